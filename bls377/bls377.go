@@ -21,6 +21,9 @@ var ID = gurvy.BLS377
 // B b coeff of the curve
 var B fp.Element
 
+// bTwistCurveCoeff b coeff of the twist (defined over Fp2) curve
+var bTwistCurveCoeff E2
+
 // generators of the r-torsion group, resp. in ker(pi-id), ker(Tr)
 var g1Gen G1Jac
 var g2Gen G2Jac
@@ -41,17 +44,29 @@ var thirdRootOneG1 fp.Element
 var thirdRootOneG2 fp.Element
 var lambdaGLV big.Int
 
-// parameters for pippenger ScalarMulByGen
-// TODO get rid of this, keep only double and add, and the multi exp
-const sGen = 4
-const bGen = sGen
+// glvBasis is a reduced basis of the lattice {(a, b) | a + b*lambdaGLV = 0 mod r}, used to split
+// the scalars of the GLV scalar multiplication in two halves
+var glvBasis [2][2]big.Int
+
+// seed x of the curve
+var xGen big.Int
+
+// gtLambda eigenvalue of the Frobenius on GT: z**p = z**gtLambda
+var gtLambda big.Int
+
+// psi endomorphism on the twist: psi(x,y) = (conj(x)*psiFactorX, conj(y)*psiFactorY)
+var psiFactorX, psiFactorY E2
 
-var tGenG1 [((1 << bGen) - 1)]G1Jac
-var tGenG2 [((1 << bGen) - 1)]G2Jac
+// glsBasis is a reduced basis of the lattice {a | a[0] + a[1]*mu + a[2]*lambdaGLV + a[3]*mu*lambdaGLV = 0 mod r},
+// mu = gtLambda being the eigenvalue of psi on G2, used to split the scalars of the GLS scalar multiplication
+// in four quarters. glsBabai is r times the first row of glsBasis**-1
+var glsBasis [4][4]big.Int
+var glsBabai [4]big.Int
 
 func init() {
 
 	B.SetUint64(1)
+	bTwistCurveCoeff.A1.SetString("103465770405187637604261093477957413414557405101965864215953705066688187339336329109987555255829344049776128583271") // 1/u
 
 	g1Gen.X.SetString("68333130937826953018162399284085925021577172705782285525244777453303237942212457240213897533859360921141590695983")
 	g1Gen.Y.SetString("243386584320553125968203959498080829207604143167922579970841210259134422887279629198736754149500839244552761526603")
@@ -73,17 +88,53 @@ func init() {
 	thirdRootOneG2.Square(&thirdRootOneG1)
 	lambdaGLV.SetString("91893752504881257701523279626832445440", 10)
 
+	glvBasis[0][0].SetInt64(1)
+	glvBasis[0][1].SetString("91893752504881257701523279626832445441", 10) // x**2
+	glvBasis[1][0].SetString("91893752504881257701523279626832445440", 10) // x**2-1
+	glvBasis[1][1].SetInt64(-1)
+
 	// binary decomposition of 15132376222941642752 little endian
 	loopCounter = [64]int8{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 1}
 
-	tGenG1[0].Set(&g1Gen)
-	for j := 1; j < len(tGenG1)-1; j = j + 2 {
-		tGenG1[j].Set(&tGenG1[j/2]).DoubleAssign()
-		tGenG1[j+1].Set(&tGenG1[(j+1)/2]).AddAssign(&tGenG1[j/2])
-	}
-	tGenG2[0].Set(&g2Gen)
-	for j := 1; j < len(tGenG2)-1; j = j + 2 {
-		tGenG2[j].Set(&tGenG2[j/2]).DoubleAssign()
-		tGenG2[j+1].Set(&tGenG2[(j+1)/2]).AddAssign(&tGenG2[j/2])
+	xGen.SetString("9586122913090633729", 10)
+	gtLambda.Set(&xGen) // p = x mod r
+
+	psiFactorX.SetString("80949648264912719408558363140637477264845294720710499478137287262712535938301461879813459410946",
+		"0")
+	psiFactorY.SetString("216465761340224619389371505802605247630151569547285782856803747159100223055385581585702401816380679166954762214499",
+		"0")
+
+	glsBasis[0][0].SetString("-9586122913090633729", 10) // -x
+	glsBasis[0][1].SetInt64(1)
+	glsBasis[1][0].SetInt64(1)
+	glsBasis[1][1].SetString("-9586122913090633729", 10) // -x
+	glsBasis[1][2].SetInt64(1)
+	glsBasis[2][2].SetString("-9586122913090633729", 10) // -x
+	glsBasis[2][3].SetInt64(1)
+	glsBasis[3][0].SetInt64(1)
+	glsBasis[3][3].SetString("9586122913090633729", 10) // x
+
+	glsBabai[0].SetString("-880904806456922042258150504921383618666682042621506879489", 10) // -x**3
+	glsBabai[1].SetString("-91893752504881257701523279626832445441", 10)                    // -x**2
+	glsBabai[2].SetString("-9586122913090633729", 10)                                       // -x
+	glsBabai[3].SetInt64(1)
+}
+
+// Generators returns the generators of G1 and G2 used in this package, in jacobian and affine coordinates
+func Generators() (g1Jac G1Jac, g2Jac G2Jac, g1Aff G1Affine, g2Aff G2Affine) {
+	g1Jac.Set(&g1Gen)
+	g2Jac.Set(&g2Gen)
+	g1Aff.FromJacobian(&g1Gen)
+	g2Aff.FromJacobian(&g2Gen)
+	return
+}
+
+// CurveParams returns the parameters of the curve: field moduli, cofactors, embedding degree,
+// twist type and seed (cf gurvy.CurveParams)
+func CurveParams() gurvy.Params {
+	params, err := gurvy.CurveParams(ID)
+	if err != nil {
+		panic(err) // ID is always a known curve
 	}
+	return params
 }
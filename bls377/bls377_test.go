@@ -0,0 +1,203 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bls377
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gurvy"
+	"github.com/consensys/gurvy/bls377/fp"
+	"github.com/consensys/gurvy/bls377/fr"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+// ------------------------------------------------------------
+// tests
+
+func TestCurveParams(t *testing.T) {
+
+	params := CurveParams()
+	if params.ID != ID {
+		t.Fatal("wrong curve ID")
+	}
+	if params.FpModulus.Cmp(fp.Modulus()) != 0 || params.FrModulus.Cmp(fr.Modulus()) != 0 {
+		t.Fatal("wrong field moduli")
+	}
+	if params.Seed.Cmp(&xGen) != 0 {
+		t.Fatal("wrong seed")
+	}
+
+	// r divides p**k-1 but no p**i-1, i<k
+	var pi, one, rem big.Int
+	one.SetUint64(1)
+	pi.SetUint64(1)
+	for i := 1; i <= params.EmbeddingDegree; i++ {
+		pi.Mul(&pi, params.FpModulus)
+		rem.Sub(&pi, &one).Mod(&rem, params.FrModulus)
+		if (rem.Sign() == 0) != (i == params.EmbeddingDegree) {
+			t.Fatal("wrong embedding degree")
+		}
+	}
+
+	// D-twist: b' = b/xi
+	var b, xi E2
+	xi.SetOne().MulByNonResidue(&xi)
+	b.Mul(&bTwistCurveCoeff, &xi)
+	if params.Twist != gurvy.DTwist || !b.A0.Equal(&B) || !b.A1.IsZero() {
+		t.Fatal("wrong twist type")
+	}
+
+	// the parameters are copies
+	params.Seed.SetUint64(0)
+	if CurveParams().Seed.Cmp(&xGen) != 0 {
+		t.Fatal("CurveParams should return copies")
+	}
+	if _, err := gurvy.CurveParams(gurvy.UNKNOWN); err != gurvy.ErrUnknownCurve {
+		t.Fatal("gurvy.CurveParams should fail on an unknown curve")
+	}
+
+	g1Jac, g2Jac, g1Aff, g2Aff := Generators()
+	if !g1Jac.Equal(&g1Gen) || !g2Jac.Equal(&g2Gen) || !g1Aff.IsInSubGroup() || !g2Aff.IsInSubGroup() {
+		t.Fatal("wrong generators")
+	}
+	var _g1 G1Jac
+	var _g2 G2Jac
+	if !_g1.FromAffine(&g1Aff).Equal(&g1Gen) || !_g2.FromAffine(&g2Aff).Equal(&g2Gen) {
+		t.Fatal("affine generators should match the jacobian ones")
+	}
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 10
+
+	properties := gopter.NewProperties(parameters)
+
+	var hr1, hr2 big.Int
+	hr1.Mul(params.CofactorG1, params.FrModulus)
+	hr2.Mul(params.CofactorG2, params.FrModulus)
+
+	properties.Property("[cofactor*r]P should be 0 and [cofactor]P in G1 for P in E(Fp)", prop.ForAll(
+		func(a fp.Element) bool {
+			var p, q G1Jac
+			op := randomOnCurveG1(a)
+			p.ScalarMultiplication(&op, &hr1)
+			q.ScalarMultiplication(&op, params.CofactorG1)
+			return p.Z.IsZero() && q.IsInSubGroup()
+		},
+		GenFp(),
+	))
+
+	properties.Property("[cofactor*r]P should be 0 and [cofactor]P in G2 for P in E'", prop.ForAll(
+		func(a *E2) bool {
+			var p, q G2Jac
+			op := randomOnCurveG2(a)
+			p.ScalarMultiplication(&op, &hr2)
+			q.ScalarMultiplication(&op, params.CofactorG2)
+			return p.Z.IsZero() && q.IsInSubGroup()
+		},
+		GenE2(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestGLVBasis(t *testing.T) {
+
+	r := fr.Modulus()
+
+	// the rows of glvBasis are short vectors of {(a, b) | a + b*lambdaGLV = 0 mod r}
+	var tmp big.Int
+	for i := 0; i < 2; i++ {
+		tmp.Mul(&glvBasis[i][1], &lambdaGLV).Add(&tmp, &glvBasis[i][0]).Mod(&tmp, r)
+		if tmp.Sign() != 0 {
+			t.Fatal("glvBasis should be in the GLV lattice")
+		}
+		if glvBasis[i][0].BitLen() > r.BitLen()/2+1 || glvBasis[i][1].BitLen() > r.BitLen()/2+1 {
+			t.Fatal("glvBasis should be reduced")
+		}
+	}
+
+	// and a basis of it: det = ±r
+	var det big.Int
+	det.Mul(&glvBasis[0][0], &glvBasis[1][1])
+	det.Sub(&det, tmp.Mul(&glvBasis[0][1], &glvBasis[1][0]))
+	if det.Abs(&det).Cmp(r) != 0 {
+		t.Fatal("glvBasis should be a basis of the GLV lattice")
+	}
+
+	// the scalar multiplication of the point at infinity, or by 0, is the point at infinity
+	var inf G1Affine
+	var res G1Jac
+	_, _, g1Aff, _ := Generators()
+	if !res.ScalarMulGLV(&inf, r).Z.IsZero() || !res.ScalarMulGLV(&inf, &lambdaGLV).Z.IsZero() || !res.ScalarMulGLV(&g1Aff, tmp.SetUint64(0)).Z.IsZero() {
+		t.Fatal("ScalarMulGLV should output the point at infinity")
+	}
+}
+
+func TestGLSBasis(t *testing.T) {
+
+	r := fr.Modulus()
+
+	// the eigenvalues of 1, psi, phi, phi o psi on G2
+	var ev [4]big.Int
+	ev[0].SetUint64(1)
+	ev[1].Set(&gtLambda)
+	ev[2].Set(&lambdaGLV)
+	ev[3].Mul(&gtLambda, &lambdaGLV)
+
+	var g2Aff, psig2Aff G2Affine
+	var psig2, muG2 G2Jac
+	g2Aff.FromJacobian(&g2Gen)
+	psig2Aff.psi(&g2Aff)
+	muG2.mulWindowed(&g2Gen, &gtLambda)
+	psig2.FromAffine(&psig2Aff)
+	if !muG2.Equal(&psig2) {
+		t.Fatal("psi should act as [gtLambda] on G2")
+	}
+
+	// the rows of glsBasis are short vectors of {a | a[0] + a[1]*mu + a[2]*lambdaGLV + a[3]*mu*lambdaGLV = 0 mod r}
+	var tmp, sum big.Int
+	for i := 0; i < 4; i++ {
+		sum.SetUint64(0)
+		for j := 0; j < 4; j++ {
+			sum.Add(&sum, tmp.Mul(&glsBasis[i][j], &ev[j]))
+			if glsBasis[i][j].BitLen() > r.BitLen()/4+2 {
+				t.Fatal("glsBasis should be reduced")
+			}
+		}
+		if sum.Mod(&sum, r).Sign() != 0 {
+			t.Fatal("glsBasis should be in the GLS lattice")
+		}
+	}
+
+	// glsBabai*glsBasis = (r, 0, 0, 0)
+	for i := 0; i < 4; i++ {
+		sum.SetUint64(0)
+		for j := 0; j < 4; j++ {
+			sum.Add(&sum, tmp.Mul(&glsBabai[j], &glsBasis[j][i]))
+		}
+		if (i == 0 && sum.Cmp(r) != 0) || (i != 0 && sum.Sign() != 0) {
+			t.Fatal("glsBabai should be r times the first row of glsBasis**-1")
+		}
+	}
+
+	// the scalar multiplication of the point at infinity is the point at infinity
+	var inf G2Affine
+	var g G2Jac
+	if !g.ScalarMulGLS(&inf, &lambdaGLV).Z.IsZero() || !g.ScalarMulGLS(&g2Aff, tmp.SetUint64(0)).Z.IsZero() {
+		t.Fatal("ScalarMulGLS should output the point at infinity")
+	}
+}
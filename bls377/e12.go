@@ -17,7 +17,11 @@
 package bls377
 
 import (
+	"errors"
+	"io"
 	"math/big"
+
+	"github.com/consensys/gurvy/bls377/fp"
 )
 
 // E12 is a degree two finite field extension of fp6
@@ -30,6 +34,11 @@ func (z *E12) Equal(x *E12) bool {
 	return z.C0.Equal(&x.C0) && z.C1.Equal(&x.C1)
 }
 
+// IsZero returns true if z is 0
+func (z *E12) IsZero() bool {
+	return z.C0.IsZero() && z.C1.IsZero()
+}
+
 // String puts E12 in string form
 func (z *E12) String() string {
 	return (z.C0.String() + "+(" + z.C1.String() + ")*w")
@@ -224,6 +233,275 @@ func (z *E12) Exp(x *E12, e big.Int) *E12 {
 	return z
 }
 
+// CyclotomicSquareCompressed sets z to the square of x in compressed form, returns z
+// https://eprint.iacr.org/2010/542.pdf, Karabina, 3.2
+//
+// Writing x = h0 + h1*w + ... + h5*w**5 (h0 = C0.B0, h1 = C1.B0, h2 = C0.B1, h3 = C1.B1, h4 = C0.B2, h5 = C1.B2),
+// the square of an element of the cyclotomic subgroup is computed from (h1, h2, h4, h5) only,
+// the coefficients C0.B0 and C1.B1 of z are not updated (see DecompressKarabina)
+//
+// x must be in the cyclotomic subgroup (in particular x != 0), this is not checked: for other inputs the
+// decompressed result is meaningless, e.g. the compressed form of 0 decompresses to 1
+func (z *E12) CyclotomicSquareCompressed(x *E12) *E12 {
+
+	var t [7]E2
+
+	// t0 = h2**2, t1 = h5**2, t5 = 2*h2*h5
+	t[0].Square(&x.C0.B1)
+	t[1].Square(&x.C1.B2)
+	t[5].Add(&x.C0.B1, &x.C1.B2)
+	t[2].Square(&t[5])
+	t[3].Add(&t[0], &t[1])
+	t[5].Sub(&t[2], &t[3])
+
+	// t3 = (h1 + h4)**2, t2 = h1**2
+	t[6].Add(&x.C1.B0, &x.C0.B2)
+	t[3].Square(&t[6])
+	t[2].Square(&x.C1.B0)
+
+	// h1 = 6*ξ*h2*h5 + 2*h1
+	t[6].MulByNonResidue(&t[5])
+	t[5].Add(&t[6], &x.C1.B0).Double(&t[5])
+	z.C1.B0.Add(&t[5], &t[6])
+
+	// h4 = 3*(h2**2 + ξ*h5**2) - 2*h4
+	t[4].MulByNonResidue(&t[1])
+	t[5].Add(&t[0], &t[4])
+	t[6].Sub(&t[5], &x.C0.B2)
+	t[1].Square(&x.C0.B2)
+	t[6].Double(&t[6])
+	z.C0.B2.Add(&t[6], &t[5])
+
+	// h2 = 3*(h1**2 + ξ*h4**2) - 2*h2
+	t[4].MulByNonResidue(&t[1])
+	t[5].Add(&t[2], &t[4])
+	t[6].Sub(&t[5], &x.C0.B1)
+	t[6].Double(&t[6])
+	z.C0.B1.Add(&t[6], &t[5])
+
+	// h5 = 6*h1*h4 + 2*h5
+	t[0].Add(&t[2], &t[1])
+	t[5].Sub(&t[3], &t[0])
+	t[6].Add(&t[5], &x.C1.B2)
+	t[6].Double(&t[6])
+	z.C1.B2.Add(&t[5], &t[6])
+
+	return z
+}
+
+// DecompressKarabina sets z to the element of the cyclotomic subgroup whose compressed form is x, returns z
+// (cf CyclotomicSquareCompressed), x must be the compressed form of an element of the cyclotomic subgroup
+func (z *E12) DecompressKarabina(x *E12) *E12 {
+	var num, den E2
+	karabinaFraction(&num, &den, x)
+	den.Inverse(&den)
+	z.Set(x)
+	z.karabinaRecover(&num, &den)
+	return z
+}
+
+// BatchDecompressKarabina decompresses in place the compressed forms x (cf DecompressKarabina),
+// sharing a single inversion
+func BatchDecompressKarabina(x []E12) {
+	n := len(x)
+	if n == 0 {
+		return
+	}
+
+	num := make([]E2, n)
+	den := make([]E2, n)
+	for i := 0; i < n; i++ {
+		karabinaFraction(&num[i], &den[i], &x[i])
+	}
+
+	// Montgomery's trick: acc[i] = den[0]*...*den[i-1]
+	acc := make([]E2, n)
+	var inv E2
+	inv.SetOne()
+	for i := 0; i < n; i++ {
+		acc[i].Set(&inv)
+		inv.Mul(&inv, &den[i])
+	}
+	inv.Inverse(&inv)
+	for i := n - 1; i >= 0; i-- {
+		var tmp E2
+		tmp.Mul(&inv, &acc[i])
+		inv.Mul(&inv, &den[i])
+		x[i].karabinaRecover(&num[i], &tmp)
+	}
+}
+
+// karabinaFraction sets num, den such that h3 = num / den for the compressed form x:
+//
+//	h3 = (ξ*h5**2 + 3*h2**2 - 2*h4) / (4*h1) if h1 != 0
+//	h3 = 2*h2*h5 / h4 otherwise
+//
+// den is set to 1 (and num to 0) if h1 = h4 = 0, the only element of the cyclotomic subgroup
+// with this compressed form being 1 (a degenerate input outside the cyclotomic subgroup, e.g. 0,
+// is decompressed to 1 as well, hence the callers' requirement of a cyclotomic input)
+func karabinaFraction(num, den *E2, x *E12) {
+	if !x.C1.B0.IsZero() {
+		var t E2
+		t.Square(&x.C0.B1)
+		num.Sub(&t, &x.C0.B2).Double(num).Add(num, &t)
+		t.Square(&x.C1.B2).MulByNonResidue(&t)
+		num.Add(num, &t)
+		den.Double(&x.C1.B0).Double(den)
+		return
+	}
+	if !x.C0.B2.IsZero() {
+		num.Mul(&x.C0.B1, &x.C1.B2).Double(num)
+		den.Set(&x.C0.B2)
+		return
+	}
+	num.SetZero()
+	den.SetOne()
+}
+
+// karabinaRecover sets the coefficients h3 = num * denInv and h0 = ξ*(2*h3**2 + h1*h5 - 3*h2*h4) + 1 of z
+func (z *E12) karabinaRecover(num, denInv *E2) {
+	var t0, t1 E2
+	var one fp.Element
+	one.SetOne()
+	z.C1.B1.Mul(num, denInv)
+
+	t0.Square(&z.C1.B1).Double(&t0)
+	t1.Mul(&z.C1.B0, &z.C1.B2)
+	t0.Add(&t0, &t1)
+	t1.Mul(&z.C0.B1, &z.C0.B2)
+	t0.Sub(&t0, &t1).Sub(&t0, &t1).Sub(&t0, &t1)
+	z.C0.B0.MulByNonResidue(&t0)
+	z.C0.B0.A0.Add(&z.C0.B0.A0, &one)
+}
+
+// Sparse multiplications by line evaluations in the Miller loop: the coefficients of an E12 element are
+// indexed 0 to 5 in the order C0.B0, C0.B1, C0.B2, C1.B0, C1.B1, C1.B2
+// (on the D-type twist of bls377, the line evaluations only have non-zero coefficients 0, 3, 4)
+
+// MulBy034 sets z to x*(c0 + c3*w + c4*v*w), returns z
+// (13 E2 multiplications, instead of 18 for a full multiplication)
+func (z *E12) MulBy034(x *E12, c0, c3, c4 *E2) *E12 {
+	var a, b, c E6
+	var d0 E2
+
+	// a = x.C0 * c0, b = x.C1 * (c3 + c4*v)
+	a.MulByE2(&x.C0, c0)
+	b.MulBy01(&x.C1, c3, c4)
+
+	// c = (x.C0 + x.C1) * ((c0 + c3) + c4*v) - a - b
+	d0.Add(c0, c3)
+	c.Add(&x.C0, &x.C1)
+	c.MulBy01(&c, &d0, c4)
+	z.C1.Sub(&c, &a).Sub(&z.C1, &b)
+
+	z.C0.MulByNonResidue(&b).Add(&z.C0, &a)
+	return z
+}
+
+// Mul034By034 returns the product of the sparse elements (c0 + c3*w + c4*v*w) and (d0 + d3*w + d4*v*w),
+// as the coefficients 0, 1, 2, 3, 4 of an E12 element (the coefficient 5 is zero)
+func Mul034By034(c0, c3, c4, d0, d3, d4 *E2) [5]E2 {
+	var x00, x33, x44, x03, x04, x34, tmp E2
+	x00.Mul(c0, d0)
+	x33.Mul(c3, d3)
+	x44.Mul(c4, d4)
+
+	x03.Add(c0, c3)
+	tmp.Add(d0, d3)
+	x03.Mul(&x03, &tmp).Sub(&x03, &x00).Sub(&x03, &x33)
+
+	x04.Add(c0, c4)
+	tmp.Add(d0, d4)
+	x04.Mul(&x04, &tmp).Sub(&x04, &x00).Sub(&x04, &x44)
+
+	x34.Add(c3, c4)
+	tmp.Add(d3, d4)
+	x34.Mul(&x34, &tmp).Sub(&x34, &x33).Sub(&x34, &x44)
+
+	// w**2 = v, (v*w)**2 = v**3 = ξ
+	var res [5]E2
+	res[0].MulByNonResidue(&x44).Add(&res[0], &x00)
+	res[1].Set(&x33)
+	res[2].Set(&x34)
+	res[3].Set(&x03)
+	res[4].Set(&x04)
+	return res
+}
+
+// MulBy01234 sets z to x*y where y holds the coefficients 0, 1, 2, 3, 4 of an E12 element
+// whose coefficient 5 is zero (cf Mul034By034), returns z
+func (z *E12) MulBy01234(x *E12, y *[5]E2) *E12 {
+	var a, b, c, y0 E6
+	var d0, d1 E2
+
+	// a = x.C0 * y.C0, b = x.C1 * y.C1
+	y0.B0.Set(&y[0])
+	y0.B1.Set(&y[1])
+	y0.B2.Set(&y[2])
+	a.Mul(&x.C0, &y0)
+	b.MulBy01(&x.C1, &y[3], &y[4])
+
+	// c = (x.C0 + x.C1) * (y.C0 + y.C1) - a - b
+	d0.Add(&y[0], &y[3])
+	d1.Add(&y[1], &y[4])
+	y0.B0.Set(&d0)
+	y0.B1.Set(&d1)
+	c.Add(&x.C0, &x.C1).Mul(&c, &y0)
+	z.C1.Sub(&c, &a).Sub(&z.C1, &b)
+
+	z.C0.MulByNonResidue(&b).Add(&z.C0, &a)
+	return z
+}
+
+// expNAF sets z to x**e and returns z, where e = sum e[i]*2**i with e[i] in {-1, 0, 1}
+// x must be in the cyclotomic subgroup (in particular x != 0), this is not checked (cf CyclotomicSquareCompressed)
+//
+// the x**(2**i) are computed with compressed squarings (cf CyclotomicSquareCompressed), those with e[i] != 0
+// are decompressed in a batch (cf BatchDecompressKarabina) and multiplied (conjugated when e[i] = -1)
+func (z *E12) expNAF(x *E12, e []int8) *E12 {
+	n := 0
+	for i := range e {
+		if e[i] != 0 {
+			n++
+		}
+	}
+
+	squares := make([]E12, 0, n)
+	var acc E12
+	acc.Set(x)
+	for i := range e {
+		if i > 0 {
+			acc.CyclotomicSquareCompressed(&acc)
+		}
+		if e[i] != 0 {
+			squares = append(squares, acc)
+		}
+	}
+
+	// x itself is not compressed
+	start := 0
+	if e[0] != 0 {
+		start = 1
+	}
+	BatchDecompressKarabina(squares[start:])
+
+	var res E12
+	res.SetOne()
+	for i, j := 0, 0; i < len(e); i++ {
+		if e[i] == 0 {
+			continue
+		}
+		if e[i] < 0 {
+			squares[j].Conjugate(&squares[j])
+		}
+		res.Mul(&res, &squares[j])
+		j++
+	}
+
+	z.Set(&res)
+	return z
+}
+
 // InverseUnitary inverse a unitary element
 func (z *E12) InverseUnitary(x *E12) *E12 {
 	return z.Conjugate(x)
@@ -235,3 +513,184 @@ func (z *E12) Conjugate(x *E12) *E12 {
 	z.C1.Neg(&z.C1)
 	return z
 }
+
+// Torus-based compression of the elements of the cyclotomic subgroup of E12, of order p**4 - p**2 + 1
+// "Compression in finite fields and torus-based cryptography", K. Rubin and A. Silverberg
+//
+// E12 = E6[w]/(w**2 - v): an element x = c0 + c1*w with x**(p**6+1) = 1 (in particular x in the cyclotomic
+// subgroup) and x != 1 is x = (y + w) / (y - w) with y = (1 + c0) / c1 in E6, this is the T2 compressed form
+// (the identity is compressed to y = 0, -1 not being in the cyclotomic subgroup).
+//
+// E6 = E2[v]/(v**3 - ξ): x is moreover in the cyclotomic subgroup (the torus T6 over E2) iff
+// y = y0 + y1*v + y2*v**2 satisfies y0*y1 = ξ*y2**2 + 1/3, so that y0 can be recovered from (y1, y2)
+// when y1 != 0, this is the T6 compressed form (the identity is compressed to (0, 0)).
+
+// ErrTorusExceptional is returned when an element of the cyclotomic subgroup has no T6 compressed form (y1 = 0),
+// or when decompressing an invalid T6 compressed form
+var ErrTorusExceptional = errors.New("torus compression: exceptional or invalid element")
+
+// CompressT2 sets z to the T2 compressed form of x (half its size) and returns z
+// x must be in the cyclotomic subgroup
+func (z *E6) CompressT2(x *E12) *E6 {
+	if x.C1.IsZero() {
+		// x = 1
+		return z.SetZero()
+	}
+	var one, res E6
+	one.SetOne()
+	res.Inverse(&x.C1)
+	z.Add(&x.C0, &one).Mul(z, &res)
+	return z
+}
+
+// DecompressT2 sets z to the element of the cyclotomic subgroup whose T2 compressed form is x, returns z
+//
+// (y + w) / (y - w) = ((y**2 + v) + 2*y*w) / (y**2 - v)
+func (z *E12) DecompressT2(x *E6) *E12 {
+	if x.IsZero() {
+		return z.SetOne()
+	}
+	var num, den, y E6
+	var one fp.Element
+	one.SetOne()
+	y.Set(x)
+	num.Square(&y)
+	den.Set(&num)
+	num.B1.A0.Add(&num.B1.A0, &one)
+	den.B1.A0.Sub(&den.B1.A0, &one)
+	den.Inverse(&den)
+	z.C0.Mul(&num, &den)
+	z.C1.Double(&y).Mul(&z.C1, &den)
+	return z
+}
+
+// MulT2 sets z to the T2 compressed form of the product of the elements compressed as x and y, returns z
+//
+// it costs an E6 multiplication and an E6 inversion, and is cheaper than a decompression followed by a compression
+func (z *E6) MulT2(x, y *E6) *E6 {
+	if x.IsZero() {
+		return z.Set(y)
+	}
+	if y.IsZero() {
+		return z.Set(x)
+	}
+	var num, den E6
+	var one fp.Element
+	one.SetOne()
+	den.Add(x, y)
+	if den.IsZero() {
+		// y is the compressed form of the inverse of x
+		return z.SetZero()
+	}
+	// (x*y + v) / (x + y)
+	num.Mul(x, y)
+	num.B1.A0.Add(&num.B1.A0, &one)
+	den.Inverse(&den)
+	z.Mul(&num, &den)
+	return z
+}
+
+// SquareT2 sets z to the T2 compressed form of the square of the element compressed as x, returns z
+func (z *E6) SquareT2(x *E6) *E6 {
+	if x.IsZero() {
+		return z.SetZero()
+	}
+	// (x**2 + v) / (2*x)
+	var num, den E6
+	var one fp.Element
+	one.SetOne()
+	num.Square(x)
+	num.B1.A0.Add(&num.B1.A0, &one)
+	den.Double(x).Inverse(&den)
+	z.Mul(&num, &den)
+	return z
+}
+
+// InverseT2 sets z to the T2 compressed form of the inverse of the element compressed as x, returns z
+// (the inverse of (x + w) / (x - w) is (-x + w) / (-x - w))
+func (z *E6) InverseT2(x *E6) *E6 {
+	return z.Neg(x)
+}
+
+// CompressT6 returns the T6 compressed form (y1, y2) of z (a third of its size)
+// z must be in the cyclotomic subgroup, it returns ErrTorusExceptional if z has no T6 compressed form
+func (z *E12) CompressT6() ([2]E2, error) {
+	var y E6
+	var res [2]E2
+	y.CompressT2(z)
+	if y.IsZero() {
+		return res, nil
+	}
+	if y.B1.IsZero() {
+		return res, ErrTorusExceptional
+	}
+	res[0].Set(&y.B1)
+	res[1].Set(&y.B2)
+	return res, nil
+}
+
+// DecompressT6 sets z to the element of the cyclotomic subgroup whose T6 compressed form is x
+// it returns ErrTorusExceptional if x is not a valid T6 compressed form (z is left unchanged)
+func (z *E12) DecompressT6(x *[2]E2) error {
+	if x[0].IsZero() {
+		if !x[1].IsZero() {
+			return ErrTorusExceptional
+		}
+		z.SetOne()
+		return nil
+	}
+
+	// y0 = (3*ξ*y2**2 + 1) / (3*y1)
+	var y E6
+	var one, three fp.Element
+	var den E2
+	one.SetOne()
+	three.SetUint64(3)
+	y.B1.Set(&x[0])
+	y.B2.Set(&x[1])
+	y.B0.Square(&y.B2).MulByNonResidue(&y.B0).MulByElement(&y.B0, &three)
+	y.B0.A0.Add(&y.B0.A0, &one)
+	den.MulByElement(&y.B1, &three).Inverse(&den)
+	y.B0.Mul(&y.B0, &den)
+
+	z.DecompressT2(&y)
+	return nil
+}
+
+// SizeOfGT represents the size in bytes that a GT element needs in binary form
+const SizeOfGT = fp.Limbs * 8 * 12
+
+// Bytes returns the regular (non montgomery) value of z as a big-endian byte array,
+// the fp.Element coordinates are stored in order C0.B0.A0, C0.B0.A1, C0.B1.A0, ..., C1.B2.A1
+func (z *E12) Bytes() (r [SizeOfGT]byte) {
+	const fpSize = fp.Limbs * 8
+	for i, e := range z.coordinates() {
+		copy(r[i*fpSize:(i+1)*fpSize], e.Bytes())
+	}
+	return
+}
+
+// SetBytes interprets e as the bytes of a big-endian E12 (see Bytes)
+// it returns an error if a coordinate is not reduced modulo q
+func (z *E12) SetBytes(e []byte) error {
+	if len(e) < SizeOfGT {
+		return io.ErrShortBuffer
+	}
+	const fpSize = fp.Limbs * 8
+	var res E12
+	for i, c := range res.coordinates() {
+		if err := setElementBytes(c, e[i*fpSize:(i+1)*fpSize]); err != nil {
+			return err
+		}
+	}
+	z.Set(&res)
+	return nil
+}
+
+// coordinates returns pointers to the fp.Element coordinates of z
+func (z *E12) coordinates() [12]*fp.Element {
+	return [12]*fp.Element{
+		&z.C0.B0.A0, &z.C0.B0.A1, &z.C0.B1.A0, &z.C0.B1.A1, &z.C0.B2.A0, &z.C0.B2.A1,
+		&z.C1.B0.A0, &z.C1.B0.A1, &z.C1.B1.A0, &z.C1.B1.A1, &z.C1.B2.A0, &z.C1.B2.A1,
+	}
+}
@@ -136,3 +136,54 @@ func (z *E2) Conjugate(x *E2) *E2 {
 	z.A1.Neg(&x.A1)
 	return z
 }
+
+// Sqrt sets z to a square root of x and returns z
+// if x is not a square in E2, Sqrt leaves z unchanged and returns nil
+// (complex method: x = a + bu has a square root iff its norm a² - u²b² is a square in fp)
+func (z *E2) Sqrt(x *E2) *E2 {
+	var nonResidue E2
+	nonResidue.A1.SetOne()
+	nonResidue.Square(&nonResidue) // u**2, in A0
+
+	var a, b fp.Element
+	if x.A1.IsZero() {
+		if a.Sqrt(&x.A0) != nil {
+			z.A0.Set(&a)
+			z.A1.SetZero()
+			return z
+		}
+		// x.A0 is not a square in fp, but x.A0/u**2 is
+		b.Inverse(&nonResidue.A0).Mul(&b, &x.A0)
+		if a.Sqrt(&b) == nil {
+			return nil
+		}
+		z.A0.SetZero()
+		z.A1.Set(&a)
+		return z
+	}
+
+	// norm = a² - u²b²
+	var norm, lambda, half, delta fp.Element
+	norm.Square(&x.A1).Mul(&norm, &nonResidue.A0)
+	a.Square(&x.A0)
+	norm.Sub(&a, &norm)
+	if lambda.Sqrt(&norm) == nil {
+		return nil
+	}
+	half.SetUint64(2).Inverse(&half)
+
+	// x0 = sqrt((a ± lambda)/2), one of the two is a square in fp
+	delta.Add(&x.A0, &lambda).Mul(&delta, &half)
+	if a.Sqrt(&delta) == nil {
+		delta.Sub(&x.A0, &lambda).Mul(&delta, &half)
+		if a.Sqrt(&delta) == nil {
+			return nil
+		}
+	}
+
+	// x1 = b / 2x0
+	b.Double(&a).Inverse(&b).Mul(&b, &x.A1)
+	z.A0.Set(&a)
+	z.A1.Set(&b)
+	return z
+}
@@ -0,0 +1,279 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bls377
+
+import (
+	"github.com/consensys/gurvy/bls377/fp"
+	"github.com/consensys/gurvy/utils"
+)
+
+// hash to curve, following RFC 9380
+// suites BLS12377G1_XMD:SHA-256_SVDW_RO_, BLS12377G1_XMD:SHA-256_SVDW_NU_,
+// BLS12377G2_XMD:SHA-256_SVDW_RO_ and BLS12377G2_XMD:SHA-256_SVDW_NU_
+//
+// E1 and E2 have A = 0 (j-invariant 0), so the simplified SWU map does not apply
+// without an isogeny; as for bn256, the Shallue-van de Woestijne map is used on the curves directly
+
+// security parameter of the suites, in bytes: L = ceil((ceil(log2(p)) + k) / 8), k = 128
+const hashToFieldL = 64
+
+// Shallue-van de Woestijne parameters, cf RFC 9380, section 6.6.1
+// c1 = g(Z), c2 = -Z / 2, c3 = sqrt(-g(Z) * 3 * Z**2) with sgn0(c3) = 0, c4 = -4 * g(Z) / (3 * Z**2)
+var svdwG1 struct {
+	Z, c1, c2, c3, c4 fp.Element
+}
+
+var svdwG2 struct {
+	Z, c1, c2, c3, c4 E2
+}
+
+func init() {
+
+	// Z = 1 on E1 and Z = 4 on E2, they are the first candidates accepted by find_z_svdw
+	// (RFC 9380, appendix H.1)
+	var tv fp.Element
+	svdwG1.Z.SetOne()
+	svdwG1.c1.Square(&svdwG1.Z).Mul(&svdwG1.c1, &svdwG1.Z).Add(&svdwG1.c1, &B)
+	svdwG1.c2.SetUint64(2).Inverse(&svdwG1.c2).Mul(&svdwG1.c2, &svdwG1.Z).Neg(&svdwG1.c2)
+	tv.SetUint64(3)
+	tv.Mul(&tv, &svdwG1.Z).Mul(&tv, &svdwG1.Z) // 3 * Z**2
+	svdwG1.c4.Mul(&svdwG1.c1, &tv).Neg(&svdwG1.c4)
+	svdwG1.c3.Sqrt(&svdwG1.c4)
+	if sgn0(&svdwG1.c3) == 1 {
+		svdwG1.c3.Neg(&svdwG1.c3)
+	}
+	svdwG1.c4.SetUint64(4).Mul(&svdwG1.c4, &svdwG1.c1).Div(&svdwG1.c4, &tv).Neg(&svdwG1.c4)
+
+	var tv2, four E2
+	four.A0.SetUint64(4)
+	svdwG2.Z.A0.SetUint64(4)
+	svdwG2.c1.Square(&svdwG2.Z).Mul(&svdwG2.c1, &svdwG2.Z).Add(&svdwG2.c1, &bTwistCurveCoeff)
+	svdwG2.c2.Set(&svdwG2.Z)
+	tv.SetUint64(2).Inverse(&tv)
+	svdwG2.c2.MulByElement(&svdwG2.c2, &tv).Neg(&svdwG2.c2)
+	tv.SetUint64(3)
+	tv2.Square(&svdwG2.Z).MulByElement(&tv2, &tv) // 3 * Z**2
+	svdwG2.c4.Mul(&svdwG2.c1, &tv2).Neg(&svdwG2.c4)
+	svdwG2.c3.Sqrt(&svdwG2.c4)
+	if sgn0E2(&svdwG2.c3) == 1 {
+		svdwG2.c3.Neg(&svdwG2.c3)
+	}
+	tv2.Inverse(&tv2)
+	svdwG2.c4.Mul(&svdwG2.c1, &tv2).Mul(&svdwG2.c4, &four).Neg(&svdwG2.c4)
+}
+
+// HashToG1 hashes msg to a point of G1, with domain separation tag dst
+// It is indifferentiable from a random oracle (suite BLS12377G1_XMD:SHA-256_SVDW_RO_)
+func HashToG1(msg, dst []byte) (G1Affine, error) {
+	var res G1Affine
+	u, err := hashToFp(msg, dst, 2)
+	if err != nil {
+		return res, err
+	}
+	q0 := MapToG1(u[0])
+	q1 := MapToG1(u[1])
+
+	var _q0, _q1 G1Jac
+	_q0.FromAffine(&q0)
+	_q1.FromAffine(&q1)
+	_q0.AddAssign(&_q1).ClearCofactor(&_q0)
+	res.FromJacobian(&_q0)
+
+	return res, nil
+}
+
+// EncodeToG1 hashes msg to a point of G1, with domain separation tag dst
+// Its output is not uniformly distributed (suite BLS12377G1_XMD:SHA-256_SVDW_NU_)
+func EncodeToG1(msg, dst []byte) (G1Affine, error) {
+	var res G1Affine
+	u, err := hashToFp(msg, dst, 1)
+	if err != nil {
+		return res, err
+	}
+	res = MapToG1(u[0])
+	res.ClearCofactor(&res)
+	return res, nil
+}
+
+// MapToG1 maps a field element to a point of E1 (map_to_curve in RFC 9380),
+// using the Shallue-van de Woestijne map
+// The result is not in G1 (cf ClearCofactor)
+func MapToG1(u fp.Element) G1Affine {
+	return svdwMapG1(&u)
+}
+
+// HashToG2 hashes msg to a point of G2, with domain separation tag dst
+// It is indifferentiable from a random oracle (suite BLS12377G2_XMD:SHA-256_SVDW_RO_)
+func HashToG2(msg, dst []byte) (G2Affine, error) {
+	var res G2Affine
+	u, err := hashToE2(msg, dst, 2)
+	if err != nil {
+		return res, err
+	}
+	q0 := MapToG2(u[0])
+	q1 := MapToG2(u[1])
+
+	var _q0, _q1 G2Jac
+	_q0.FromAffine(&q0)
+	_q1.FromAffine(&q1)
+	_q0.AddAssign(&_q1).ClearCofactor(&_q0)
+	res.FromJacobian(&_q0)
+
+	return res, nil
+}
+
+// EncodeToG2 hashes msg to a point of G2, with domain separation tag dst
+// Its output is not uniformly distributed (suite BLS12377G2_XMD:SHA-256_SVDW_NU_)
+func EncodeToG2(msg, dst []byte) (G2Affine, error) {
+	var res G2Affine
+	u, err := hashToE2(msg, dst, 1)
+	if err != nil {
+		return res, err
+	}
+	res = MapToG2(u[0])
+	res.ClearCofactor(&res)
+	return res, nil
+}
+
+// MapToG2 maps an element of Fp2 to a point of E2 (map_to_curve in RFC 9380),
+// using the Shallue-van de Woestijne map
+// The result is not in G2 (cf ClearCofactor)
+func MapToG2(u E2) G2Affine {
+	return svdwMapG2(&u)
+}
+
+// hashToFp hashes msg to count elements of Fp (hash_to_field in RFC 9380)
+func hashToFp(msg, dst []byte, count int) ([]fp.Element, error) {
+	uniformBytes, err := utils.ExpandMsgXmd(msg, dst, count*hashToFieldL)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]fp.Element, count)
+	for i := 0; i < count; i++ {
+		res[i].SetBytes(uniformBytes[i*hashToFieldL : (i+1)*hashToFieldL])
+	}
+	return res, nil
+}
+
+// hashToE2 hashes msg to count elements of Fp2 (hash_to_field in RFC 9380)
+func hashToE2(msg, dst []byte, count int) ([]E2, error) {
+	u, err := hashToFp(msg, dst, 2*count)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]E2, count)
+	for i := 0; i < count; i++ {
+		res[i].A0.Set(&u[2*i])
+		res[i].A1.Set(&u[2*i+1])
+	}
+	return res, nil
+}
+
+// sgn0 returns the parity of z (in regular form), cf RFC 9380, section 4.1
+func sgn0(z *fp.Element) uint64 {
+	_z := z.ToRegular()
+	return _z[0] & 1
+}
+
+// sgn0E2 returns the "sign" of z (A0 + u*A1), cf RFC 9380, section 4.1
+func sgn0E2(z *E2) uint64 {
+	sign0 := sgn0(&z.A0)
+	var zero0 uint64
+	if z.A0.IsZero() {
+		zero0 = 1
+	}
+	return sign0 | (zero0 & sgn0(&z.A1))
+}
+
+// svdwMapG1 Shallue-van de Woestijne map on E1, cf RFC 9380, section 6.6.1
+func svdwMapG1(u *fp.Element) G1Affine {
+
+	var res G1Affine
+	var one, tv1, tv2, tv3, tv4, x, gx fp.Element
+	one.SetOne()
+
+	// tv1 = 1 - c1 * u**2, tv2 = 1 + c1 * u**2, tv3 = inv0(tv1 * tv2)
+	tv3.Square(u).Mul(&tv3, &svdwG1.c1)
+	tv2.Add(&one, &tv3)
+	tv1.Sub(&one, &tv3)
+	tv3.Mul(&tv1, &tv2).Inverse(&tv3)
+
+	// tv4 = u * tv1 * tv3 * c3
+	tv4.Mul(u, &tv1).Mul(&tv4, &tv3).Mul(&tv4, &svdwG1.c3)
+
+	// x1 = c2 - tv4
+	x.Sub(&svdwG1.c2, &tv4)
+	gx.Square(&x).Mul(&gx, &x).Add(&gx, &B)
+	if res.Y.Sqrt(&gx) == nil {
+		// x2 = c2 + tv4
+		x.Add(&svdwG1.c2, &tv4)
+		gx.Square(&x).Mul(&gx, &x).Add(&gx, &B)
+		if res.Y.Sqrt(&gx) == nil {
+			// x3 = Z + c4 * (tv2**2 * tv3)**2, g(x3) is always a square
+			x.Square(&tv2).Mul(&x, &tv3).Square(&x).Mul(&x, &svdwG1.c4).Add(&x, &svdwG1.Z)
+			gx.Square(&x).Mul(&gx, &x).Add(&gx, &B)
+			res.Y.Sqrt(&gx)
+		}
+	}
+	res.X.Set(&x)
+
+	if sgn0(u) != sgn0(&res.Y) {
+		res.Y.Neg(&res.Y)
+	}
+
+	return res
+}
+
+// svdwMapG2 Shallue-van de Woestijne map on E2, cf RFC 9380, section 6.6.1
+func svdwMapG2(u *E2) G2Affine {
+
+	var res G2Affine
+	var one, tv1, tv2, tv3, tv4, x, gx E2
+	one.SetOne()
+
+	// tv1 = 1 - c1 * u**2, tv2 = 1 + c1 * u**2, tv3 = inv0(tv1 * tv2)
+	tv3.Square(u).Mul(&tv3, &svdwG2.c1)
+	tv2.Add(&one, &tv3)
+	tv1.Sub(&one, &tv3)
+	tv3.Mul(&tv1, &tv2)
+	if !tv3.IsZero() {
+		tv3.Inverse(&tv3)
+	}
+
+	// tv4 = u * tv1 * tv3 * c3
+	tv4.Mul(u, &tv1).Mul(&tv4, &tv3).Mul(&tv4, &svdwG2.c3)
+
+	// x1 = c2 - tv4
+	x.Sub(&svdwG2.c2, &tv4)
+	gx.Square(&x).Mul(&gx, &x).Add(&gx, &bTwistCurveCoeff)
+	if res.Y.Sqrt(&gx) == nil {
+		// x2 = c2 + tv4
+		x.Add(&svdwG2.c2, &tv4)
+		gx.Square(&x).Mul(&gx, &x).Add(&gx, &bTwistCurveCoeff)
+		if res.Y.Sqrt(&gx) == nil {
+			// x3 = Z + c4 * (tv2**2 * tv3)**2, g(x3) is always a square
+			x.Square(&tv2).Mul(&x, &tv3).Square(&x).Mul(&x, &svdwG2.c4).Add(&x, &svdwG2.Z)
+			gx.Square(&x).Mul(&gx, &x).Add(&gx, &bTwistCurveCoeff)
+			res.Y.Sqrt(&gx)
+		}
+	}
+	res.X.Set(&x)
+
+	if sgn0E2(u) != sgn0E2(&res.Y) {
+		res.Y.Neg(&res.Y)
+	}
+
+	return res
+}
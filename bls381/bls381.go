@@ -21,6 +21,9 @@ var ID = gurvy.BLS381
 // B b coeff of the curve
 var B fp.Element
 
+// bTwistCurveCoeff b coeff of the twist (defined over Fp2) curve
+var bTwistCurveCoeff E2
+
 // generators of the r-torsion group, resp. in ker(pi-id), ker(Tr)
 var g1Gen G1Jac
 var g2Gen G2Jac
@@ -41,17 +44,30 @@ var thirdRootOneG1 fp.Element
 var thirdRootOneG2 fp.Element
 var lambdaGLV big.Int
 
-// parameters for pippenger ScalarMulByGen
-// TODO get rid of this, keep only double and add, and the multi exp
-const sGen = 4
-const bGen = sGen
+// glvBasis is a reduced basis of the lattice {(a, b) | a + b*lambdaGLV = 0 mod r}, used to split
+// the scalars of the GLV scalar multiplication in two halves
+var glvBasis [2][2]big.Int
+
+// seed x of the curve
+var xGen big.Int
+
+// gtLambda eigenvalue of the Frobenius on GT: z**p = z**gtLambda
+var gtLambda big.Int
 
-var tGenG1 [((1 << bGen) - 1)]G1Jac
-var tGenG2 [((1 << bGen) - 1)]G2Jac
+// psi endomorphism on the twist: psi(x,y) = (conj(x)*psiFactorX, conj(y)*psiFactorY)
+var psiFactorX, psiFactorY E2
+
+// glsBasis is a reduced basis of the lattice {a | a[0] + a[1]*mu + a[2]*lambdaGLV + a[3]*mu*lambdaGLV = 0 mod r},
+// mu = gtLambda being the eigenvalue of psi on G2, used to split the scalars of the GLS scalar multiplication
+// in four quarters. glsBabai is r times the first row of glsBasis**-1
+var glsBasis [4][4]big.Int
+var glsBabai [4]big.Int
 
 func init() {
 
 	B.SetUint64(4)
+	bTwistCurveCoeff.A0.SetUint64(4)
+	bTwistCurveCoeff.A1.SetUint64(4)
 
 	g1Gen.X.SetString("2407661716269791519325591009883849385849641130669941829988413640673772478386903154468379397813974815295049686961384")
 	g1Gen.Y.SetString("821462058248938975967615814494474302717441302457255475448080663619194518120412959273482223614332657512049995916067")
@@ -73,17 +89,53 @@ func init() {
 	thirdRootOneG2.Square(&thirdRootOneG1)
 	lambdaGLV.SetString("228988810152649578064853576960394133503", 10)
 
+	glvBasis[0][0].SetInt64(1)
+	glvBasis[0][1].SetString("228988810152649578064853576960394133504", 10) // x**2
+	glvBasis[1][0].SetString("228988810152649578064853576960394133503", 10) // x**2-1
+	glvBasis[1][1].SetInt64(-1)
+
 	// binary decomposition of 15132376222941642752 little endian
 	loopCounter = [64]int8{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 1, 0, 1, 1}
 
-	tGenG1[0].Set(&g1Gen)
-	for j := 1; j < len(tGenG1)-1; j = j + 2 {
-		tGenG1[j].Set(&tGenG1[j/2]).DoubleAssign()
-		tGenG1[j+1].Set(&tGenG1[(j+1)/2]).AddAssign(&tGenG1[j/2])
-	}
-	tGenG2[0].Set(&g2Gen)
-	for j := 1; j < len(tGenG2)-1; j = j + 2 {
-		tGenG2[j].Set(&tGenG2[j/2]).DoubleAssign()
-		tGenG2[j+1].Set(&tGenG2[(j+1)/2]).AddAssign(&tGenG2[j/2])
+	xGen.SetString("-15132376222941642752", 10)
+	gtLambda.Set(&xGen) // p = x mod r
+
+	psiFactorX.SetString("0",
+		"4002409555221667392624310435006688643935503118305586438271171395842971157480381377015405980053539358417135540939437")
+	psiFactorY.SetString("2973677408986561043442465346520108879172042883009249989176415018091420807192182638567116318576472649347015917690530",
+		"1028732146235106349975324479215795277384839936929757896155643118032610843298655225875571310552543014690878354869257")
+
+	glsBasis[0][0].SetString("15132376222941642752", 10) // -x
+	glsBasis[0][1].SetInt64(1)
+	glsBasis[1][0].SetInt64(1)
+	glsBasis[1][1].SetString("15132376222941642752", 10) // -x
+	glsBasis[1][2].SetInt64(1)
+	glsBasis[2][2].SetString("15132376222941642752", 10) // -x
+	glsBasis[2][3].SetInt64(1)
+	glsBasis[3][0].SetInt64(1)
+	glsBasis[3][3].SetString("-15132376222941642752", 10) // x
+
+	glsBabai[0].SetString("3465144826073652318776269530687742778270252468765361963008", 10) // -x**3
+	glsBabai[1].SetString("-228988810152649578064853576960394133504", 10)                   // -x**2
+	glsBabai[2].SetString("15132376222941642752", 10)                                       // -x
+	glsBabai[3].SetInt64(1)
+}
+
+// Generators returns the generators of G1 and G2 used in this package, in jacobian and affine coordinates
+func Generators() (g1Jac G1Jac, g2Jac G2Jac, g1Aff G1Affine, g2Aff G2Affine) {
+	g1Jac.Set(&g1Gen)
+	g2Jac.Set(&g2Gen)
+	g1Aff.FromJacobian(&g1Gen)
+	g2Aff.FromJacobian(&g2Gen)
+	return
+}
+
+// CurveParams returns the parameters of the curve: field moduli, cofactors, embedding degree,
+// twist type and seed (cf gurvy.CurveParams)
+func CurveParams() gurvy.Params {
+	params, err := gurvy.CurveParams(ID)
+	if err != nil {
+		panic(err) // ID is always a known curve
 	}
+	return params
 }
@@ -17,6 +17,7 @@
 package bls381
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/leanovate/gopter"
@@ -342,6 +343,24 @@ func TestE12State(t *testing.T) {
 		},
 	}
 
+	setbytes := &commands.ProtoCommand{
+		Name: "SETBYTES",
+		RunFunc: func(systemUnderTest commands.SystemUnderTest) commands.Result {
+			var a E12
+			b := systemUnderTest.(*E12).Bytes()
+			if err := a.SetBytes(b[:]); err != nil {
+				return false
+			}
+			return systemUnderTest.(*E12).Equal(&a)
+		},
+		PostConditionFunc: func(state commands.State, result commands.Result) *gopter.PropResult {
+			if result.(bool) {
+				return &gopter.PropResult{Status: gopter.PropTrue}
+			}
+			return &gopter.PropResult{Status: gopter.PropFalse}
+		},
+	}
+
 	e6commands := &commands.ProtoCommands{
 		NewSystemUnderTestFunc: func(_ commands.State) commands.SystemUnderTest {
 			var a E12
@@ -350,7 +369,7 @@ func TestE12State(t *testing.T) {
 		},
 		InitialStateGen: gen.Const(false),
 		GenCommandFunc: func(state commands.State) gopter.Gen {
-			return gen.OneConstOf(subadd, mulinverse, inversetwice, squaremul, conjugate, frobenius, frobeniussquare, frobeniusscube, cyclotomicsquare)
+			return gen.OneConstOf(subadd, mulinverse, inversetwice, squaremul, conjugate, frobenius, frobeniussquare, frobeniusscube, cyclotomicsquare, setbytes)
 		},
 	}
 
@@ -360,6 +379,288 @@ func TestE12State(t *testing.T) {
 
 }
 
+func TestE12MulBySparse(t *testing.T) {
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	genA := GenE12()
+	genB := GenE2()
+	genC := GenE2()
+	genD := GenE2()
+
+	properties.Property("MulBy023 should match Mul", prop.ForAll(
+		func(a *E12, c0, c2, c3 *E2) bool {
+			var b, c, d E12
+			b.C0.B0.Set(c0)
+			b.C0.B2.Set(c2)
+			b.C1.B0.Set(c3)
+			c.Mul(a, &b)
+			d.MulBy023(a, c0, c2, c3)
+			return c.Equal(&d)
+		},
+		genA,
+		genB,
+		genC,
+		genD,
+	))
+
+	properties.Property("Mul023By023 then MulBy01235 should match two MulBy023", prop.ForAll(
+		func(a *E12, c0, c2, c3 *E2) bool {
+			var b, c E12
+			var d0, d2, d3 E2
+			d0.SetRandom()
+			d2.SetRandom()
+			d3.SetRandom()
+			b.MulBy023(a, c0, c2, c3).MulBy023(&b, &d0, &d2, &d3)
+			prod := Mul023By023(c0, c2, c3, &d0, &d2, &d3)
+			c.MulBy01235(a, &prod)
+			return b.Equal(&c)
+		},
+		genA,
+		genB,
+		genC,
+		genD,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestE12CyclotomicSquareCompressed(t *testing.T) {
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	genA := GenE12()
+
+	// a**((p**6-1)*(p**2+1)) is in the cyclotomic subgroup
+	toCyclotomic := func(a *E12) *E12 {
+		var b E12
+		b.Inverse(a)
+		a.Conjugate(a).Mul(a, &b)
+		b.FrobeniusSquare(a)
+		return a.Mul(a, &b)
+	}
+
+	properties.Property("DecompressKarabina(CyclotomicSquareCompressed(a)) should equal CyclotomicSquare(a)", prop.ForAll(
+		func(a *E12) bool {
+			var b, c E12
+			toCyclotomic(a)
+			b.CyclotomicSquareCompressed(a).DecompressKarabina(&b)
+			c.CyclotomicSquare(a)
+			return b.Equal(&c)
+		},
+		genA,
+	))
+
+	properties.Property("Having the receiver as operand (DecompressKarabina) should output the same result", prop.ForAll(
+		func(a *E12) bool {
+			var b, c E12
+			toCyclotomic(a)
+			b.CyclotomicSquareCompressed(a)
+			c.DecompressKarabina(&b)
+			b.DecompressKarabina(&b)
+			return b.Equal(&c)
+		},
+		genA,
+	))
+
+	properties.Property("BatchDecompressKarabina should match DecompressKarabina on a chain of compressed squares", prop.ForAll(
+		func(a *E12) bool {
+			var squares [8]E12
+			toCyclotomic(a)
+			squares[0].CyclotomicSquareCompressed(a)
+			for i := 1; i < len(squares); i++ {
+				squares[i].CyclotomicSquareCompressed(&squares[i-1])
+			}
+			var b, c E12
+			b.DecompressKarabina(&squares[7])
+			c.Set(a)
+			BatchDecompressKarabina(squares[:])
+			for i := 0; i < len(squares); i++ {
+				c.CyclotomicSquare(&c)
+				if !c.Equal(&squares[i]) {
+					return false
+				}
+			}
+			return b.Equal(&squares[7])
+		},
+		genA,
+	))
+
+	properties.Property("Expt should match Exp by the curve parameter", prop.ForAll(
+		func(a *E12) bool {
+			var b, c E12
+			var t big.Int
+			toCyclotomic(a)
+			t.Abs(&xGen)
+			b.Exp(a, t)
+			if xGen.Sign() < 0 {
+				b.Conjugate(&b)
+			}
+			c.Expt(a)
+			return b.Equal(&c)
+		},
+		genA,
+	))
+
+	properties.Property("compressed squares of 1 should decompress to 1", prop.ForAll(
+		func(a *E12) bool {
+			var one E12
+			one.SetOne()
+			a.CyclotomicSquareCompressed(&one).CyclotomicSquareCompressed(a).DecompressKarabina(a)
+			return a.Equal(&one)
+		},
+		genA,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestE12Torus(t *testing.T) {
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	genA := GenE12()
+	genB := GenE12()
+
+	// a**((p**6-1)*(p**2+1)) is in the cyclotomic subgroup
+	toCyclotomic := func(a *E12) *E12 {
+		var b E12
+		b.Inverse(a)
+		a.Conjugate(a).Mul(a, &b)
+		b.FrobeniusSquare(a)
+		return a.Mul(a, &b)
+	}
+
+	properties.Property("DecompressT2(CompressT2(a)) should equal a", prop.ForAll(
+		func(a *E12) bool {
+			var y E6
+			var b E12
+			toCyclotomic(a)
+			b.DecompressT2(y.CompressT2(a))
+			return a.Equal(&b)
+		},
+		genA,
+	))
+
+	properties.Property("T2 compression of 1 should be 0 and decompress to 1", prop.ForAll(
+		func(a *E12) bool {
+			var y E6
+			a.SetOne()
+			y.CompressT2(a)
+			return y.IsZero() && a.DecompressT2(&y).Equal(a)
+		},
+		genA,
+	))
+
+	properties.Property("MulT2 should match Mul", prop.ForAll(
+		func(a, b *E12) bool {
+			var x, y, xy E6
+			var c, d E12
+			toCyclotomic(a)
+			toCyclotomic(b)
+			x.CompressT2(a)
+			y.CompressT2(b)
+			xy.MulT2(&x, &y)
+			c.Mul(a, b)
+			d.DecompressT2(&xy)
+			return c.Equal(&d)
+		},
+		genA,
+		genB,
+	))
+
+	properties.Property("MulT2 by the compressed inverse or by 1 should output the expected result", prop.ForAll(
+		func(a *E12) bool {
+			var x, y, z, one E6
+			toCyclotomic(a)
+			x.CompressT2(a)
+			y.InverseT2(&x)
+			z.MulT2(&x, &y)
+			return z.IsZero() && y.MulT2(&x, &one).Equal(&x)
+		},
+		genA,
+	))
+
+	properties.Property("SquareT2 and InverseT2 should match CyclotomicSquare and Conjugate", prop.ForAll(
+		func(a *E12) bool {
+			var x, sq, inv E6
+			var c, d E12
+			toCyclotomic(a)
+			x.CompressT2(a)
+			sq.SquareT2(&x)
+			inv.InverseT2(&x)
+			c.CyclotomicSquare(a)
+			d.DecompressT2(&sq)
+			if !c.Equal(&d) {
+				return false
+			}
+			c.Conjugate(a)
+			d.DecompressT2(&inv)
+			return c.Equal(&d)
+		},
+		genA,
+	))
+
+	properties.Property("DecompressT6(CompressT6(a)) should equal a", prop.ForAll(
+		func(a *E12) bool {
+			var b E12
+			toCyclotomic(a)
+			y, err := a.CompressT6()
+			if err != nil {
+				return false
+			}
+			if err := b.DecompressT6(&y); err != nil {
+				return false
+			}
+			return a.Equal(&b)
+		},
+		genA,
+	))
+
+	properties.Property("T6 compression of 1 should be (0, 0) and decompress to 1", prop.ForAll(
+		func(a *E12) bool {
+			a.SetOne()
+			y, err := a.CompressT6()
+			if err != nil || !y[0].IsZero() || !y[1].IsZero() {
+				return false
+			}
+			a.SetRandom()
+			return a.DecompressT6(&y) == nil && a.IsInCyclotomicSubGroup() && a.C1.IsZero()
+		},
+		genA,
+	))
+
+	properties.Property("DecompressT6 should output an element of the cyclotomic subgroup", prop.ForAll(
+		func(a *E12) bool {
+			var y [2]E2
+			y[0].SetRandom()
+			y[1].SetRandom()
+			return a.DecompressT6(&y) == nil && a.IsInCyclotomicSubGroup()
+		},
+		genA,
+	))
+
+	properties.Property("DecompressT6 should reject (0, y2) with y2 != 0", prop.ForAll(
+		func(a *E12) bool {
+			var y [2]E2
+			y[1].SetOne()
+			return a.DecompressT6(&y) == ErrTorusExceptional
+		},
+		genA,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
 // ------------------------------------------------------------
 // benches
 
@@ -402,6 +703,36 @@ func BenchmarkE12Cyclosquare(b *testing.B) {
 	}
 }
 
+func BenchmarkE12CyclosquareCompressed(b *testing.B) {
+	var a E12
+	a.SetRandom()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.CyclotomicSquareCompressed(&a)
+	}
+}
+
+func BenchmarkE12DecompressKarabina(b *testing.B) {
+	var a, c E12
+	a.SetRandom()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.DecompressKarabina(&a)
+	}
+}
+func BenchmarkE12MulBy023(b *testing.B) {
+	var a E12
+	var c0, c2, c3 E2
+	a.SetRandom()
+	c0.SetRandom()
+	c2.SetRandom()
+	c3.SetRandom()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.MulBy023(&a, &c0, &c2, &c3)
+	}
+}
+
 func BenchmarkE12Square(b *testing.B) {
 	var a E12
 	a.SetRandom()
@@ -473,3 +804,41 @@ func BenchmarkE12FinalExponentiation(b *testing.B) {
 		a.FinalExponentiation(&a)
 	}
 }
+
+func BenchmarkE12Torus(b *testing.B) {
+	var a, c E12
+	var x, y E6
+	a.SetRandom()
+	c.Inverse(&a)
+	a.Conjugate(&a).Mul(&a, &c)
+	c.FrobeniusSquare(&a)
+	a.Mul(&a, &c)
+	x.CompressT2(&a)
+	y6, _ := a.CompressT6()
+
+	b.Run("CompressT2", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			y.CompressT2(&a)
+		}
+	})
+	b.Run("DecompressT2", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c.DecompressT2(&x)
+		}
+	})
+	b.Run("MulT2", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			y.MulT2(&x, &x)
+		}
+	})
+	b.Run("CompressT6", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = a.CompressT6()
+		}
+	})
+	b.Run("DecompressT6", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = c.DecompressT6(&y6)
+		}
+	})
+}
@@ -17,12 +17,15 @@
 package bls381
 
 import (
+	"context"
+	"crypto/subtle"
 	"math/big"
-	"runtime"
+	"sync"
+	"unsafe"
 
 	"github.com/consensys/gurvy/bls381/fp"
 	"github.com/consensys/gurvy/bls381/fr"
-	"github.com/consensys/gurvy/utils/debug"
+	"github.com/consensys/gurvy/utils"
 )
 
 // G1Jac is a point with fp.Element coordinates
@@ -40,7 +43,7 @@ type G1Affine struct {
 	X, Y fp.Element
 }
 
-//  g1JacExtended parameterized jacobian coordinates (x=X/ZZ, y=Y/ZZZ, ZZ**3=ZZZ**2)
+// g1JacExtended parameterized jacobian coordinates (x=X/ZZ, y=Y/ZZZ, ZZ**3=ZZZ**2)
 type g1JacExtended struct {
 	X, Y, ZZ, ZZZ fp.Element
 }
@@ -146,6 +149,76 @@ func (p *g1JacExtended) double(q *G1Affine) *g1JacExtended {
 	return p
 }
 
+// add sets p to p+q, in extended Jacobian coordinates
+// http://www.hyperelliptic.org/EFD/ g1p/auto-shortw-xyzz.html#addition-add-2008-s
+func (p *g1JacExtended) add(q *g1JacExtended) *g1JacExtended {
+
+	//if q is infinity return p
+	if q.ZZ.IsZero() {
+		return p
+	}
+	// p is infinity, return q
+	if p.ZZ.IsZero() {
+		*p = *q
+		return p
+	}
+
+	var U1, U2, S1, S2, P, R, PP, PPP, Q, V fp.Element
+
+	// p2: q, p1: p
+	U1.Mul(&p.X, &q.ZZ)
+	U2.Mul(&q.X, &p.ZZ)
+	S1.Mul(&p.Y, &q.ZZZ)
+	S2.Mul(&q.Y, &p.ZZZ)
+	P.Sub(&U2, &U1)
+	R.Sub(&S2, &S1)
+	if P.IsZero() {
+		if R.IsZero() {
+			return p.doubleAssign()
+		}
+		// q = -p
+		return p.SetInfinity()
+	}
+	PP.Square(&P)
+	PPP.Mul(&P, &PP)
+	Q.Mul(&U1, &PP)
+	p.X.Square(&R).
+		Sub(&p.X, &PPP).
+		Sub(&p.X, &Q).
+		Sub(&p.X, &Q)
+	V.Sub(&Q, &p.X).Mul(&V, &R)
+	S1.Mul(&S1, &PPP)
+	p.Y.Sub(&V, &S1)
+	p.ZZ.Mul(&p.ZZ, &q.ZZ).Mul(&p.ZZ, &PP)
+	p.ZZZ.Mul(&p.ZZZ, &q.ZZZ).Mul(&p.ZZZ, &PPP)
+
+	return p
+}
+
+// doubleAssign sets p to 2p, in extended Jacobian coordinates
+// http://www.hyperelliptic.org/EFD/ g1p/auto-shortw-xyzz.html#doubling-dbl-2008-s-1
+func (p *g1JacExtended) doubleAssign() *g1JacExtended {
+
+	var U, V, W, S, M, XX fp.Element
+
+	U.Double(&p.Y)
+	V.Square(&U)
+	W.Mul(&U, &V)
+	S.Mul(&p.X, &V)
+	XX.Square(&p.X)
+	M.Double(&XX).
+		Add(&M, &XX) // -> + a, but a=0 here
+	U.Mul(&W, &p.Y)
+	p.X.Square(&M).
+		Sub(&p.X, &S).
+		Sub(&p.X, &S)
+	p.Y.Sub(&S, &p.X).Mul(&p.Y, &M).Sub(&p.Y, &U)
+	p.ZZ.Mul(&p.ZZ, &V)
+	p.ZZZ.Mul(&p.ZZZ, &W)
+
+	return p
+}
+
 // Set set p to the provided point
 func (p *G1Jac) Set(a *G1Jac) *G1Jac {
 	p.X.Set(&a.X)
@@ -174,6 +247,13 @@ func (p *G1Affine) Equal(a *G1Affine) bool {
 	return p.X.Equal(&a.X) && p.Y.Equal(&a.Y)
 }
 
+// Set sets p to the provided point
+func (p *G1Affine) Set(a *G1Affine) *G1Affine {
+	p.X.Set(&a.X)
+	p.Y.Set(&a.Y)
+	return p
+}
+
 // Clone returns a copy of self
 func (p *G1Jac) Clone() *G1Jac {
 	return &G1Jac{
@@ -269,6 +349,75 @@ func (p *G1Affine) IsInfinity() bool {
 	return p.X.IsZero() && p.Y.IsZero()
 }
 
+// IsOnCurve returns true if p satisfies the curve equation
+// (the point at infinity is considered on the curve)
+func (p *G1Affine) IsOnCurve() bool {
+	if p.IsInfinity() {
+		return true
+	}
+	var left, right fp.Element
+	left.Square(&p.Y)
+	right.Square(&p.X).Mul(&right, &p.X)
+	right.Add(&right, &B)
+	return left.Equal(&right)
+}
+
+// IsOnCurve returns true if p satisfies the curve equation Y**2 = X**3 + b*Z**6
+// (the point at infinity is considered on the curve)
+func (p *G1Jac) IsOnCurve() bool {
+	var left, right, tmp fp.Element
+	left.Square(&p.Y)
+	right.Square(&p.X).Mul(&right, &p.X)
+	tmp.Square(&p.Z).
+		Mul(&tmp, &p.Z).
+		Square(&tmp)
+	tmp.Mul(&tmp, &B)
+	right.Add(&right, &tmp)
+	return left.Equal(&right)
+}
+
+// IsInSubGroup returns true if p is on the r-torsion subgroup
+func (p *G1Affine) IsInSubGroup() bool {
+	var _p G1Jac
+	_p.FromAffine(p)
+	return _p.IsInSubGroup()
+}
+
+// IsInSubGroup returns true if p is on the r-torsion subgroup
+// It checks that phi(p) = [lambdaGLV]p, phi being the endomorphism (x,y) -> (thirdRootOneG1*x, y)
+func (p *G1Jac) IsInSubGroup() bool {
+	var res, phip G1Jac
+	phip.phi(p)
+	res.mulWindowed(p, &lambdaGLV)
+	return p.IsOnCurve() && res.Equal(&phip)
+}
+
+// ClearCofactor maps a point in E(Fp) to the r-torsion subgroup
+func (p *G1Affine) ClearCofactor(a *G1Affine) *G1Affine {
+	var _p G1Jac
+	_p.FromAffine(a)
+	_p.ClearCofactor(&_p)
+	p.FromJacobian(&_p)
+	return p
+}
+
+// ClearCofactor maps a point in E(Fp) to the r-torsion subgroup
+// It multiplies by 1-x, which is enough to clear the cofactor (x being the seed of the curve)
+// cf https://eprint.iacr.org/2019/403.pdf, 5
+func (p *G1Jac) ClearCofactor(a *G1Jac) *G1Jac {
+	var res G1Jac
+	res.mulWindowed(a, &xGen).Neg(&res).AddAssign(a)
+	p.Set(&res)
+	return p
+}
+
+// phi sets p to phi(a), where phi: (x,y) -> (thirdRootOneG1*x, y), and returns p
+func (p *G1Jac) phi(a *G1Jac) *G1Jac {
+	p.Set(a)
+	p.X.Mul(&p.X, &thirdRootOneG1)
+	return p
+}
+
 // AddAssign point addition in montgomery form
 // https://hyperelliptic.org/EFD/g1p/auto-shortw-jacobian-3.html#addition-add-2007-bl
 func (p *G1Jac) AddAssign(a *G1Jac) *G1Jac {
@@ -435,216 +584,737 @@ func (p *G1Jac) ScalarMultiplication(a *G1Affine, s *big.Int) *G1Jac {
 	return p
 }
 
-// ScalarMulGLV performs scalar multiplication using GLV (without the lattice reduction)
-func (p *G1Jac) ScalarMulGLV(a *G1Affine, s *big.Int) *G1Jac {
+// mulWindowed 2-bits windowed exponentiation, s may be negative
+func (p *G1Jac) mulWindowed(a *G1Jac, s *big.Int) *G1Jac {
+
+	var res G1Jac
+	var ops [3]G1Jac
 
-	var g1, phig1, res G1Jac
-	var phig1Affine G1Affine
 	res.Set(&g1Infinity)
-	g1.FromAffine(a)
-	phig1.Set(&g1)
-	phig1.X.Mul(&phig1.X, &thirdRootOneG1)
+	ops[0].Set(a)
+	if s.Sign() == -1 {
+		ops[0].Neg(&ops[0])
+	}
+	ops[1].Double(&ops[0])
+	ops[2].Set(&ops[0]).AddAssign(&ops[1])
+
+	b := s.Bytes()
+	for i := range b {
+		w := b[i]
+		mask := byte(0xc0)
+		for j := 0; j < 4; j++ {
+			res.DoubleAssign().DoubleAssign()
+			c := (w & mask) >> (6 - 2*j)
+			if c != 0 {
+				res.AddAssign(&ops[c-1])
+			}
+			mask = mask >> 2
+		}
+	}
+	p.Set(&res)
+
+	return p
+}
+
+// ScalarMulGLV performs scalar multiplication using GLV
+// s is split as s = k0 + k1*lambdaGLV mod r with |k0|, |k1| ~ sqrt(r) using the reduced basis glvBasis,
+// then [k0]a + [k1]phi(a) is computed in a single loop from the interleaved width-w NAFs of k0 and k1
+func (p *G1Jac) ScalarMulGLV(a *G1Affine, s *big.Int) *G1Jac {
 
-	phig1Affine.FromJacobian(&phig1)
+	const w = 5
 
-	// s = s1*lambda+s2
-	var s1, s2 big.Int
-	s1.DivMod(s, &lambdaGLV, &s2)
+	var k [2]big.Int
+	var _s big.Int
+	_s.Mod(s, fr.Modulus())
+	utils.SplitScalar(&_s, &glvBasis, &k)
 
-	chTasks := []chan struct{}{
-		make(chan struct{}),
-		make(chan struct{}),
+	// table[0][i] = [2i+1]a, table[1][i] = [2i+1]phi(a)
+	var table [2][1 << (w - 2)]G1Jac
+	table[0][0].FromAffine(a)
+	table[0][0].oddMultiples(table[0][:])
+	for i := range table[1] {
+		table[1][i].phi(&table[0][i])
 	}
 
-	// s1 part (on phi(g1)=lambda*g1)
-	go func() {
-		phig1.ScalarMultiplication(&phig1Affine, &s1)
-		chTasks[0] <- struct{}{}
-	}()
+	return p.mulWnaf(w, k[:], [][]G1Jac{table[0][:], table[1][:]})
+}
 
-	// s2 part (on g1)
-	go func() {
-		g1.ScalarMultiplication(a, &s2)
-		chTasks[1] <- struct{}{}
-	}()
+// DoubleScalarMul computes [s1]a + [s2]b and returns p
+// Both scalar multiplications share a single doubling loop, see multiScalarMul
+func (p *G1Jac) DoubleScalarMul(a, b *G1Affine, s1, s2 *big.Int) *G1Jac {
+	points := [2]G1Affine{*a, *b}
+	var scalars [2]big.Int
+	scalars[0].Set(s1)
+	scalars[1].Set(s2)
+	return p.multiScalarMul(points[:], scalars[:])
+}
 
-	<-chTasks[0]
-	res.AddAssign(&phig1)
-	<-chTasks[1]
-	res.AddAssign(&g1)
+// multiScalarMul sets p to [scalars[0]]points[0] + .. + [scalars[n-1]]points[n-1] with Straus' method, and returns p
+// Each scalar is split with GLV, then the 2n width-w NAFs are interleaved in a single loop (see mulWnaf), so that
+// the n scalar multiplications share about fr.Bits/2 doublings. Meant for small n, the tables holding 2**(w-1)*n points
+func (p *G1Jac) multiScalarMul(points []G1Affine, scalars []big.Int) *G1Jac {
+
+	const w = 5
+	const tableSize = 1 << (w - 2)
+
+	r := fr.Modulus()
+	k := make([]big.Int, 2*len(points))
+	tables := make([][]G1Jac, 2*len(points))
+	buf := make([]G1Jac, 2*len(points)*tableSize)
+	var s big.Int
+	var ki [2]big.Int
+	for i := range points {
+		s.Mod(&scalars[i], r)
+		utils.SplitScalar(&s, &glvBasis, &ki)
+		k[2*i].Set(&ki[0])
+		k[2*i+1].Set(&ki[1])
+
+		// tables[2i][j] = [2j+1]points[i], tables[2i+1][j] = [2j+1]phi(points[i])
+		tables[2*i] = buf[2*i*tableSize : (2*i+1)*tableSize]
+		tables[2*i+1] = buf[(2*i+1)*tableSize : (2*i+2)*tableSize]
+		tables[2*i][0].FromAffine(&points[i])
+		tables[2*i][0].oddMultiples(tables[2*i])
+		for j := range tables[2*i+1] {
+			tables[2*i+1][j].phi(&tables[2*i][j])
+		}
+	}
 
+	return p.mulWnaf(w, k, tables)
+}
+
+// ScalarMulCT performs scalar multiplication in constant time, for secret scalars
+// s is made odd (s or r-s, the result being negated back in the latter case), then recoded with the regular
+// signed window method (Joye-Tunstall) in ceil(fr.Bits/w) odd digits in (-2**w, 2**w), so that the sequence
+// of doublings and additions is fixed. The table lookups and the negations use conditional moves.
+// The additions only branch on their exceptional cases (p = ±a), which a handful of scalars close to r reach
+func (p *G1Jac) ScalarMulCT(a *G1Affine, s *fr.Element) *G1Jac {
+
+	const w = 4
+	const nbDigits = (fr.Bits + w - 1) / w
+
+	// k = s or r-s, whichever is odd
+	var sNeg fr.Element
+	sNeg.Neg(s)
+	k, kNeg := s.ToRegular(), sNeg.ToRegular()
+	isZero := utils.IsZero(k[:])
+	even := (k[0] & 1) ^ 1
+	utils.CondCopy(k[:], kNeg[:], even)
+
+	// table[i] = [2i+1]a
+	var table [1 << (w - 1)]G1Jac
+	table[0].FromAffine(a)
+	table[0].oddMultiples(table[:])
+
+	// the most significant digit is positive, the other ones are 2*window+1-2**w
+	var res, tmp G1Jac
+	res.lookupCT(table[:], utils.Window(k[:], w*(nbDigits-1)+1, w))
+	for i := nbDigits - 2; i >= 0; i-- {
+		for j := 0; j < w; j++ {
+			res.DoubleAssign()
+		}
+		d := 2*utils.Window(k[:], uint(w*i+1), w) + 1 - 1<<w
+		neg := d >> 63
+		abs := (d ^ -neg) + neg
+		tmp.lookupCT(table[:], abs>>1)
+		tmp.condNeg(neg)
+		res.AddAssign(&tmp)
+	}
+	res.condNeg(even)
+	res.cmov(&g1Infinity, isZero)
 	p.Set(&res)
 
 	return p
 }
 
-// MultiExp complexity O(n)
-func (p *G1Jac) MultiExp(points []G1Affine, scalars []fr.Element) chan G1Jac {
+// lookupCT sets p to table[idx] by scanning the whole table, and returns p
+func (p *G1Jac) lookupCT(table []G1Jac, idx uint64) *G1Jac {
+	for i := range table {
+		p.cmov(&table[i], uint64(subtle.ConstantTimeEq(int32(i), int32(idx))))
+	}
+	return p
+}
 
-	nbPoints := len(points)
-	debug.Assert(nbPoints == len(scalars))
+// condNeg sets p to -p if cond is 1 and leaves it unchanged if cond is 0, and returns p
+func (p *G1Jac) condNeg(cond uint64) *G1Jac {
+	var neg G1Jac
+	neg.Neg(p)
+	return p.cmov(&neg, cond)
+}
 
-	chRes := make(chan G1Jac, 1)
+// cmov sets p to a if cond is 1 and leaves it unchanged if cond is 0, in constant time, and returns p
+func (p *G1Jac) cmov(a *G1Jac, cond uint64) *G1Jac {
+	utils.CondCopy(p.X[:], a.X[:], cond)
+	utils.CondCopy(p.Y[:], a.Y[:], cond)
+	utils.CondCopy(p.Z[:], a.Z[:], cond)
+	return p
+}
+
+// oddMultiples sets table[i] to [2i+1]p
+func (p *G1Jac) oddMultiples(table []G1Jac) {
+	var double G1Jac
+	double.Double(p)
+	table[0].Set(p)
+	for i := 1; i < len(table); i++ {
+		table[i].Set(&table[i-1]).AddAssign(&double)
+	}
+}
+
+// mulWnaf sets p to [k[0]]b_0 + .. + [k[n-1]]b_(n-1), tables[j] holding the odd multiples [1]b_j, [3]b_j, ..,
+// [2**(w-1)-1]b_j, and returns p. It runs a single loop over the interleaved width-w NAFs of the k[j],
+// which may be negative
+func (p *G1Jac) mulWnaf(w uint, k []big.Int, tables [][]G1Jac) *G1Jac {
+
+	digits := make([][fr.Limbs*64 + 1]int8, len(k))
+	var abs big.Int
+	n := 0
+	for j := range k {
+		abs.Abs(&k[j])
+		l := utils.WnafDecomposition(&abs, w, digits[j][:])
+		if k[j].Sign() < 0 {
+			for i := 0; i < l; i++ {
+				digits[j][i] = -digits[j][i]
+			}
+		}
+		if l > n {
+			n = l
+		}
+	}
+
+	var res, tmp G1Jac
+	res.Set(&g1Infinity)
+	for i := n - 1; i >= 0; i-- {
+		res.DoubleAssign()
+		for j := range tables {
+			if d := digits[j][i]; d > 0 {
+				res.AddAssign(&tables[j][d>>1])
+			} else if d < 0 {
+				tmp.Neg(&tables[j][(-d)>>1])
+				res.AddAssign(&tmp)
+			}
+		}
+	}
+	p.Set(&res)
+
+	return p
+}
+
+// MultiExp sets p to [scalars[0]]points[0] + .. + [scalars[n-1]]points[n-1], the scalars being in regular form
+// (not Montgomery) and reduced mod r, and returns p. opts may be nil, see MultiExpOptions.
+// On error (invalid inputs or options, or ctx done before the end of the computation, in which case ctx.Err() is
+// returned), p is left unchanged
+func (p *G1Jac) MultiExp(ctx context.Context, points []G1Affine, scalars []fr.Element, opts *MultiExpOptions) (G1Jac, error) {
+
+	nbPoints := len(points)
+	if nbPoints != len(scalars) {
+		return *p, ErrMultiExpInputSize
+	}
+	if opts == nil {
+		opts = &MultiExpOptions{}
+	}
+	if opts.C == 1 || opts.C > MaxMultiExpWindowSize {
+		return *p, ErrMultiExpWindowSize
+	}
+	if err := ctx.Err(); err != nil {
+		return *p, err
+	}
+	nbTasks := opts.nbTasks()
 
-	// under 50 points, the windowed multi exp performs better
-	const minPoints = 50
-	if nbPoints <= minPoints {
-		var tmp G1Jac
-		var s big.Int
-		p.Set(&g1Infinity)
+	// for a few points, Straus' method performs better than the bucket method. It runs on a single core, so the
+	// crossover (empirical, ~200 points on one core) goes down as the bucket method gets more cores
+	if opts.C == 0 && nbPoints <= 200/nbTasks {
+		_scalars := make([]big.Int, nbPoints)
 		for i := 0; i < nbPoints; i++ {
-			scalars[i].ToBigInt(&s)
-			tmp.ScalarMulGLV(&points[i], &s)
-			p.AddAssign(&tmp)
+			scalars[i].ToBigInt(&_scalars[i])
 		}
+		p.multiScalarMul(points, _scalars)
+		return *p, nil
 	}
 
-	// empirical values
-	var nbChunks, chunkSize int
-	var mask uint64
-	if nbPoints <= 10000 {
-		chunkSize = 8
-	} else if nbPoints <= 80000 {
-		chunkSize = 11
-	} else if nbPoints <= 400000 {
-		chunkSize = 13
-	} else if nbPoints <= 800000 {
-		chunkSize = 14
-	} else {
-		chunkSize = 16
+	c := opts.windowSize(nbPoints)
+
+	// each task holds 2**(c-1) buckets, and each point its signed digits (2 bytes each)
+	batchSize := nbPoints
+	if opts.MaxMemory > 0 {
+		bucketsMemory := multiExpBucketsMemoryG1(c, nbTasks)
+		if bucketsMemory >= opts.MaxMemory {
+			return *p, ErrMultiExpMemory
+		}
+		max := (opts.MaxMemory - bucketsMemory) / (multiExpNbChunks(c) * 2)
+		if max < 1 {
+			return *p, ErrMultiExpMemory
+		}
+		if max < batchSize {
+			batchSize = max
+		}
+	}
+
+	var res, tmp G1Jac
+	res.Set(&g1Infinity)
+	for start := 0; start < nbPoints; start += batchSize {
+		end := start + batchSize
+		if end > nbPoints {
+			end = nbPoints
+		}
+		if err := tmp.multiExpBuckets(ctx, points[start:end], scalars[start:end], c, nbTasks); err != nil {
+			return *p, err
+		}
+		res.AddAssign(&tmp)
 	}
+	p.Set(&res)
 
-	const sizeScalar = fr.Limbs * 64
+	return *p, nil
+}
 
-	var bitsForTask [][]int
-	if sizeScalar%chunkSize == 0 {
-		counter := sizeScalar - 1
-		nbChunks = sizeScalar / chunkSize
-		bitsForTask = make([][]int, nbChunks)
-		for i := 0; i < nbChunks; i++ {
-			bitsForTask[i] = make([]int, chunkSize)
-			for j := 0; j < chunkSize; j++ {
-				bitsForTask[i][j] = counter
-				counter--
-			}
+// multiExpBucketsMemoryG1 returns the memory (in bytes) allocated by the buckets of the nbTasks tasks
+// of the bucket method with c-bit windows: the buckets in extended Jacobian coordinates, or the buckets and the
+// batch of g1BatchAffineBuckets
+func multiExpBucketsMemoryG1(c uint, nbTasks int) int {
+	nbBuckets := 1 << (c - 1)
+	if c < batchAffineMinWindowSize {
+		return nbTasks * nbBuckets * int(unsafe.Sizeof(g1JacExtended{}))
+	}
+
+	// buckets, overflow, empty and inBatch
+	bucketSize := int(unsafe.Sizeof(G1Affine{})+unsafe.Sizeof(g1JacExtended{})) + 2
+
+	// batchBuckets, batchPoints and prefix
+	var coord fp.Element
+	batchSize := int(unsafe.Sizeof(int(0)) + unsafe.Sizeof(G1Affine{}) + unsafe.Sizeof(coord))
+
+	return nbTasks * (nbBuckets*bucketSize + batchAffineSize(nbBuckets)*batchSize)
+}
+
+// multiExpBuckets sets p to [scalars[0]]points[0] + .. + [scalars[n-1]]points[n-1] with the bucket method
+// (Pippenger), and returns ctx.Err() if ctx is done before the end of the computation.
+// The scalars are recoded in signed c-bit digits in [-2**(c-1), 2**(c-1)), so that a window only needs 2**(c-1)
+// buckets, a point being added to the bucket |digit| or its opposite to the bucket -digit. The window sum,
+// sum of [k]bucket[k], is computed by a running sum of the buckets from the top one.
+// From batchAffineMinWindowSize bits, the buckets are in affine coordinates (see g1BatchAffineBuckets).
+// The windows are spread over nbTasks goroutines, then the window sums are combined by double and add
+func (p *G1Jac) multiExpBuckets(ctx context.Context, points []G1Affine, scalars []fr.Element, c uint, nbTasks int) error {
+
+	nbPoints := len(points)
+	nbChunks := multiExpNbChunks(c)
+	nbBuckets := 1 << (c - 1)
+	done := ctx.Done()
+
+	// digits[i*nbPoints+j] is the i-th signed digit of scalars[j], as an int16
+	digits := make([]uint16, nbChunks*nbPoints)
+	var wg sync.WaitGroup
+	batchSize := (nbPoints + nbTasks - 1) / nbTasks
+	for start := 0; start < nbPoints; start += batchSize {
+		end := start + batchSize
+		if end > nbPoints {
+			end = nbPoints
 		}
-	} else {
-		counter := sizeScalar - 1
-		nbChunks = sizeScalar/chunkSize + 1
-		bitsForTask = make([][]int, nbChunks)
-		for i := 0; i < nbChunks; i++ {
-			if i < nbChunks-1 {
-				bitsForTask[i] = make([]int, chunkSize)
-			} else {
-				bitsForTask[i] = make([]int, sizeScalar%chunkSize)
-			}
-			for j := 0; j < chunkSize && counter >= 0; j++ {
-				bitsForTask[i][j] = counter
-				counter--
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			half := uint64(nbBuckets)
+			for j := start; j < end; j++ {
+				if j%4096 == 0 {
+					select {
+					case <-done:
+						return
+					default:
+					}
+				}
+				var carry uint64
+				for i := 0; i < nbChunks; i++ {
+					d := utils.Window(scalars[j][:], uint(i)*c, c) + carry
+					carry = 0
+					if d >= half {
+						// digit d - 2**c, its 16 low bits being its two's complement
+						carry = 1
+						d -= 1 << c
+					}
+					digits[i*nbPoints+j] = uint16(d)
+				}
 			}
-		}
+		}(start, end)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	accumulators := make([]G1Jac, nbChunks)
-	chIndices := make([]chan struct{}, nbChunks)
-	chPoints := make([]chan struct{}, nbChunks)
+	// windowSums[i] = sum of [k]bucket[k] for the i-th window
+	windowSums := make([]G1Jac, nbChunks)
+	chunks := make(chan int, nbChunks)
 	for i := 0; i < nbChunks; i++ {
-		chIndices[i] = make(chan struct{}, 1)
-		chPoints[i] = make(chan struct{}, 1)
-	}
-
-	mask = (1 << chunkSize) - 1
-	nbPointsPerSlots := nbPoints / int(mask)
-	// [][] is more efficient than [][][] for storage, elements are accessed via i*nbChunks+k
-	indices := make([][]int, int(mask)*nbChunks)
-	for i := 0; i < int(mask)*nbChunks; i++ {
-		indices[i] = make([]int, 0, nbPointsPerSlots)
-	}
-
-	// if chunkSize=8, nbChunks=32 (the scalars are chunkSize*nbChunks bits long)
-	// for each 32 chunk, there is a list of 2**8=256 list of indices
-	// for the i-th chunk, accumulateIndices stores in the k-th list all the indices of points
-	// for which the i-th chunk of 8 bits is equal to k
-	accumulateIndices := func(cpuID, nbTasks, n int) {
-		for i := 0; i < nbTasks; i++ {
-			task := cpuID + i*n
-			idx := task*int(mask) - 1
-			for j := 0; j < nbPoints; j++ {
-				val := 0
-				for k := 0; k < len(bitsForTask[task]); k++ {
-					val = val << 1
-					c := bitsForTask[task][k] / int(64)
-					o := bitsForTask[task][k] % int(64)
-					b := (scalars[j][c] >> o) & 1
-					val += int(b)
+		chunks <- i
+	}
+	close(chunks)
+
+	if nbTasks > nbChunks {
+		nbTasks = nbChunks
+	}
+	wg.Add(nbTasks)
+	for t := 0; t < nbTasks; t++ {
+		go func() {
+			defer wg.Done()
+
+			// the buckets are reused from one window to the next
+			var batchAffineBuckets *g1BatchAffineBuckets
+			var buckets []g1JacExtended
+			if c >= batchAffineMinWindowSize {
+				batchAffineBuckets = newG1BatchAffineBuckets(nbBuckets)
+			} else {
+				buckets = make([]g1JacExtended, nbBuckets)
+			}
+
+			for chunk := range chunks {
+				chunkDigits := digits[chunk*nbPoints : (chunk+1)*nbPoints]
+				var ok bool
+				if batchAffineBuckets != nil {
+					ok = windowSums[chunk].windowSumBatchAffine(done, points, chunkDigits, batchAffineBuckets)
+				} else {
+					ok = windowSums[chunk].windowSum(done, points, chunkDigits, buckets)
 				}
-				if val != 0 {
-					indices[idx+int(val)] = append(indices[idx+int(val)], j)
+				if !ok {
+					return
 				}
 			}
-			chIndices[task] <- struct{}{}
-			close(chIndices[task])
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var res G1Jac
+	res.Set(&g1Infinity)
+	for i := nbChunks - 1; i >= 0; i-- {
+		for j := uint(0); j < c; j++ {
+			res.DoubleAssign()
 		}
+		res.AddAssign(&windowSums[i])
 	}
+	p.Set(&res)
 
-	// if chunkSize=8, nbChunks=32 (the scalars are chunkSize*nbChunks bits long)
-	// for each chunk, sum up elements in index 0, add to current result, sum up elements
-	// in index 1, add to current result, etc, up to 255=2**8-1
-	accumulatePoints := func(cpuID, nbTasks, n int) {
-		for i := 0; i < nbTasks; i++ {
-			var tmp g1JacExtended
-			var _tmp G1Jac
-			task := cpuID + i*n
+	return nil
+}
 
-			// init points
-			tmp.SetInfinity()
-			accumulators[task].Set(&g1Infinity)
+// windowSum sets p to the sum of [k]bucket[k] for a window of the bucket method, digits being the signed digits
+// of the scalars in this window, with buckets in extended Jacobian coordinates.
+// It returns false if done is closed before the end of the computation
+func (p *G1Jac) windowSum(done <-chan struct{}, points []G1Affine, digits []uint16, buckets []g1JacExtended) bool {
 
-			// wait for indices to be ready
-			<-chIndices[task]
+	for k := range buckets {
+		buckets[k].SetInfinity()
+	}
+	var neg G1Affine
+	for j := range points {
+		if j%4096 == 0 {
+			select {
+			case <-done:
+				return false
+			default:
+			}
+		}
+		if points[j].IsInfinity() {
+			continue
+		}
+		if d := int(int16(digits[j])); d > 0 {
+			buckets[d-1].mAdd(&points[j])
+		} else if d < 0 {
+			neg.Neg(&points[j])
+			buckets[-d-1].mAdd(&neg)
+		}
+	}
 
-			for j := int(mask - 1); j >= 0; j-- {
-				for _, k := range indices[task*int(mask)+j] {
-					tmp.mAdd(&points[k])
-				}
-				tmp.ToJac(&_tmp)
-				accumulators[task].AddAssign(&_tmp)
+	var runningSum, sum g1JacExtended
+	runningSum.SetInfinity()
+	sum.SetInfinity()
+	for k := len(buckets) - 1; k >= 0; k-- {
+		if k%4096 == 0 {
+			select {
+			case <-done:
+				return false
+			default:
 			}
-			chPoints[task] <- struct{}{}
-			close(chPoints[task])
 		}
+		runningSum.add(&buckets[k])
+		sum.add(&runningSum)
 	}
+	sum.ToJac(p)
+
+	return true
+}
 
-	// double and add algo to collect all small reductions
-	reduce := func() {
-		var res G1Jac
-		res.Set(&g1Infinity)
-		for i := 0; i < nbChunks; i++ {
-			for j := 0; j < len(bitsForTask[i]); j++ {
-				res.DoubleAssign()
+// windowSumBatchAffine is windowSum with buckets in affine coordinates
+func (p *G1Jac) windowSumBatchAffine(done <-chan struct{}, points []G1Affine, digits []uint16, b *g1BatchAffineBuckets) bool {
+
+	b.reset()
+	for j := range points {
+		if j%4096 == 0 {
+			select {
+			case <-done:
+				return false
+			default:
 			}
-			<-chPoints[i]
-			res.AddAssign(&accumulators[i])
 		}
-		p.Set(&res)
-		chRes <- *p
-	}
-
-	nbCpus := runtime.NumCPU()
-	nbTasksPerCpus := nbChunks / nbCpus
-	remainingTasks := nbChunks % nbCpus
-	for i := 0; i < nbCpus; i++ {
-		if remainingTasks > 0 {
-			go accumulateIndices(i, nbTasksPerCpus+1, nbCpus)
-			go accumulatePoints(i, nbTasksPerCpus+1, nbCpus)
-			remainingTasks--
-		} else {
-			go accumulateIndices(i, nbTasksPerCpus, nbCpus)
-			go accumulatePoints(i, nbTasksPerCpus, nbCpus)
+		if d := int(int16(digits[j])); d > 0 {
+			b.add(d-1, &points[j], false)
+		} else if d < 0 {
+			b.add(-d-1, &points[j], true)
+		}
+	}
+	b.executeBatch()
+
+	var runningSum, sum g1JacExtended
+	runningSum.SetInfinity()
+	sum.SetInfinity()
+	for k := len(b.buckets) - 1; k >= 0; k-- {
+		if k%4096 == 0 {
+			select {
+			case <-done:
+				return false
+			default:
+			}
+		}
+		if !b.empty[k] {
+			runningSum.mAdd(&b.buckets[k])
+		}
+		runningSum.add(&b.overflow[k])
+		sum.add(&runningSum)
+	}
+	sum.ToJac(p)
+
+	return true
+}
+
+// g1BatchAffineBuckets buckets of the bucket method in affine coordinates.
+// The additions to the buckets are done by batches of additions to distinct buckets, sharing a single inversion
+// (Montgomery's trick): an addition then costs 6 multiplications and 1 square, against 8 multiplications and
+// 2 squares for a mixed addition in extended Jacobian coordinates. An addition to a bucket already in the batch,
+// or to a bucket with the same x coordinate (doubling or opposite), goes to the overflow bucket of the bucket,
+// in extended Jacobian coordinates
+type g1BatchAffineBuckets struct {
+	buckets  []G1Affine
+	empty    []bool
+	overflow []g1JacExtended
+	inBatch  []bool
+
+	// the batch of additions buckets[batchBuckets[i]] += batchPoints[i]
+	batchBuckets []int
+	batchPoints  []G1Affine
+	prefix       []fp.Element
+}
+
+// newG1BatchAffineBuckets returns nbBuckets empty buckets
+func newG1BatchAffineBuckets(nbBuckets int) *g1BatchAffineBuckets {
+
+	batchSize := batchAffineSize(nbBuckets)
+
+	return &g1BatchAffineBuckets{
+		buckets:      make([]G1Affine, nbBuckets),
+		empty:        make([]bool, nbBuckets),
+		overflow:     make([]g1JacExtended, nbBuckets),
+		inBatch:      make([]bool, nbBuckets),
+		batchBuckets: make([]int, 0, batchSize),
+		batchPoints:  make([]G1Affine, 0, batchSize),
+		prefix:       make([]fp.Element, batchSize),
+	}
+}
+
+// reset empties the buckets
+func (b *g1BatchAffineBuckets) reset() {
+	for k := range b.buckets {
+		b.empty[k] = true
+		b.overflow[k].SetInfinity()
+	}
+}
+
+// add adds a, or -a if neg, to the bucket k
+func (b *g1BatchAffineBuckets) add(k int, a *G1Affine, neg bool) {
+
+	if a.IsInfinity() {
+		return
+	}
+	var q G1Affine
+	if neg {
+		q.Neg(a)
+	} else {
+		q = *a
+	}
+
+	if b.empty[k] {
+		b.buckets[k] = q
+		b.empty[k] = false
+		return
+	}
+	if b.inBatch[k] || b.buckets[k].X.Equal(&q.X) {
+		b.overflow[k].mAdd(&q)
+		return
+	}
+
+	b.inBatch[k] = true
+	b.batchBuckets = append(b.batchBuckets, k)
+	b.batchPoints = append(b.batchPoints, q)
+	if len(b.batchBuckets) == cap(b.batchBuckets) {
+		b.executeBatch()
+	}
+}
+
+// executeBatch does the additions of the batch, then empties it
+func (b *g1BatchAffineBuckets) executeBatch() {
+
+	n := len(b.batchBuckets)
+	if n == 0 {
+		return
+	}
+
+	// prefix[i] = product of the denominators x(q)-x(r) of the additions 0 to i-1
+	var acc, d, inv, lambda, t fp.Element
+	acc.SetOne()
+	for i := 0; i < n; i++ {
+		b.prefix[i] = acc
+		d.Sub(&b.batchPoints[i].X, &b.buckets[b.batchBuckets[i]].X)
+		acc.Mul(&acc, &d)
+	}
+	inv.Inverse(&acc)
+
+	for i := n - 1; i >= 0; i-- {
+		r := &b.buckets[b.batchBuckets[i]]
+		q := &b.batchPoints[i]
+
+		// inv = 1/(d_0*...*d_i) -> lambda = (y(q)-y(r))/d_i, inv = 1/(d_0*...*d_(i-1))
+		d.Sub(&q.X, &r.X)
+		lambda.Mul(&b.prefix[i], &inv)
+		inv.Mul(&inv, &d)
+		t.Sub(&q.Y, &r.Y)
+		lambda.Mul(&lambda, &t)
+
+		// x = lambda**2 - x(r) - x(q), y = lambda*(x(r)-x) - y(r)
+		d.Square(&lambda).Sub(&d, &r.X).Sub(&d, &q.X)
+		t.Sub(&r.X, &d).Mul(&t, &lambda)
+		r.Y.Sub(&t, &r.Y)
+		r.X = d
+
+		b.inBatch[b.batchBuckets[i]] = false
+	}
+
+	b.batchBuckets = b.batchBuckets[:0]
+	b.batchPoints = b.batchPoints[:0]
+}
+
+// G1FixedBaseTable holds precomputed multiples of a long-lived base point b, for repeated scalar
+// multiplications of b (key generation, commitments...): table[i][j] = [(j+1)*2**(w*i)]b, in affine coordinates.
+// A scalar multiplication then costs about fr.Bits/w mixed additions and no doubling
+type G1FixedBaseTable struct {
+	w     uint
+	table [][]G1Affine
+}
+
+// NewG1FixedBaseTable builds the table of base, with 8-bit windows: it holds (fr.Bits/8+1)*128 points
+func NewG1FixedBaseTable(base *G1Affine) *G1FixedBaseTable {
+
+	const w = 8
+	const nbWindows = (fr.Bits+w-1)/w + 1
+	const windowSize = 1 << (w - 1)
+
+	jac := make([]G1Jac, nbWindows*windowSize)
+	var b G1Jac
+	b.FromAffine(base)
+	for i := 0; i < nbWindows; i++ {
+		row := jac[i*windowSize : (i+1)*windowSize]
+		row[0].Set(&b)
+		for j := 1; j < windowSize; j++ {
+			row[j].Set(&row[j-1]).AddAssign(&b)
+		}
+		b.Double(&row[windowSize-1])
+	}
+
+	t := &G1FixedBaseTable{w: w, table: make([][]G1Affine, nbWindows)}
+	aff := make([]G1Affine, len(jac))
+	batchJacobianToAffineG1(jac, aff)
+	for i := range t.table {
+		t.table[i] = aff[i*windowSize : (i+1)*windowSize]
+	}
+	return t
+}
+
+// ScalarMulFixedBase computes [s]b, b being the base point of table, and returns p
+// s is recoded in signed w-bit digits in [-2**(w-1), 2**(w-1)], so that each window adds one point of the table
+// or its opposite. It is not constant time
+func (p *G1Jac) ScalarMulFixedBase(table *G1FixedBaseTable, s *big.Int) *G1Jac {
+
+	var e fr.Element
+	e.SetBigInt(s)
+	k := e.ToRegular()
+
+	w := table.w
+	half := uint64(1) << (w - 1)
+
+	var res G1Jac
+	var neg G1Affine
+	res.Set(&g1Infinity)
+	var carry uint64
+	for i := range table.table {
+		d := utils.Window(k[:], uint(i)*w, w) + carry
+		carry = 0
+		if d > half {
+			// digit d - 2**w
+			carry = 1
+			if d = (1 << w) - d; d != 0 {
+				neg.Neg(&table.table[i][d-1])
+				res.AddMixed(&neg)
+			}
+		} else if d != 0 {
+			res.AddMixed(&table.table[i][d-1])
 		}
 	}
+	p.Set(&res)
+
+	return p
+}
 
-	go reduce()
+// g1GenTable fixed base table of the generator of G1, built on the first call to ScalarMulBase
+var g1GenTable *G1FixedBaseTable
+var g1GenTableOnce sync.Once
+
+// ScalarMulBase computes [s]g, g being the generator of G1, and returns p
+// It uses a G1FixedBaseTable of g, built on the first call
+func (p *G1Jac) ScalarMulBase(s *big.Int) *G1Jac {
+	g1GenTableOnce.Do(func() {
+		var gen G1Affine
+		gen.FromJacobian(&g1Gen)
+		g1GenTable = NewG1FixedBaseTable(&gen)
+	})
+	return p.ScalarMulFixedBase(g1GenTable, s)
+}
 
-	return chRes
+// batchJacobianToAffineG1 sets result[i] to points[i] in affine coordinates, with a single inversion
+// (Montgomery's trick)
+func batchJacobianToAffineG1(points []G1Jac, result []G1Affine) {
+
+	// zInv[i] = product of the non-zero Z[j], j < i
+	zInv := make([]fp.Element, len(points))
+	var acc fp.Element
+	acc.SetOne()
+	for i := range points {
+		if points[i].Z.IsZero() {
+			continue
+		}
+		zInv[i] = acc
+		acc.Mul(&acc, &points[i].Z)
+	}
+
+	acc.Inverse(&acc)
+	for i := len(points) - 1; i >= 0; i-- {
+		if points[i].Z.IsZero() {
+			result[i].X.SetZero()
+			result[i].Y.SetZero()
+			continue
+		}
+		// zInv[i] = 1/Z[i], acc = 1/(Z[0]*...*Z[i-1])
+		zInv[i].Mul(&zInv[i], &acc)
+		acc.Mul(&acc, &points[i].Z)
+
+		var zInv2 fp.Element
+		zInv2.Square(&zInv[i])
+		result[i].X.Mul(&points[i].X, &zInv2)
+		result[i].Y.Mul(&points[i].Y, &zInv2).Mul(&result[i].Y, &zInv[i])
+	}
 }
@@ -17,11 +17,14 @@
 package bls381
 
 import (
+	"context"
+	"crypto/subtle"
 	"math/big"
-	"runtime"
+	"sync"
+	"unsafe"
 
 	"github.com/consensys/gurvy/bls381/fr"
-	"github.com/consensys/gurvy/utils/debug"
+	"github.com/consensys/gurvy/utils"
 )
 
 // G2Jac is a point with E2 coordinates
@@ -39,7 +42,7 @@ type G2Affine struct {
 	X, Y E2
 }
 
-//  g2JacExtended parameterized jacobian coordinates (x=X/ZZ, y=Y/ZZZ, ZZ**3=ZZZ**2)
+// g2JacExtended parameterized jacobian coordinates (x=X/ZZ, y=Y/ZZZ, ZZ**3=ZZZ**2)
 type g2JacExtended struct {
 	X, Y, ZZ, ZZZ E2
 }
@@ -145,6 +148,76 @@ func (p *g2JacExtended) double(q *G2Affine) *g2JacExtended {
 	return p
 }
 
+// add sets p to p+q, in extended Jacobian coordinates
+// http://www.hyperelliptic.org/EFD/ g2p/auto-shortw-xyzz.html#addition-add-2008-s
+func (p *g2JacExtended) add(q *g2JacExtended) *g2JacExtended {
+
+	//if q is infinity return p
+	if q.ZZ.IsZero() {
+		return p
+	}
+	// p is infinity, return q
+	if p.ZZ.IsZero() {
+		*p = *q
+		return p
+	}
+
+	var U1, U2, S1, S2, P, R, PP, PPP, Q, V E2
+
+	// p2: q, p1: p
+	U1.Mul(&p.X, &q.ZZ)
+	U2.Mul(&q.X, &p.ZZ)
+	S1.Mul(&p.Y, &q.ZZZ)
+	S2.Mul(&q.Y, &p.ZZZ)
+	P.Sub(&U2, &U1)
+	R.Sub(&S2, &S1)
+	if P.IsZero() {
+		if R.IsZero() {
+			return p.doubleAssign()
+		}
+		// q = -p
+		return p.SetInfinity()
+	}
+	PP.Square(&P)
+	PPP.Mul(&P, &PP)
+	Q.Mul(&U1, &PP)
+	p.X.Square(&R).
+		Sub(&p.X, &PPP).
+		Sub(&p.X, &Q).
+		Sub(&p.X, &Q)
+	V.Sub(&Q, &p.X).Mul(&V, &R)
+	S1.Mul(&S1, &PPP)
+	p.Y.Sub(&V, &S1)
+	p.ZZ.Mul(&p.ZZ, &q.ZZ).Mul(&p.ZZ, &PP)
+	p.ZZZ.Mul(&p.ZZZ, &q.ZZZ).Mul(&p.ZZZ, &PPP)
+
+	return p
+}
+
+// doubleAssign sets p to 2p, in extended Jacobian coordinates
+// http://www.hyperelliptic.org/EFD/ g2p/auto-shortw-xyzz.html#doubling-dbl-2008-s-1
+func (p *g2JacExtended) doubleAssign() *g2JacExtended {
+
+	var U, V, W, S, M, XX E2
+
+	U.Double(&p.Y)
+	V.Square(&U)
+	W.Mul(&U, &V)
+	S.Mul(&p.X, &V)
+	XX.Square(&p.X)
+	M.Double(&XX).
+		Add(&M, &XX) // -> + a, but a=0 here
+	U.Mul(&W, &p.Y)
+	p.X.Square(&M).
+		Sub(&p.X, &S).
+		Sub(&p.X, &S)
+	p.Y.Sub(&S, &p.X).Mul(&p.Y, &M).Sub(&p.Y, &U)
+	p.ZZ.Mul(&p.ZZ, &V)
+	p.ZZZ.Mul(&p.ZZZ, &W)
+
+	return p
+}
+
 // Set set p to the provided point
 func (p *G2Jac) Set(a *G2Jac) *G2Jac {
 	p.X.Set(&a.X)
@@ -173,6 +246,13 @@ func (p *G2Affine) Equal(a *G2Affine) bool {
 	return p.X.Equal(&a.X) && p.Y.Equal(&a.Y)
 }
 
+// Set sets p to the provided point
+func (p *G2Affine) Set(a *G2Affine) *G2Affine {
+	p.X.Set(&a.X)
+	p.Y.Set(&a.Y)
+	return p
+}
+
 // Clone returns a copy of self
 func (p *G2Jac) Clone() *G2Jac {
 	return &G2Jac{
@@ -268,6 +348,106 @@ func (p *G2Affine) IsInfinity() bool {
 	return p.X.IsZero() && p.Y.IsZero()
 }
 
+// IsOnCurve returns true if p satisfies the curve equation
+// (the point at infinity is considered on the curve)
+func (p *G2Affine) IsOnCurve() bool {
+	if p.IsInfinity() {
+		return true
+	}
+	var left, right E2
+	left.Square(&p.Y)
+	right.Square(&p.X).Mul(&right, &p.X)
+	right.Add(&right, &bTwistCurveCoeff)
+	return left.Equal(&right)
+}
+
+// IsOnCurve returns true if p satisfies the curve equation Y**2 = X**3 + b*Z**6
+// (the point at infinity is considered on the curve)
+func (p *G2Jac) IsOnCurve() bool {
+	var left, right, tmp E2
+	left.Square(&p.Y)
+	right.Square(&p.X).Mul(&right, &p.X)
+	tmp.Square(&p.Z).
+		Mul(&tmp, &p.Z).
+		Square(&tmp)
+	tmp.Mul(&tmp, &bTwistCurveCoeff)
+	right.Add(&right, &tmp)
+	return left.Equal(&right)
+}
+
+// IsInSubGroup returns true if p is on the r-torsion subgroup
+func (p *G2Affine) IsInSubGroup() bool {
+	var _p G2Jac
+	_p.FromAffine(p)
+	return _p.IsInSubGroup()
+}
+
+// IsInSubGroup returns true if p is on the r-torsion subgroup
+// It checks that psi(p) = [x]p, x being the seed of the curve
+// cf https://eprint.iacr.org/2021/1130.pdf
+func (p *G2Jac) IsInSubGroup() bool {
+	var res, psip G2Jac
+	psip.psi(p)
+	res.mulWindowed(p, &xGen)
+	return p.IsOnCurve() && res.Equal(&psip)
+}
+
+// ClearCofactor maps a point in E'(Fp2) to the r-torsion subgroup
+func (p *G2Affine) ClearCofactor(a *G2Affine) *G2Affine {
+	var _p G2Jac
+	_p.FromAffine(a)
+	_p.ClearCofactor(&_p)
+	p.FromJacobian(&_p)
+	return p
+}
+
+// ClearCofactor maps a point in E'(Fp2) to the r-torsion subgroup
+// It computes [x**2-x-1]a + [x-1]psi(a) + psi**2([2]a) (Budroni-Pintore)
+// cf https://eprint.iacr.org/2017/419.pdf
+func (p *G2Jac) ClearCofactor(a *G2Jac) *G2Jac {
+	var xa, psia, psi2a, res G2Jac
+
+	psia.psi(a)
+	psi2a.Double(a).psi(&psi2a).psi(&psi2a)
+
+	// [x]([x]a - a + psi(a)) = [x**2-x]a + [x]psi(a)
+	xa.mulWindowed(a, &xGen).SubAssign(*a)
+	xa.AddAssign(&psia)
+	res.mulWindowed(&xa, &xGen)
+
+	// - a - psi(a) + psi**2([2]a)
+	res.SubAssign(*a)
+	res.SubAssign(psia)
+	res.AddAssign(&psi2a)
+
+	p.Set(&res)
+	return p
+}
+
+// psi sets p to psi(a), where psi = u o Frobenius o u**-1, u being the isomorphism from the twist to E,
+// and returns p
+func (p *G2Jac) psi(a *G2Jac) *G2Jac {
+	p.Set(a)
+	p.X.Conjugate(&p.X).Mul(&p.X, &psiFactorX)
+	p.Y.Conjugate(&p.Y).Mul(&p.Y, &psiFactorY)
+	p.Z.Conjugate(&p.Z)
+	return p
+}
+
+// psi sets p to psi(a) (cf G2Jac.psi), and returns p
+func (p *G2Affine) psi(a *G2Affine) *G2Affine {
+	p.X.Conjugate(&a.X).Mul(&p.X, &psiFactorX)
+	p.Y.Conjugate(&a.Y).Mul(&p.Y, &psiFactorY)
+	return p
+}
+
+// phi sets p to phi(a), where phi: (x,y) -> (thirdRootOneG2*x, y), and returns p
+func (p *G2Jac) phi(a *G2Jac) *G2Jac {
+	p.Set(a)
+	p.X.MulByElement(&p.X, &thirdRootOneG2)
+	return p
+}
+
 // AddAssign point addition in montgomery form
 // https://hyperelliptic.org/EFD/g2p/auto-shortw-jacobian-3.html#addition-add-2007-bl
 func (p *G2Jac) AddAssign(a *G2Jac) *G2Jac {
@@ -434,216 +614,767 @@ func (p *G2Jac) ScalarMultiplication(a *G2Affine, s *big.Int) *G2Jac {
 	return p
 }
 
-// ScalarMulGLV performs scalar multiplication using GLV (without the lattice reduction)
-func (p *G2Jac) ScalarMulGLV(a *G2Affine, s *big.Int) *G2Jac {
+// mulWindowed 2-bits windowed exponentiation, s may be negative
+func (p *G2Jac) mulWindowed(a *G2Jac, s *big.Int) *G2Jac {
+
+	var res G2Jac
+	var ops [3]G2Jac
 
-	var g2, phig2, res G2Jac
-	var phig2Affine G2Affine
 	res.Set(&g2Infinity)
-	g2.FromAffine(a)
-	phig2.Set(&g2)
-	phig2.X.MulByElement(&phig2.X, &thirdRootOneG2)
+	ops[0].Set(a)
+	if s.Sign() == -1 {
+		ops[0].Neg(&ops[0])
+	}
+	ops[1].Double(&ops[0])
+	ops[2].Set(&ops[0]).AddAssign(&ops[1])
+
+	b := s.Bytes()
+	for i := range b {
+		w := b[i]
+		mask := byte(0xc0)
+		for j := 0; j < 4; j++ {
+			res.DoubleAssign().DoubleAssign()
+			c := (w & mask) >> (6 - 2*j)
+			if c != 0 {
+				res.AddAssign(&ops[c-1])
+			}
+			mask = mask >> 2
+		}
+	}
+	p.Set(&res)
 
-	phig2Affine.FromJacobian(&phig2)
+	return p
+}
+
+// ScalarMulGLV performs scalar multiplication using GLV
+// s is split as s = k0 + k1*lambdaGLV mod r with |k0|, |k1| ~ sqrt(r) using the reduced basis glvBasis,
+// then [k0]a + [k1]phi(a) is computed in a single loop from the interleaved width-w NAFs of k0 and k1
+func (p *G2Jac) ScalarMulGLV(a *G2Affine, s *big.Int) *G2Jac {
 
-	// s = s1*lambda+s2
-	var s1, s2 big.Int
-	s1.DivMod(s, &lambdaGLV, &s2)
+	const w = 5
 
-	chTasks := []chan struct{}{
-		make(chan struct{}),
-		make(chan struct{}),
+	var k [2]big.Int
+	var _s big.Int
+	_s.Mod(s, fr.Modulus())
+	utils.SplitScalar(&_s, &glvBasis, &k)
+
+	// table[0][i] = [2i+1]a, table[1][i] = [2i+1]phi(a)
+	var table [2][1 << (w - 2)]G2Jac
+	table[0][0].FromAffine(a)
+	table[0][0].oddMultiples(table[0][:])
+	for i := range table[1] {
+		table[1][i].phi(&table[0][i])
 	}
 
-	// s1 part (on phi(g2)=lambda*g2)
-	go func() {
-		phig2.ScalarMultiplication(&phig2Affine, &s1)
-		chTasks[0] <- struct{}{}
-	}()
+	return p.mulWnaf(w, k[:], [][]G2Jac{table[0][:], table[1][:]})
+}
 
-	// s2 part (on g2)
-	go func() {
-		g2.ScalarMultiplication(a, &s2)
-		chTasks[1] <- struct{}{}
-	}()
+// DoubleScalarMul computes [s1]a + [s2]b and returns p
+// Both scalar multiplications share a single doubling loop, see multiScalarMul
+func (p *G2Jac) DoubleScalarMul(a, b *G2Affine, s1, s2 *big.Int) *G2Jac {
+	points := [2]G2Affine{*a, *b}
+	var scalars [2]big.Int
+	scalars[0].Set(s1)
+	scalars[1].Set(s2)
+	return p.multiScalarMul(points[:], scalars[:])
+}
 
-	<-chTasks[0]
-	res.AddAssign(&phig2)
-	<-chTasks[1]
-	res.AddAssign(&g2)
+// multiScalarMul sets p to [scalars[0]]points[0] + .. + [scalars[n-1]]points[n-1] with Straus' method, and returns p
+// Each scalar is split with GLV, then the 2n width-w NAFs are interleaved in a single loop (see mulWnaf), so that
+// the n scalar multiplications share about fr.Bits/2 doublings. Meant for small n, the tables holding 2**(w-1)*n points
+func (p *G2Jac) multiScalarMul(points []G2Affine, scalars []big.Int) *G2Jac {
+
+	const w = 5
+	const tableSize = 1 << (w - 2)
+
+	r := fr.Modulus()
+	k := make([]big.Int, 2*len(points))
+	tables := make([][]G2Jac, 2*len(points))
+	buf := make([]G2Jac, 2*len(points)*tableSize)
+	var s big.Int
+	var ki [2]big.Int
+	for i := range points {
+		s.Mod(&scalars[i], r)
+		utils.SplitScalar(&s, &glvBasis, &ki)
+		k[2*i].Set(&ki[0])
+		k[2*i+1].Set(&ki[1])
+
+		// tables[2i][j] = [2j+1]points[i], tables[2i+1][j] = [2j+1]phi(points[i])
+		tables[2*i] = buf[2*i*tableSize : (2*i+1)*tableSize]
+		tables[2*i+1] = buf[(2*i+1)*tableSize : (2*i+2)*tableSize]
+		tables[2*i][0].FromAffine(&points[i])
+		tables[2*i][0].oddMultiples(tables[2*i])
+		for j := range tables[2*i+1] {
+			tables[2*i+1][j].phi(&tables[2*i][j])
+		}
+	}
+
+	return p.mulWnaf(w, k, tables)
+}
+
+// ScalarMulGLS performs scalar multiplication using both the GLS endomorphism psi and the GLV endomorphism phi
+// s is split as s = k0 + k1*mu + k2*lambdaGLV + k3*mu*lambdaGLV mod r with |ki| ~ r**(1/4) using the reduced
+// basis glsBasis, mu being the eigenvalue of psi on G2, then [k0]a + [k1]psi(a) + [k2]phi(a) + [k3]phi(psi(a))
+// is computed in a single loop from the interleaved width-w NAFs of the ki
+func (p *G2Jac) ScalarMulGLS(a *G2Affine, s *big.Int) *G2Jac {
+
+	const w = 5
+
+	var k [4]big.Int
+	var _s big.Int
+	r := fr.Modulus()
+	_s.Mod(s, r)
+	utils.SplitScalar4(&_s, r, &glsBasis, &glsBabai, &k)
+
+	// table[0][i] = [2i+1]a, table[1][i] = [2i+1]psi(a), table[2][i] = [2i+1]phi(a), table[3][i] = [2i+1]phi(psi(a))
+	var table [4][1 << (w - 2)]G2Jac
+	table[0][0].FromAffine(a)
+	table[0][0].oddMultiples(table[0][:])
+	for i := range table[1] {
+		table[1][i].psi(&table[0][i])
+		table[2][i].phi(&table[0][i])
+		table[3][i].phi(&table[1][i])
+	}
+
+	return p.mulWnaf(w, k[:], [][]G2Jac{table[0][:], table[1][:], table[2][:], table[3][:]})
+}
 
+// ScalarMulCT performs scalar multiplication in constant time, for secret scalars
+// s is made odd (s or r-s, the result being negated back in the latter case), then recoded with the regular
+// signed window method (Joye-Tunstall) in ceil(fr.Bits/w) odd digits in (-2**w, 2**w), so that the sequence
+// of doublings and additions is fixed. The table lookups and the negations use conditional moves.
+// The additions only branch on their exceptional cases (p = ±a), which a handful of scalars close to r reach
+func (p *G2Jac) ScalarMulCT(a *G2Affine, s *fr.Element) *G2Jac {
+
+	const w = 4
+	const nbDigits = (fr.Bits + w - 1) / w
+
+	// k = s or r-s, whichever is odd
+	var sNeg fr.Element
+	sNeg.Neg(s)
+	k, kNeg := s.ToRegular(), sNeg.ToRegular()
+	isZero := utils.IsZero(k[:])
+	even := (k[0] & 1) ^ 1
+	utils.CondCopy(k[:], kNeg[:], even)
+
+	// table[i] = [2i+1]a
+	var table [1 << (w - 1)]G2Jac
+	table[0].FromAffine(a)
+	table[0].oddMultiples(table[:])
+
+	// the most significant digit is positive, the other ones are 2*window+1-2**w
+	var res, tmp G2Jac
+	res.lookupCT(table[:], utils.Window(k[:], w*(nbDigits-1)+1, w))
+	for i := nbDigits - 2; i >= 0; i-- {
+		for j := 0; j < w; j++ {
+			res.DoubleAssign()
+		}
+		d := 2*utils.Window(k[:], uint(w*i+1), w) + 1 - 1<<w
+		neg := d >> 63
+		abs := (d ^ -neg) + neg
+		tmp.lookupCT(table[:], abs>>1)
+		tmp.condNeg(neg)
+		res.AddAssign(&tmp)
+	}
+	res.condNeg(even)
+	res.cmov(&g2Infinity, isZero)
 	p.Set(&res)
 
 	return p
 }
 
-// MultiExp complexity O(n)
-func (p *G2Jac) MultiExp(points []G2Affine, scalars []fr.Element) chan G2Jac {
+// lookupCT sets p to table[idx] by scanning the whole table, and returns p
+func (p *G2Jac) lookupCT(table []G2Jac, idx uint64) *G2Jac {
+	for i := range table {
+		p.cmov(&table[i], uint64(subtle.ConstantTimeEq(int32(i), int32(idx))))
+	}
+	return p
+}
 
-	nbPoints := len(points)
-	debug.Assert(nbPoints == len(scalars))
+// condNeg sets p to -p if cond is 1 and leaves it unchanged if cond is 0, and returns p
+func (p *G2Jac) condNeg(cond uint64) *G2Jac {
+	var neg G2Jac
+	neg.Neg(p)
+	return p.cmov(&neg, cond)
+}
+
+// cmov sets p to a if cond is 1 and leaves it unchanged if cond is 0, in constant time, and returns p
+func (p *G2Jac) cmov(a *G2Jac, cond uint64) *G2Jac {
+	utils.CondCopy(p.X.A0[:], a.X.A0[:], cond)
+	utils.CondCopy(p.X.A1[:], a.X.A1[:], cond)
+	utils.CondCopy(p.Y.A0[:], a.Y.A0[:], cond)
+	utils.CondCopy(p.Y.A1[:], a.Y.A1[:], cond)
+	utils.CondCopy(p.Z.A0[:], a.Z.A0[:], cond)
+	utils.CondCopy(p.Z.A1[:], a.Z.A1[:], cond)
+	return p
+}
+
+// oddMultiples sets table[i] to [2i+1]p
+func (p *G2Jac) oddMultiples(table []G2Jac) {
+	var double G2Jac
+	double.Double(p)
+	table[0].Set(p)
+	for i := 1; i < len(table); i++ {
+		table[i].Set(&table[i-1]).AddAssign(&double)
+	}
+}
+
+// mulWnaf sets p to [k[0]]b_0 + .. + [k[n-1]]b_(n-1), tables[j] holding the odd multiples [1]b_j, [3]b_j, ..,
+// [2**(w-1)-1]b_j, and returns p. It runs a single loop over the interleaved width-w NAFs of the k[j],
+// which may be negative
+func (p *G2Jac) mulWnaf(w uint, k []big.Int, tables [][]G2Jac) *G2Jac {
+
+	digits := make([][fr.Limbs*64 + 1]int8, len(k))
+	var abs big.Int
+	n := 0
+	for j := range k {
+		abs.Abs(&k[j])
+		l := utils.WnafDecomposition(&abs, w, digits[j][:])
+		if k[j].Sign() < 0 {
+			for i := 0; i < l; i++ {
+				digits[j][i] = -digits[j][i]
+			}
+		}
+		if l > n {
+			n = l
+		}
+	}
+
+	var res, tmp G2Jac
+	res.Set(&g2Infinity)
+	for i := n - 1; i >= 0; i-- {
+		res.DoubleAssign()
+		for j := range tables {
+			if d := digits[j][i]; d > 0 {
+				res.AddAssign(&tables[j][d>>1])
+			} else if d < 0 {
+				tmp.Neg(&tables[j][(-d)>>1])
+				res.AddAssign(&tmp)
+			}
+		}
+	}
+	p.Set(&res)
+
+	return p
+}
+
+// MultiExp sets p to [scalars[0]]points[0] + .. + [scalars[n-1]]points[n-1], the scalars being in regular form
+// (not Montgomery) and reduced mod r, and returns p. opts may be nil, see MultiExpOptions.
+// On error (invalid inputs or options, or ctx done before the end of the computation, in which case ctx.Err() is
+// returned), p is left unchanged
+func (p *G2Jac) MultiExp(ctx context.Context, points []G2Affine, scalars []fr.Element, opts *MultiExpOptions) (G2Jac, error) {
 
-	chRes := make(chan G2Jac, 1)
+	nbPoints := len(points)
+	if nbPoints != len(scalars) {
+		return *p, ErrMultiExpInputSize
+	}
+	if opts == nil {
+		opts = &MultiExpOptions{}
+	}
+	if opts.C == 1 || opts.C > MaxMultiExpWindowSize {
+		return *p, ErrMultiExpWindowSize
+	}
+	if err := ctx.Err(); err != nil {
+		return *p, err
+	}
+	nbTasks := opts.nbTasks()
 
-	// under 50 points, the windowed multi exp performs better
-	const minPoints = 50
-	if nbPoints <= minPoints {
-		var tmp G2Jac
-		var s big.Int
-		p.Set(&g2Infinity)
+	// for a few points, Straus' method performs better than the bucket method. It runs on a single core, so the
+	// crossover (empirical, ~200 points on one core) goes down as the bucket method gets more cores
+	if opts.C == 0 && nbPoints <= 200/nbTasks {
+		_scalars := make([]big.Int, nbPoints)
 		for i := 0; i < nbPoints; i++ {
-			scalars[i].ToBigInt(&s)
-			tmp.ScalarMulGLV(&points[i], &s)
-			p.AddAssign(&tmp)
+			scalars[i].ToBigInt(&_scalars[i])
 		}
+		p.multiScalarMul(points, _scalars)
+		return *p, nil
 	}
 
-	// empirical values
-	var nbChunks, chunkSize int
-	var mask uint64
-	if nbPoints <= 10000 {
-		chunkSize = 8
-	} else if nbPoints <= 80000 {
-		chunkSize = 11
-	} else if nbPoints <= 400000 {
-		chunkSize = 13
-	} else if nbPoints <= 800000 {
-		chunkSize = 14
-	} else {
-		chunkSize = 16
+	c := opts.windowSize(nbPoints)
+
+	// each task holds 2**(c-1) buckets, and each point its signed digits (2 bytes each)
+	batchSize := nbPoints
+	if opts.MaxMemory > 0 {
+		bucketsMemory := multiExpBucketsMemoryG2(c, nbTasks)
+		if bucketsMemory >= opts.MaxMemory {
+			return *p, ErrMultiExpMemory
+		}
+		max := (opts.MaxMemory - bucketsMemory) / (multiExpNbChunks(c) * 2)
+		if max < 1 {
+			return *p, ErrMultiExpMemory
+		}
+		if max < batchSize {
+			batchSize = max
+		}
+	}
+
+	var res, tmp G2Jac
+	res.Set(&g2Infinity)
+	for start := 0; start < nbPoints; start += batchSize {
+		end := start + batchSize
+		if end > nbPoints {
+			end = nbPoints
+		}
+		if err := tmp.multiExpBuckets(ctx, points[start:end], scalars[start:end], c, nbTasks); err != nil {
+			return *p, err
+		}
+		res.AddAssign(&tmp)
 	}
+	p.Set(&res)
 
-	const sizeScalar = fr.Limbs * 64
+	return *p, nil
+}
 
-	var bitsForTask [][]int
-	if sizeScalar%chunkSize == 0 {
-		counter := sizeScalar - 1
-		nbChunks = sizeScalar / chunkSize
-		bitsForTask = make([][]int, nbChunks)
-		for i := 0; i < nbChunks; i++ {
-			bitsForTask[i] = make([]int, chunkSize)
-			for j := 0; j < chunkSize; j++ {
-				bitsForTask[i][j] = counter
-				counter--
-			}
+// multiExpBucketsMemoryG2 returns the memory (in bytes) allocated by the buckets of the nbTasks tasks
+// of the bucket method with c-bit windows: the buckets in extended Jacobian coordinates, or the buckets and the
+// batch of g2BatchAffineBuckets
+func multiExpBucketsMemoryG2(c uint, nbTasks int) int {
+	nbBuckets := 1 << (c - 1)
+	if c < batchAffineMinWindowSize {
+		return nbTasks * nbBuckets * int(unsafe.Sizeof(g2JacExtended{}))
+	}
+
+	// buckets, overflow, empty and inBatch
+	bucketSize := int(unsafe.Sizeof(G2Affine{})+unsafe.Sizeof(g2JacExtended{})) + 2
+
+	// batchBuckets, batchPoints and prefix
+	var coord E2
+	batchSize := int(unsafe.Sizeof(int(0)) + unsafe.Sizeof(G2Affine{}) + unsafe.Sizeof(coord))
+
+	return nbTasks * (nbBuckets*bucketSize + batchAffineSize(nbBuckets)*batchSize)
+}
+
+// multiExpBuckets sets p to [scalars[0]]points[0] + .. + [scalars[n-1]]points[n-1] with the bucket method
+// (Pippenger), and returns ctx.Err() if ctx is done before the end of the computation.
+// The scalars are recoded in signed c-bit digits in [-2**(c-1), 2**(c-1)), so that a window only needs 2**(c-1)
+// buckets, a point being added to the bucket |digit| or its opposite to the bucket -digit. The window sum,
+// sum of [k]bucket[k], is computed by a running sum of the buckets from the top one.
+// From batchAffineMinWindowSize bits, the buckets are in affine coordinates (see g2BatchAffineBuckets).
+// The windows are spread over nbTasks goroutines, then the window sums are combined by double and add
+func (p *G2Jac) multiExpBuckets(ctx context.Context, points []G2Affine, scalars []fr.Element, c uint, nbTasks int) error {
+
+	nbPoints := len(points)
+	nbChunks := multiExpNbChunks(c)
+	nbBuckets := 1 << (c - 1)
+	done := ctx.Done()
+
+	// digits[i*nbPoints+j] is the i-th signed digit of scalars[j], as an int16
+	digits := make([]uint16, nbChunks*nbPoints)
+	var wg sync.WaitGroup
+	batchSize := (nbPoints + nbTasks - 1) / nbTasks
+	for start := 0; start < nbPoints; start += batchSize {
+		end := start + batchSize
+		if end > nbPoints {
+			end = nbPoints
 		}
-	} else {
-		counter := sizeScalar - 1
-		nbChunks = sizeScalar/chunkSize + 1
-		bitsForTask = make([][]int, nbChunks)
-		for i := 0; i < nbChunks; i++ {
-			if i < nbChunks-1 {
-				bitsForTask[i] = make([]int, chunkSize)
-			} else {
-				bitsForTask[i] = make([]int, sizeScalar%chunkSize)
-			}
-			for j := 0; j < chunkSize && counter >= 0; j++ {
-				bitsForTask[i][j] = counter
-				counter--
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			half := uint64(nbBuckets)
+			for j := start; j < end; j++ {
+				if j%4096 == 0 {
+					select {
+					case <-done:
+						return
+					default:
+					}
+				}
+				var carry uint64
+				for i := 0; i < nbChunks; i++ {
+					d := utils.Window(scalars[j][:], uint(i)*c, c) + carry
+					carry = 0
+					if d >= half {
+						// digit d - 2**c, its 16 low bits being its two's complement
+						carry = 1
+						d -= 1 << c
+					}
+					digits[i*nbPoints+j] = uint16(d)
+				}
 			}
-		}
+		}(start, end)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	accumulators := make([]G2Jac, nbChunks)
-	chIndices := make([]chan struct{}, nbChunks)
-	chPoints := make([]chan struct{}, nbChunks)
+	// windowSums[i] = sum of [k]bucket[k] for the i-th window
+	windowSums := make([]G2Jac, nbChunks)
+	chunks := make(chan int, nbChunks)
 	for i := 0; i < nbChunks; i++ {
-		chIndices[i] = make(chan struct{}, 1)
-		chPoints[i] = make(chan struct{}, 1)
-	}
-
-	mask = (1 << chunkSize) - 1
-	nbPointsPerSlots := nbPoints / int(mask)
-	// [][] is more efficient than [][][] for storage, elements are accessed via i*nbChunks+k
-	indices := make([][]int, int(mask)*nbChunks)
-	for i := 0; i < int(mask)*nbChunks; i++ {
-		indices[i] = make([]int, 0, nbPointsPerSlots)
-	}
-
-	// if chunkSize=8, nbChunks=32 (the scalars are chunkSize*nbChunks bits long)
-	// for each 32 chunk, there is a list of 2**8=256 list of indices
-	// for the i-th chunk, accumulateIndices stores in the k-th list all the indices of points
-	// for which the i-th chunk of 8 bits is equal to k
-	accumulateIndices := func(cpuID, nbTasks, n int) {
-		for i := 0; i < nbTasks; i++ {
-			task := cpuID + i*n
-			idx := task*int(mask) - 1
-			for j := 0; j < nbPoints; j++ {
-				val := 0
-				for k := 0; k < len(bitsForTask[task]); k++ {
-					val = val << 1
-					c := bitsForTask[task][k] / int(64)
-					o := bitsForTask[task][k] % int(64)
-					b := (scalars[j][c] >> o) & 1
-					val += int(b)
+		chunks <- i
+	}
+	close(chunks)
+
+	if nbTasks > nbChunks {
+		nbTasks = nbChunks
+	}
+	wg.Add(nbTasks)
+	for t := 0; t < nbTasks; t++ {
+		go func() {
+			defer wg.Done()
+
+			// the buckets are reused from one window to the next
+			var batchAffineBuckets *g2BatchAffineBuckets
+			var buckets []g2JacExtended
+			if c >= batchAffineMinWindowSize {
+				batchAffineBuckets = newG2BatchAffineBuckets(nbBuckets)
+			} else {
+				buckets = make([]g2JacExtended, nbBuckets)
+			}
+
+			for chunk := range chunks {
+				chunkDigits := digits[chunk*nbPoints : (chunk+1)*nbPoints]
+				var ok bool
+				if batchAffineBuckets != nil {
+					ok = windowSums[chunk].windowSumBatchAffine(done, points, chunkDigits, batchAffineBuckets)
+				} else {
+					ok = windowSums[chunk].windowSum(done, points, chunkDigits, buckets)
 				}
-				if val != 0 {
-					indices[idx+int(val)] = append(indices[idx+int(val)], j)
+				if !ok {
+					return
 				}
 			}
-			chIndices[task] <- struct{}{}
-			close(chIndices[task])
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var res G2Jac
+	res.Set(&g2Infinity)
+	for i := nbChunks - 1; i >= 0; i-- {
+		for j := uint(0); j < c; j++ {
+			res.DoubleAssign()
 		}
+		res.AddAssign(&windowSums[i])
 	}
+	p.Set(&res)
 
-	// if chunkSize=8, nbChunks=32 (the scalars are chunkSize*nbChunks bits long)
-	// for each chunk, sum up elements in index 0, add to current result, sum up elements
-	// in index 1, add to current result, etc, up to 255=2**8-1
-	accumulatePoints := func(cpuID, nbTasks, n int) {
-		for i := 0; i < nbTasks; i++ {
-			var tmp g2JacExtended
-			var _tmp G2Jac
-			task := cpuID + i*n
+	return nil
+}
 
-			// init points
-			tmp.SetInfinity()
-			accumulators[task].Set(&g2Infinity)
+// windowSum sets p to the sum of [k]bucket[k] for a window of the bucket method, digits being the signed digits
+// of the scalars in this window, with buckets in extended Jacobian coordinates.
+// It returns false if done is closed before the end of the computation
+func (p *G2Jac) windowSum(done <-chan struct{}, points []G2Affine, digits []uint16, buckets []g2JacExtended) bool {
 
-			// wait for indices to be ready
-			<-chIndices[task]
+	for k := range buckets {
+		buckets[k].SetInfinity()
+	}
+	var neg G2Affine
+	for j := range points {
+		if j%4096 == 0 {
+			select {
+			case <-done:
+				return false
+			default:
+			}
+		}
+		if points[j].IsInfinity() {
+			continue
+		}
+		if d := int(int16(digits[j])); d > 0 {
+			buckets[d-1].mAdd(&points[j])
+		} else if d < 0 {
+			neg.Neg(&points[j])
+			buckets[-d-1].mAdd(&neg)
+		}
+	}
 
-			for j := int(mask - 1); j >= 0; j-- {
-				for _, k := range indices[task*int(mask)+j] {
-					tmp.mAdd(&points[k])
-				}
-				tmp.ToJac(&_tmp)
-				accumulators[task].AddAssign(&_tmp)
+	var runningSum, sum g2JacExtended
+	runningSum.SetInfinity()
+	sum.SetInfinity()
+	for k := len(buckets) - 1; k >= 0; k-- {
+		if k%4096 == 0 {
+			select {
+			case <-done:
+				return false
+			default:
 			}
-			chPoints[task] <- struct{}{}
-			close(chPoints[task])
 		}
+		runningSum.add(&buckets[k])
+		sum.add(&runningSum)
 	}
+	sum.ToJac(p)
+
+	return true
+}
+
+// windowSumBatchAffine is windowSum with buckets in affine coordinates
+func (p *G2Jac) windowSumBatchAffine(done <-chan struct{}, points []G2Affine, digits []uint16, b *g2BatchAffineBuckets) bool {
 
-	// double and add algo to collect all small reductions
-	reduce := func() {
-		var res G2Jac
-		res.Set(&g2Infinity)
-		for i := 0; i < nbChunks; i++ {
-			for j := 0; j < len(bitsForTask[i]); j++ {
-				res.DoubleAssign()
+	b.reset()
+	for j := range points {
+		if j%4096 == 0 {
+			select {
+			case <-done:
+				return false
+			default:
 			}
-			<-chPoints[i]
-			res.AddAssign(&accumulators[i])
 		}
-		p.Set(&res)
-		chRes <- *p
-	}
-
-	nbCpus := runtime.NumCPU()
-	nbTasksPerCpus := nbChunks / nbCpus
-	remainingTasks := nbChunks % nbCpus
-	for i := 0; i < nbCpus; i++ {
-		if remainingTasks > 0 {
-			go accumulateIndices(i, nbTasksPerCpus+1, nbCpus)
-			go accumulatePoints(i, nbTasksPerCpus+1, nbCpus)
-			remainingTasks--
-		} else {
-			go accumulateIndices(i, nbTasksPerCpus, nbCpus)
-			go accumulatePoints(i, nbTasksPerCpus, nbCpus)
+		if d := int(int16(digits[j])); d > 0 {
+			b.add(d-1, &points[j], false)
+		} else if d < 0 {
+			b.add(-d-1, &points[j], true)
 		}
 	}
+	b.executeBatch()
+
+	var runningSum, sum g2JacExtended
+	runningSum.SetInfinity()
+	sum.SetInfinity()
+	for k := len(b.buckets) - 1; k >= 0; k-- {
+		if k%4096 == 0 {
+			select {
+			case <-done:
+				return false
+			default:
+			}
+		}
+		if !b.empty[k] {
+			runningSum.mAdd(&b.buckets[k])
+		}
+		runningSum.add(&b.overflow[k])
+		sum.add(&runningSum)
+	}
+	sum.ToJac(p)
+
+	return true
+}
+
+// g2BatchAffineBuckets buckets of the bucket method in affine coordinates.
+// The additions to the buckets are done by batches of additions to distinct buckets, sharing a single inversion
+// (Montgomery's trick): an addition then costs 6 multiplications and 1 square, against 8 multiplications and
+// 2 squares for a mixed addition in extended Jacobian coordinates. An addition to a bucket already in the batch,
+// or to a bucket with the same x coordinate (doubling or opposite), goes to the overflow bucket of the bucket,
+// in extended Jacobian coordinates
+type g2BatchAffineBuckets struct {
+	buckets  []G2Affine
+	empty    []bool
+	overflow []g2JacExtended
+	inBatch  []bool
+
+	// the batch of additions buckets[batchBuckets[i]] += batchPoints[i]
+	batchBuckets []int
+	batchPoints  []G2Affine
+	prefix       []E2
+}
+
+// newG2BatchAffineBuckets returns nbBuckets empty buckets
+func newG2BatchAffineBuckets(nbBuckets int) *g2BatchAffineBuckets {
+
+	batchSize := batchAffineSize(nbBuckets)
+
+	return &g2BatchAffineBuckets{
+		buckets:      make([]G2Affine, nbBuckets),
+		empty:        make([]bool, nbBuckets),
+		overflow:     make([]g2JacExtended, nbBuckets),
+		inBatch:      make([]bool, nbBuckets),
+		batchBuckets: make([]int, 0, batchSize),
+		batchPoints:  make([]G2Affine, 0, batchSize),
+		prefix:       make([]E2, batchSize),
+	}
+}
+
+// reset empties the buckets
+func (b *g2BatchAffineBuckets) reset() {
+	for k := range b.buckets {
+		b.empty[k] = true
+		b.overflow[k].SetInfinity()
+	}
+}
+
+// add adds a, or -a if neg, to the bucket k
+func (b *g2BatchAffineBuckets) add(k int, a *G2Affine, neg bool) {
+
+	if a.IsInfinity() {
+		return
+	}
+	var q G2Affine
+	if neg {
+		q.Neg(a)
+	} else {
+		q = *a
+	}
+
+	if b.empty[k] {
+		b.buckets[k] = q
+		b.empty[k] = false
+		return
+	}
+	if b.inBatch[k] || b.buckets[k].X.Equal(&q.X) {
+		b.overflow[k].mAdd(&q)
+		return
+	}
 
-	go reduce()
+	b.inBatch[k] = true
+	b.batchBuckets = append(b.batchBuckets, k)
+	b.batchPoints = append(b.batchPoints, q)
+	if len(b.batchBuckets) == cap(b.batchBuckets) {
+		b.executeBatch()
+	}
+}
 
-	return chRes
+// executeBatch does the additions of the batch, then empties it
+func (b *g2BatchAffineBuckets) executeBatch() {
+
+	n := len(b.batchBuckets)
+	if n == 0 {
+		return
+	}
+
+	// prefix[i] = product of the denominators x(q)-x(r) of the additions 0 to i-1
+	var acc, d, inv, lambda, t E2
+	acc.SetOne()
+	for i := 0; i < n; i++ {
+		b.prefix[i] = acc
+		d.Sub(&b.batchPoints[i].X, &b.buckets[b.batchBuckets[i]].X)
+		acc.Mul(&acc, &d)
+	}
+	inv.Inverse(&acc)
+
+	for i := n - 1; i >= 0; i-- {
+		r := &b.buckets[b.batchBuckets[i]]
+		q := &b.batchPoints[i]
+
+		// inv = 1/(d_0*...*d_i) -> lambda = (y(q)-y(r))/d_i, inv = 1/(d_0*...*d_(i-1))
+		d.Sub(&q.X, &r.X)
+		lambda.Mul(&b.prefix[i], &inv)
+		inv.Mul(&inv, &d)
+		t.Sub(&q.Y, &r.Y)
+		lambda.Mul(&lambda, &t)
+
+		// x = lambda**2 - x(r) - x(q), y = lambda*(x(r)-x) - y(r)
+		d.Square(&lambda).Sub(&d, &r.X).Sub(&d, &q.X)
+		t.Sub(&r.X, &d).Mul(&t, &lambda)
+		r.Y.Sub(&t, &r.Y)
+		r.X = d
+
+		b.inBatch[b.batchBuckets[i]] = false
+	}
+
+	b.batchBuckets = b.batchBuckets[:0]
+	b.batchPoints = b.batchPoints[:0]
+}
+
+// G2FixedBaseTable holds precomputed multiples of a long-lived base point b, for repeated scalar
+// multiplications of b (key generation, commitments...): table[i][j] = [(j+1)*2**(w*i)]b, in affine coordinates.
+// A scalar multiplication then costs about fr.Bits/w mixed additions and no doubling
+type G2FixedBaseTable struct {
+	w     uint
+	table [][]G2Affine
+}
+
+// NewG2FixedBaseTable builds the table of base, with 8-bit windows: it holds (fr.Bits/8+1)*128 points
+func NewG2FixedBaseTable(base *G2Affine) *G2FixedBaseTable {
+
+	const w = 8
+	const nbWindows = (fr.Bits+w-1)/w + 1
+	const windowSize = 1 << (w - 1)
+
+	jac := make([]G2Jac, nbWindows*windowSize)
+	var b G2Jac
+	b.FromAffine(base)
+	for i := 0; i < nbWindows; i++ {
+		row := jac[i*windowSize : (i+1)*windowSize]
+		row[0].Set(&b)
+		for j := 1; j < windowSize; j++ {
+			row[j].Set(&row[j-1]).AddAssign(&b)
+		}
+		b.Double(&row[windowSize-1])
+	}
+
+	t := &G2FixedBaseTable{w: w, table: make([][]G2Affine, nbWindows)}
+	aff := make([]G2Affine, len(jac))
+	batchJacobianToAffineG2(jac, aff)
+	for i := range t.table {
+		t.table[i] = aff[i*windowSize : (i+1)*windowSize]
+	}
+	return t
+}
+
+// ScalarMulFixedBase computes [s]b, b being the base point of table, and returns p
+// s is recoded in signed w-bit digits in [-2**(w-1), 2**(w-1)], so that each window adds one point of the table
+// or its opposite. It is not constant time
+func (p *G2Jac) ScalarMulFixedBase(table *G2FixedBaseTable, s *big.Int) *G2Jac {
+
+	var e fr.Element
+	e.SetBigInt(s)
+	k := e.ToRegular()
+
+	w := table.w
+	half := uint64(1) << (w - 1)
+
+	var res G2Jac
+	var neg G2Affine
+	res.Set(&g2Infinity)
+	var carry uint64
+	for i := range table.table {
+		d := utils.Window(k[:], uint(i)*w, w) + carry
+		carry = 0
+		if d > half {
+			// digit d - 2**w
+			carry = 1
+			if d = (1 << w) - d; d != 0 {
+				neg.Neg(&table.table[i][d-1])
+				res.AddMixed(&neg)
+			}
+		} else if d != 0 {
+			res.AddMixed(&table.table[i][d-1])
+		}
+	}
+	p.Set(&res)
+
+	return p
+}
+
+// g2GenTable fixed base table of the generator of G2, built on the first call to ScalarMulBase
+var g2GenTable *G2FixedBaseTable
+var g2GenTableOnce sync.Once
+
+// ScalarMulBase computes [s]g, g being the generator of G2, and returns p
+// It uses a G2FixedBaseTable of g, built on the first call
+func (p *G2Jac) ScalarMulBase(s *big.Int) *G2Jac {
+	g2GenTableOnce.Do(func() {
+		var gen G2Affine
+		gen.FromJacobian(&g2Gen)
+		g2GenTable = NewG2FixedBaseTable(&gen)
+	})
+	return p.ScalarMulFixedBase(g2GenTable, s)
+}
+
+// batchJacobianToAffineG2 sets result[i] to points[i] in affine coordinates, with a single inversion
+// (Montgomery's trick)
+func batchJacobianToAffineG2(points []G2Jac, result []G2Affine) {
+
+	// zInv[i] = product of the non-zero Z[j], j < i
+	zInv := make([]E2, len(points))
+	var acc E2
+	acc.SetOne()
+	for i := range points {
+		if points[i].Z.IsZero() {
+			continue
+		}
+		zInv[i] = acc
+		acc.Mul(&acc, &points[i].Z)
+	}
+
+	acc.Inverse(&acc)
+	for i := len(points) - 1; i >= 0; i-- {
+		if points[i].Z.IsZero() {
+			result[i].X.SetZero()
+			result[i].Y.SetZero()
+			continue
+		}
+		// zInv[i] = 1/Z[i], acc = 1/(Z[0]*...*Z[i-1])
+		zInv[i].Mul(&zInv[i], &acc)
+		acc.Mul(&acc, &points[i].Z)
+
+		var zInv2 E2
+		zInv2.Square(&zInv[i])
+		result[i].X.Mul(&points[i].X, &zInv2)
+		result[i].Y.Mul(&points[i].Y, &zInv2).Mul(&result[i].Y, &zInv[i])
+	}
 }
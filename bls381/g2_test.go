@@ -17,11 +17,18 @@
 package bls381
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"math/big"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/consensys/gurvy/bls381/fr"
+	"github.com/consensys/gurvy/internal/timing"
+	"github.com/consensys/gurvy/utils"
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/prop"
 )
@@ -56,6 +63,23 @@ func fuzzExtendedJacobianG2(p *g2JacExtended, f *E2) g2JacExtended {
 	return res
 }
 
+// randomOnCurveG2 returns a point on the curve with abscissa x or above,
+// which with overwhelming probability is not in the r-torsion subgroup
+func randomOnCurveG2(x *E2) G2Affine {
+	var p G2Affine
+	var rhs, one E2
+	one.SetOne()
+	p.X.Set(x)
+	for {
+		rhs.Square(&p.X).Mul(&rhs, &p.X)
+		rhs.Add(&rhs, &bTwistCurveCoeff)
+		if p.Y.Sqrt(&rhs) != nil {
+			return p
+		}
+		p.X.Add(&p.X, &one)
+	}
+}
+
 // ------------------------------------------------------------
 // tests
 
@@ -178,6 +202,142 @@ func TestG2Conversions(t *testing.T) {
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
+func TestG2IsOnCurve(t *testing.T) {
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+	genFuzz1 := GenE2()
+
+	properties.Property("[Jacobian] a valid point should be on the curve", prop.ForAll(
+		func(a *E2) bool {
+			op1 := fuzzJacobianG2(&g2Gen, a)
+			return op1.IsOnCurve() && g2Infinity.IsOnCurve()
+		},
+		genFuzz1,
+	))
+
+	properties.Property("[Jacobian] an invalid point should not be on the curve", prop.ForAll(
+		func(a *E2) bool {
+			op1 := fuzzJacobianG2(&g2Gen, a)
+			op1.Y.Double(&op1.Y)
+			return !op1.IsOnCurve()
+		},
+		genFuzz1,
+	))
+
+	properties.Property("[Affine] a valid point should be on the curve", prop.ForAll(
+		func(a *E2) bool {
+			op1 := randomOnCurveG2(a)
+			var op2 G2Affine
+			op2.Y.Double(&op1.Y)
+			op2.X.Set(&op1.X)
+			return op1.IsOnCurve() && !op2.IsOnCurve()
+		},
+		genFuzz1,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestG2IsInSubGroup(t *testing.T) {
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 10
+
+	properties := gopter.NewProperties(parameters)
+	genFuzz1 := GenE2()
+	genFuzz2 := GenE2()
+
+	genScalar := GenFr()
+
+	properties.Property("[Jacobian] multiples of the generator should be in the subgroup", prop.ForAll(
+		func(s fr.Element, a *E2) bool {
+			var scalar big.Int
+			var gaff, op2 G2Affine
+			var op1 G2Jac
+			s.ToBigIntRegular(&scalar)
+			gaff.FromJacobian(&g2Gen)
+			op1.ScalarMultiplication(&gaff, &scalar)
+			op2.FromJacobian(&op1)
+			op1 = fuzzJacobianG2(&op1, a)
+			return op1.IsInSubGroup() && op2.IsInSubGroup() && g2Infinity.IsInSubGroup()
+		},
+		genScalar,
+		genFuzz1,
+	))
+
+	properties.Property("[Jacobian] a point not on the curve should not be in the subgroup", prop.ForAll(
+		func(a *E2) bool {
+			op1 := fuzzJacobianG2(&g2Gen, a)
+			op1.Y.Double(&op1.Y)
+			return !op1.IsInSubGroup()
+		},
+		genFuzz1,
+	))
+
+	properties.Property("[Affine] a random point on the curve should not be in the subgroup", prop.ForAll(
+		func(a *E2) bool {
+			op1 := randomOnCurveG2(a)
+			return op1.IsOnCurve() && !op1.IsInSubGroup()
+		},
+		genFuzz1,
+	))
+
+	properties.Property("[Affine] a random point on the curve, multiplied by r, should not be the point at infinity", prop.ForAll(
+		func(a *E2) bool {
+			op1 := randomOnCurveG2(a)
+			var op2 G2Jac
+			op2.ScalarMultiplication(&op1, fr.Modulus())
+			return !op2.Z.IsZero()
+		},
+		genFuzz1,
+	))
+
+	properties.Property("[Affine] clearing the cofactor of a random point should output a point in the subgroup", prop.ForAll(
+		func(a *E2) bool {
+			op1 := randomOnCurveG2(a)
+			var op2 G2Affine
+			op2.ClearCofactor(&op1)
+			return !op2.IsInfinity() && op2.IsInSubGroup()
+		},
+		genFuzz1,
+	))
+
+	properties.Property("[Jacobian] clearing the cofactor should not depend on the representative", prop.ForAll(
+		func(a, b *E2) bool {
+			op1 := randomOnCurveG2(a)
+			var op2, op3 G2Jac
+			op2.FromAffine(&op1)
+			op3 = fuzzJacobianG2(&op2, b)
+			op2.ClearCofactor(&op2)
+			op3.ClearCofactor(&op3)
+			return op2.Equal(&op3) && op3.IsInSubGroup()
+		},
+		genFuzz1,
+		genFuzz2,
+	))
+
+	properties.Property("[Jacobian] mulWindowed should be consistent with double and add, for positive and negative scalars", prop.ForAll(
+		func(s fr.Element) bool {
+			var scalar, negScalar big.Int
+			var gaff G2Affine
+			var op1, op2, op3 G2Jac
+			s.ToBigIntRegular(&scalar)
+			negScalar.Neg(&scalar)
+			gaff.FromJacobian(&g2Gen)
+			op1.ScalarMultiplication(&gaff, &scalar)
+			op2.mulWindowed(&g2Gen, &scalar)
+			op3.mulWindowed(&g2Gen, &negScalar).Neg(&op3)
+			return op1.Equal(&op2) && op1.Equal(&op3)
+		},
+		genScalar,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
 func TestG2Ops(t *testing.T) {
 
 	parameters := gopter.DefaultTestParameters()
@@ -304,6 +464,229 @@ func TestG2Ops(t *testing.T) {
 		genScalar,
 	))
 
+	properties.Property("DoubleScalarMul should match the sum of two GLV scalar multiplications", prop.ForAll(
+		func(s1, s2 fr.Element) bool {
+
+			var r1, r2, negR1 big.Int
+			var op1, op2, op3, op4 G2Jac
+			var a, b G2Affine
+			s1.ToBigIntRegular(&r1)
+			s2.ToBigIntRegular(&r2)
+			negR1.Neg(&r1)
+			a.FromJacobian(&g2Gen)
+			op1.ScalarMulGLV(&a, &r2)
+			b.FromJacobian(&op1)
+			op1.ScalarMulGLV(&a, &r1)
+			op2.ScalarMulGLV(&b, &r2)
+			op1.AddAssign(&op2)
+			op3.DoubleScalarMul(&a, &b, &r1, &r2)
+			op4.DoubleScalarMul(&a, &a, &r1, &negR1)
+
+			return op1.Equal(&op3) && op4.Equal(&g2Infinity)
+
+		},
+		genScalar,
+		genScalar,
+	))
+
+	properties.Property("Straus multi scalar multiplication should match the sum of GLV scalar multiplications, including infinity", prop.ForAll(
+		func(mixer fr.Element) bool {
+
+			const nbPoints = 10
+			var g, expected, tmp G2Jac
+			var res G2Jac
+			points := make([]G2Affine, nbPoints)
+			scalars := make([]big.Int, nbPoints)
+			g.Set(&g2Gen)
+			expected.Set(&g2Infinity)
+			for i := 0; i < nbPoints; i++ {
+				var s fr.Element
+				s.SetUint64(uint64(i + 1)).MulAssign(&mixer).ToBigIntRegular(&scalars[i])
+				if i == 3 {
+					// infinity
+					points[i].X.SetZero()
+					points[i].Y.SetZero()
+				} else {
+					points[i].FromJacobian(&g)
+				}
+				tmp.ScalarMulGLV(&points[i], &scalars[i])
+				expected.AddAssign(&tmp)
+				g.AddAssign(&g2Gen)
+			}
+			res.multiScalarMul(points, scalars)
+
+			return res.Equal(&expected)
+		},
+		genScalar,
+	))
+
+	properties.Property("ScalarMulCT and Double and Add should output the same result", prop.ForAll(
+		func(s fr.Element) bool {
+
+			var r big.Int
+			var op1, op2 G2Jac
+			var gaff G2Affine
+			s.ToBigIntRegular(&r)
+			gaff.FromJacobian(&g2Gen)
+			op1.ScalarMultiplication(&gaff, &r)
+			op2.ScalarMulCT(&gaff, &s)
+			return op1.Equal(&op2) && !op1.Equal(&g2Infinity)
+
+		},
+		genScalar,
+	))
+
+	properties.Property("ScalarMulCT should handle the scalars 0, 1, -1 and -s", prop.ForAll(
+		func(s fr.Element) bool {
+
+			var zero, one, minusOne, negS fr.Element
+			var op1, op2, op3, op4, negG, negSG G2Jac
+			var gaff G2Affine
+			gaff.FromJacobian(&g2Gen)
+			one.SetOne()
+			minusOne.Neg(&one)
+			negS.Neg(&s)
+			op1.ScalarMulCT(&gaff, &zero)
+			op2.ScalarMulCT(&gaff, &one)
+			op3.ScalarMulCT(&gaff, &minusOne)
+			op4.ScalarMulCT(&gaff, &negS)
+			negG.Neg(&g2Gen)
+			negSG.ScalarMulCT(&gaff, &s).Neg(&negSG)
+
+			return op1.Equal(&g2Infinity) && op2.Equal(&g2Gen) && op3.Equal(&negG) && op4.Equal(&negSG)
+
+		},
+		genScalar,
+	))
+
+	properties.Property("ScalarMulBase and Double and Add should output the same result", prop.ForAll(
+		func(s fr.Element) bool {
+
+			var r big.Int
+			var op1, op2 G2Jac
+			var gaff G2Affine
+			s.ToBigIntRegular(&r)
+			gaff.FromJacobian(&g2Gen)
+			op1.ScalarMultiplication(&gaff, &r)
+			op2.ScalarMulBase(&r)
+			return op1.Equal(&op2) && !op1.Equal(&g2Infinity)
+
+		},
+		genScalar,
+	))
+
+	// a table for a base point other than the generator, built once for all the runs
+	var base G2Jac
+	var baseAff G2Affine
+	base.mulWindowed(&g2Gen, big.NewInt(1789))
+	baseAff.FromJacobian(&base)
+	baseTable := NewG2FixedBaseTable(&baseAff)
+
+	properties.Property("ScalarMulFixedBase should handle the scalars 0, s, s+r and -s", prop.ForAll(
+		func(s fr.Element) bool {
+
+			var r, sPlusR, negS big.Int
+			var op1, op2, op3, op4, op5 G2Jac
+			s.ToBigIntRegular(&r)
+			sPlusR.Add(&r, fr.Modulus())
+			negS.Neg(&r)
+			op1.ScalarMulFixedBase(baseTable, big.NewInt(0))
+			op2.ScalarMultiplication(&baseAff, &r)
+			op3.ScalarMulFixedBase(baseTable, &r)
+			op4.ScalarMulFixedBase(baseTable, &sPlusR)
+			op5.ScalarMulFixedBase(baseTable, &negS).Neg(&op5)
+
+			return op1.Equal(&g2Infinity) && op2.Equal(&op3) && op3.Equal(&op4) && op3.Equal(&op5)
+
+		},
+		genScalar,
+	))
+
+	properties.Property("batch conversion to affine coordinates should match FromJacobian, including infinity", prop.ForAll(
+		func(s fr.Element) bool {
+
+			var r big.Int
+			points := make([]G2Jac, 4)
+			s.ToBigIntRegular(&r)
+			points[0].mulWindowed(&g2Gen, &r)
+			points[1].Set(&g2Infinity)
+			points[2].Set(&g2Gen)
+			points[3].Double(&points[0])
+			result := make([]G2Affine, len(points))
+			batchJacobianToAffineG2(points, result)
+			for i := range points {
+				var expected G2Affine
+				expected.FromJacobian(&points[i])
+				if !expected.Equal(&result[i]) {
+					return false
+				}
+			}
+			return true
+
+		},
+		genScalar,
+	))
+
+	properties.Property("GLS and Double and Add should output the same result", prop.ForAll(
+		func(s fr.Element) bool {
+
+			var r big.Int
+			var op1, op2 G2Jac
+			var gaff G2Affine
+			s.ToBigIntRegular(&r)
+			gaff.FromJacobian(&g2Gen)
+			op1.ScalarMultiplication(&gaff, &r)
+			op2.ScalarMulGLS(&gaff, &r)
+			return op1.Equal(&op2) && !op1.Equal(&g2Infinity)
+
+		},
+		genScalar,
+	))
+
+	properties.Property("scalar multiplication (GLS) should depend only on the scalar mod r", prop.ForAll(
+		func(s fr.Element) bool {
+
+			r := fr.Modulus()
+			var g G2Jac
+			var gaff G2Affine
+			gaff.FromJacobian(&g2Gen)
+			g.ScalarMulGLS(&gaff, r)
+
+			var scalar, blindedScalar, negScalar big.Int
+			var op1, op2, op3, neg G2Jac
+			s.ToBigIntRegular(&scalar)
+			blindedScalar.Add(&scalar, r)
+			negScalar.Neg(&scalar)
+			op1.ScalarMulGLS(&gaff, &scalar)
+			op2.ScalarMulGLS(&gaff, &blindedScalar)
+			op3.ScalarMulGLS(&gaff, &negScalar)
+			neg.Neg(&op1)
+
+			return op1.Equal(&op2) && g.Equal(&g2Infinity) && neg.Equal(&op3)
+
+		},
+		genScalar,
+	))
+
+	properties.Property("psi on affine and jacobian coordinates should match", prop.ForAll(
+		func(s fr.Element) bool {
+
+			var r big.Int
+			var a, psia G2Jac
+			var gaff, aaff, psiaaff, res G2Affine
+			s.ToBigIntRegular(&r)
+			gaff.FromJacobian(&g2Gen)
+			a.ScalarMultiplication(&gaff, &r)
+			aaff.FromJacobian(&a)
+			psia.psi(&a)
+			psiaaff.psi(&aaff)
+			res.FromJacobian(&psia)
+			return res.Equal(&psiaaff) && psiaaff.IsOnCurve()
+
+		},
+		genScalar,
+	))
+
 	properties.Property("Multi exponentation (>50points) should be consistant with sum of square", prop.ForAll(
 		func(mixer fr.Element) bool {
 
@@ -323,7 +706,9 @@ func TestG2Ops(t *testing.T) {
 			}
 
 			var op1MultiExp G2Jac
-			<-op1MultiExp.MultiExp(samplePoints, sampleScalars)
+			if _, err := op1MultiExp.MultiExp(context.Background(), samplePoints, sampleScalars, nil); err != nil {
+				return false
+			}
 
 			var finalBigScalar fr.Element
 			var finalBigScalarBi big.Int
@@ -358,7 +743,9 @@ func TestG2Ops(t *testing.T) {
 			}
 
 			var op1MultiExp G2Jac
-			<-op1MultiExp.MultiExp(samplePoints, sampleScalars)
+			if _, err := op1MultiExp.MultiExp(context.Background(), samplePoints, sampleScalars, nil); err != nil {
+				return false
+			}
 
 			var finalBigScalar fr.Element
 			var finalBigScalarBi big.Int
@@ -377,6 +764,277 @@ func TestG2Ops(t *testing.T) {
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
+func TestG2MultiExp(t *testing.T) {
+
+	// scalars in regular form, so that every window of the bucket method is used
+	const nbPoints = 1000
+	points := make([]G2Affine, nbPoints)
+	scalars := make([]fr.Element, nbPoints)
+	var g, expected, tmp G2Jac
+	var s big.Int
+	g.Set(&g2Gen)
+	expected.Set(&g2Infinity)
+	for i := 0; i < nbPoints; i++ {
+		scalars[i].SetRandom()
+		scalars[i].ToBigIntRegular(&s)
+		scalars[i].FromMont()
+		points[i].FromJacobian(&g)
+		tmp.mulWindowed(&g, &s)
+		expected.AddAssign(&tmp)
+		g.AddAssign(&g2Gen)
+	}
+
+	for _, opts := range []*MultiExpOptions{
+		nil,
+		{NbTasks: 1},
+		{NbTasks: 3, C: 5},
+		{C: 2},
+		{C: 11},
+		{NbTasks: 2, C: 4, MaxMemory: 20000}, // batches of less than nbPoints points
+	} {
+		var res G2Jac
+		r, err := res.MultiExp(context.Background(), points, scalars, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !r.Equal(&expected) || !res.Equal(&expected) {
+			t.Fatalf("MultiExp with options %+v should match the sum of the scalar multiplications", opts)
+		}
+	}
+
+	var res G2Jac
+	res.Set(&g2Gen)
+	if _, err := res.MultiExp(context.Background(), points, scalars[1:], nil); err != ErrMultiExpInputSize {
+		t.Fatal("MultiExp should fail when points and scalars have different lengths")
+	}
+	if _, err := res.MultiExp(context.Background(), points, scalars, &MultiExpOptions{C: MaxMultiExpWindowSize + 1}); err != ErrMultiExpWindowSize {
+		t.Fatal("MultiExp should fail when the window size is too large")
+	}
+	if _, err := res.MultiExp(context.Background(), points, scalars, &MultiExpOptions{C: 1}); err != ErrMultiExpWindowSize {
+		t.Fatal("MultiExp should fail when the window size is 1")
+	}
+	if _, err := res.MultiExp(context.Background(), points, scalars, &MultiExpOptions{C: 8, MaxMemory: 1000}); err != ErrMultiExpMemory {
+		t.Fatal("MultiExp should fail when the memory budget is too small for the buckets")
+	}
+	maxMemory := multiExpBucketsMemoryG2(8, 1) + 10
+	if _, err := res.MultiExp(context.Background(), points, scalars, &MultiExpOptions{NbTasks: 1, C: 8, MaxMemory: maxMemory}); err != ErrMultiExpMemory {
+		t.Fatal("MultiExp should fail when the memory budget is too small for a single point")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := res.MultiExp(ctx, points, scalars, nil); err != context.Canceled {
+		t.Fatal("MultiExp should return the error of a cancelled context")
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := res.MultiExp(ctx, points, scalars, &MultiExpOptions{C: MaxMultiExpWindowSize}); err != context.DeadlineExceeded {
+		t.Fatal("MultiExp should stop when the context deadline is exceeded")
+	}
+	if !res.Equal(&g2Gen) {
+		t.Fatal("MultiExp should leave p unchanged on error")
+	}
+}
+
+func TestG2MultiExpBuckets(t *testing.T) {
+
+	// random points and scalars, then the same points, their opposites and infinity with the same scalars,
+	// for the doublings and the cancellations in the buckets
+	const n = 500
+	points := make([]G2Affine, 3*n+10)
+	scalars := make([]fr.Element, len(points))
+	var g G2Jac
+	var s big.Int
+	for i := 0; i < n; i++ {
+		scalars[i].SetRandom()
+		scalars[i].ToBigIntRegular(&s)
+		g.mulWindowed(&g2Gen, &s)
+		points[i].FromJacobian(&g)
+		scalars[i].SetRandom().FromMont()
+	}
+	scalars[0].SetZero()
+	scalars[1].SetOne().FromMont()
+	scalars[2].SetOne().Neg(&scalars[2]).FromMont()
+	for i := 0; i < n; i++ {
+		points[n+i] = points[i]
+		points[2*n+i].Neg(&points[i])
+		scalars[n+i] = scalars[i]
+		scalars[2*n+i] = scalars[i]
+	}
+	for i := 3 * n; i < len(points); i++ {
+		scalars[i].SetRandom().FromMont()
+	}
+
+	var expected G2Jac
+	if err := expected.multiExpReference(context.Background(), points[:n], scalars[:n], 8, 2); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []uint{2, 5, 9, batchAffineMinWindowSize, 13} {
+		var res G2Jac
+		if err := res.multiExpBuckets(context.Background(), points, scalars, c, 2); err != nil {
+			t.Fatal(err)
+		}
+		if !res.Equal(&expected) {
+			t.Fatalf("the bucket method with c=%d should match the reference implementation", c)
+		}
+	}
+}
+
+func TestG2MultiExpBucketsMemory(t *testing.T) {
+
+	// the estimate should cover the allocations of the buckets, up to the rounding of the large slices to pages
+	const c = 12
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	b := newG2BatchAffineBuckets(1 << (c - 1))
+	runtime.ReadMemStats(&after)
+	allocated := int(after.TotalAlloc - before.TotalAlloc)
+	estimate := multiExpBucketsMemoryG2(c, 1)
+	if allocated > estimate+4*8192 {
+		t.Fatalf("the buckets allocate %d bytes, estimated to %d", allocated, estimate)
+	}
+	runtime.KeepAlive(b)
+}
+
+// multiExpReference is the previous implementation of multiExpBuckets, for tests.
+// The scalars are cut in unsigned c-bit windows. For each window, the points are put in the bucket of their c-bit digit
+// and the window sum, sum of [k]bucket[k], is computed by a running sum of the buckets from the top one.
+// The windows are spread over nbTasks goroutines, then the window sums are combined by double and add
+func (p *G2Jac) multiExpReference(ctx context.Context, points []G2Affine, scalars []fr.Element, c uint, nbTasks int) error {
+
+	const sizeScalar = fr.Limbs * 64
+	nbChunks := (sizeScalar + int(c) - 1) / int(c)
+	nbBuckets := (1 << c) - 1
+
+	// windowSums[i] = sum of [k]bucket[k] for the i-th window (bits i*c to (i+1)*c-1 of the scalars)
+	windowSums := make([]G2Jac, nbChunks)
+	chunks := make(chan int, nbChunks)
+	for i := 0; i < nbChunks; i++ {
+		chunks <- i
+	}
+	close(chunks)
+
+	if nbTasks > nbChunks {
+		nbTasks = nbChunks
+	}
+	done := ctx.Done()
+	var wg sync.WaitGroup
+	wg.Add(nbTasks)
+	for t := 0; t < nbTasks; t++ {
+		go func() {
+			defer wg.Done()
+
+			// indices[k-1] holds the indices of the points in the bucket k, reused from one window to the next
+			indices := make([][]int, nbBuckets)
+			for k := range indices {
+				indices[k] = make([]int, 0, len(points)/nbBuckets)
+			}
+			var sum g2JacExtended
+			var _sum G2Jac
+
+			for chunk := range chunks {
+				for k := range indices {
+					indices[k] = indices[k][:0]
+				}
+				for j := range scalars {
+					if j%4096 == 0 {
+						select {
+						case <-done:
+							return
+						default:
+						}
+					}
+					if k := utils.Window(scalars[j][:], uint(chunk)*c, c); k != 0 {
+						indices[k-1] = append(indices[k-1], j)
+					}
+				}
+
+				sum.SetInfinity()
+				windowSums[chunk].Set(&g2Infinity)
+				for k := nbBuckets - 1; k >= 0; k-- {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					for _, j := range indices[k] {
+						sum.mAdd(&points[j])
+					}
+					sum.ToJac(&_sum)
+					windowSums[chunk].AddAssign(&_sum)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var res G2Jac
+	res.Set(&g2Infinity)
+	for i := nbChunks - 1; i >= 0; i-- {
+		for j := uint(0); j < c; j++ {
+			res.DoubleAssign()
+		}
+		res.AddAssign(&windowSums[i])
+	}
+	p.Set(&res)
+
+	return nil
+}
+
+func TestG2ScalarMulCTTiming(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the timing test in short mode")
+	}
+
+	const nbMeasures = 1000
+
+	// fix-vs-random: class 0 multiplies by 1, class 1 by a random scalar
+	var gaff G2Affine
+	var res G2Jac
+	gaff.FromJacobian(&g2Gen)
+	var one fr.Element
+	var oneBi big.Int
+	one.SetOne()
+	oneBi.SetUint64(1)
+	scalars := make([]fr.Element, nbMeasures+2)
+	scalarsBi := make([]big.Int, nbMeasures+2)
+	for i := range scalars {
+		scalars[i].SetRandom()
+		scalars[i].ToBigIntRegular(&scalarsBi[i])
+	}
+
+	// the double and add leaks the scalar: this checks that the harness detects it
+	i := 0
+	tDoubleAndAdd := timing.WelchT(nbMeasures, func(class int) {
+		s := &oneBi
+		if class == 1 {
+			s = &scalarsBi[i]
+		}
+		i++
+		res.ScalarMultiplication(&gaff, s)
+	})
+	if math.Abs(tDoubleAndAdd) < timing.Threshold {
+		t.Fatalf("the timings of ScalarMultiplication should depend on the scalar (t = %.2f)", tDoubleAndAdd)
+	}
+
+	i = 0
+	tCT := timing.WelchT(nbMeasures, func(class int) {
+		s := &one
+		if class == 1 {
+			s = &scalars[i]
+		}
+		i++
+		res.ScalarMulCT(&gaff, s)
+	})
+	t.Logf("t statistic: %.2f (ScalarMultiplication), %.2f (ScalarMulCT)", tDoubleAndAdd, tCT)
+	if math.Abs(tCT) > timing.Threshold {
+		t.Fatalf("the timings of ScalarMulCT should not depend on the scalar (t = %.2f)", tCT)
+	}
+}
+
 // ------------------------------------------------------------
 // benches
 
@@ -393,6 +1051,80 @@ func BenchmarkG2GLV(b *testing.B) {
 
 }
 
+func BenchmarkG2GLS(b *testing.B) {
+	var g G2Affine
+	g.FromJacobian(&g2Gen)
+	var op1 G2Jac
+	var s big.Int
+	s.SetString("5243587517512619047944770508185965837690552500527637822603658699938581184513", 10)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op1.ScalarMulGLS(&g, &s)
+	}
+
+}
+
+func BenchmarkG2DoubleScalarMul(b *testing.B) {
+	var g, h G2Affine
+	var op1 G2Jac
+	g.FromJacobian(&g2Gen)
+	op1.Double(&g2Gen)
+	h.FromJacobian(&op1)
+	var s1, s2 big.Int
+	s1.SetString("5243587517512619047944770508185965837690552500527637822603658699938581184513", 10)
+	s2.SetString("3125497852613451235648975256548713254861329745612354865423165432165487956321", 10)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op1.DoubleScalarMul(&g, &h, &s1, &s2)
+	}
+
+}
+func BenchmarkG2ScalarMulCT(b *testing.B) {
+	var g G2Affine
+	g.FromJacobian(&g2Gen)
+	var op1 G2Jac
+	var s fr.Element
+	s.SetString("5243587517512619047944770508185965837690552500527637822603658699938581184513")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op1.ScalarMulCT(&g, &s)
+	}
+
+}
+
+func BenchmarkG2ScalarMulBase(b *testing.B) {
+	var op1 G2Jac
+	var s big.Int
+	s.SetString("5243587517512619047944770508185965837690552500527637822603658699938581184513", 10)
+	// the first call builds the precomputed table
+	op1.ScalarMulBase(&s)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op1.ScalarMulBase(&s)
+	}
+
+}
+func BenchmarkG2IsInSubGroup(b *testing.B) {
+	var a G2Jac
+	a.Set(&g2Gen)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.IsInSubGroup()
+	}
+}
+
+func BenchmarkG2ClearCofactor(b *testing.B) {
+	var x E2
+	x.SetRandom()
+	a := randomOnCurveG2(&x)
+	var op1, op2 G2Jac
+	op1.FromAffine(&a)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op2.ClearCofactor(&op1)
+	}
+}
+
 func BenchmarkG2DoubleAndAdd(b *testing.B) {
 
 	var g G2Affine
@@ -443,35 +1175,33 @@ func BenchmarkG2G2Double(b *testing.B) {
 
 func BenchmarkG2MultiExpG2(b *testing.B) {
 
-	var G G2Jac
-
-	// ensure every words of the scalars are filled
-	var mixer fr.Element
-	mixer.SetString("7716837800905789770901243404444209691916730933998574719964609384059111546487")
-
-	var nbSamples int
-	nbSamples = 800000
-
-	samplePoints := make([]G2Affine, nbSamples)
-	sampleScalars := make([]fr.Element, nbSamples)
-
-	G.Set(&g2Gen)
-
-	for i := 1; i <= nbSamples; i++ {
-		sampleScalars[i-1].SetUint64(uint64(i)).
-			Mul(&sampleScalars[i-1], &mixer).
-			FromMont()
-		samplePoints[i-1].FromJacobian(&G)
+	// distinct points and random scalars in regular form, as in a proof system
+	const pow = 22
+	samplePoints := make([]G2Affine, 1<<pow)
+	sampleScalars := make([]fr.Element, 1<<pow)
+
+	var g G2Jac
+	g.Set(&g2Gen)
+	jac := make([]G2Jac, 1<<10)
+	for i := 0; i < len(samplePoints); i += len(jac) {
+		for j := range jac {
+			jac[j].Set(&g)
+			g.AddAssign(&g2Gen)
+		}
+		batchJacobianToAffineG2(jac, samplePoints[i:i+len(jac)])
+	}
+	for i := range sampleScalars {
+		sampleScalars[i].SetRandom().FromMont()
 	}
 
 	var testPoint G2Jac
 
-	for i := 0; i < 16; i++ {
-
-		b.Run(fmt.Sprintf("%d points)", (i+1)*50000), func(b *testing.B) {
+	for i := 10; i <= pow; i++ {
+		nbPoints := 1 << i
+		b.Run(fmt.Sprintf("%d points", nbPoints), func(b *testing.B) {
 			b.ResetTimer()
 			for j := 0; j < b.N; j++ {
-				<-testPoint.MultiExp(samplePoints[:50000+i*50000], sampleScalars[:50000+i*50000])
+				testPoint.MultiExp(context.Background(), samplePoints[:nbPoints], sampleScalars[:nbPoints], nil)
 			}
 		})
 	}
@@ -0,0 +1,324 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bls381
+
+import (
+	"github.com/consensys/gurvy/bls381/fp"
+	"github.com/consensys/gurvy/utils"
+)
+
+// hash to curve, following RFC 9380
+// suites BLS12381G1_XMD:SHA-256_SSWU_RO_, BLS12381G1_XMD:SHA-256_SSWU_NU_,
+// BLS12381G2_XMD:SHA-256_SSWU_RO_ and BLS12381G2_XMD:SHA-256_SSWU_NU_
+
+// security parameter of the suites, in bytes: L = ceil((ceil(log2(p)) + k) / 8), k = 128
+const hashToFieldL = 64
+
+// simplified SWU parameters, on the curves E1' and E2' isogenous to E1 and E2
+// cf RFC 9380, section 8.8
+var sswuG1 struct {
+	A, B, Z              fp.Element
+	minusBOverA, bOverZA fp.Element
+}
+
+var sswuG2 struct {
+	A, B, Z              E2
+	minusBOverA, bOverZA E2
+}
+
+func init() {
+
+	// A1' = 0x144698a3b8e9433d693a02c96d4982b0ea985383ee66a8d8e8981aefd881ac98936f8da0e0f97f5cf428082d584c1d
+	sswuG1.A = fp.Element{3415322872136444497, 9675504606121301699, 13284745414851768802, 2873609449387478652, 2897906769629812789, 1536947672689614213}
+	// B1' = 0x12e2908d11688030018b12e8753eee3b2016c1f0f24f4070a0b9c14fcef35ef55a23215a316ceaa5d1cc48e98e172be0
+	sswuG1.B = fp.Element{18129637713272545760, 11144507692959411567, 10108153527111632324, 9745270364868568433, 14587922135379007624, 469008097655535723}
+	sswuG1.Z.SetUint64(11)
+	sswuG1.minusBOverA.Div(&sswuG1.B, &sswuG1.A).Neg(&sswuG1.minusBOverA)
+	sswuG1.bOverZA.Mul(&sswuG1.Z, &sswuG1.A).Inverse(&sswuG1.bOverZA).Mul(&sswuG1.bOverZA, &sswuG1.B)
+
+	// A2' = 240*u, B2' = 1012*(1+u), Z = -(2+u)
+	sswuG2.A.A1.SetUint64(240)
+	sswuG2.B.A0.SetUint64(1012)
+	sswuG2.B.A1.SetUint64(1012)
+	sswuG2.Z.A0.SetUint64(2)
+	sswuG2.Z.A1.SetUint64(1)
+	sswuG2.Z.Neg(&sswuG2.Z)
+	sswuG2.minusBOverA.Inverse(&sswuG2.A).Mul(&sswuG2.minusBOverA, &sswuG2.B).Neg(&sswuG2.minusBOverA)
+	sswuG2.bOverZA.Mul(&sswuG2.Z, &sswuG2.A).Inverse(&sswuG2.bOverZA).Mul(&sswuG2.bOverZA, &sswuG2.B)
+}
+
+// HashToG1 hashes msg to a point of G1, with domain separation tag dst
+// It is indifferentiable from a random oracle (suite BLS12381G1_XMD:SHA-256_SSWU_RO_)
+func HashToG1(msg, dst []byte) (G1Affine, error) {
+	var res G1Affine
+	u, err := hashToFp(msg, dst, 2)
+	if err != nil {
+		return res, err
+	}
+	q0 := MapToG1(u[0])
+	q1 := MapToG1(u[1])
+
+	var _q0, _q1 G1Jac
+	_q0.FromAffine(&q0)
+	_q1.FromAffine(&q1)
+	_q0.AddAssign(&_q1).ClearCofactor(&_q0)
+	res.FromJacobian(&_q0)
+
+	return res, nil
+}
+
+// EncodeToG1 hashes msg to a point of G1, with domain separation tag dst
+// Its output is not uniformly distributed (suite BLS12381G1_XMD:SHA-256_SSWU_NU_)
+func EncodeToG1(msg, dst []byte) (G1Affine, error) {
+	var res G1Affine
+	u, err := hashToFp(msg, dst, 1)
+	if err != nil {
+		return res, err
+	}
+	res = MapToG1(u[0])
+	res.ClearCofactor(&res)
+	return res, nil
+}
+
+// MapToG1 maps a field element to a point of E1 (map_to_curve in RFC 9380),
+// using the simplified SWU map on E1' followed by the 11-isogeny to E1
+// The result is not in G1 (cf ClearCofactor)
+func MapToG1(u fp.Element) G1Affine {
+	res := sswuMapG1(&u)
+	isogenyMapG1(&res)
+	return res
+}
+
+// HashToG2 hashes msg to a point of G2, with domain separation tag dst
+// It is indifferentiable from a random oracle (suite BLS12381G2_XMD:SHA-256_SSWU_RO_)
+func HashToG2(msg, dst []byte) (G2Affine, error) {
+	var res G2Affine
+	u, err := hashToE2(msg, dst, 2)
+	if err != nil {
+		return res, err
+	}
+	q0 := MapToG2(u[0])
+	q1 := MapToG2(u[1])
+
+	var _q0, _q1 G2Jac
+	_q0.FromAffine(&q0)
+	_q1.FromAffine(&q1)
+	_q0.AddAssign(&_q1).ClearCofactor(&_q0)
+	res.FromJacobian(&_q0)
+
+	return res, nil
+}
+
+// EncodeToG2 hashes msg to a point of G2, with domain separation tag dst
+// Its output is not uniformly distributed (suite BLS12381G2_XMD:SHA-256_SSWU_NU_)
+func EncodeToG2(msg, dst []byte) (G2Affine, error) {
+	var res G2Affine
+	u, err := hashToE2(msg, dst, 1)
+	if err != nil {
+		return res, err
+	}
+	res = MapToG2(u[0])
+	res.ClearCofactor(&res)
+	return res, nil
+}
+
+// MapToG2 maps an element of Fp2 to a point of E2 (map_to_curve in RFC 9380),
+// using the simplified SWU map on E2' followed by the 3-isogeny to E2
+// The result is not in G2 (cf ClearCofactor)
+func MapToG2(u E2) G2Affine {
+	res := sswuMapG2(&u)
+	isogenyMapG2(&res)
+	return res
+}
+
+// hashToFp hashes msg to count elements of Fp (hash_to_field in RFC 9380)
+func hashToFp(msg, dst []byte, count int) ([]fp.Element, error) {
+	uniformBytes, err := utils.ExpandMsgXmd(msg, dst, count*hashToFieldL)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]fp.Element, count)
+	for i := 0; i < count; i++ {
+		res[i].SetBytes(uniformBytes[i*hashToFieldL : (i+1)*hashToFieldL])
+	}
+	return res, nil
+}
+
+// hashToE2 hashes msg to count elements of Fp2 (hash_to_field in RFC 9380)
+func hashToE2(msg, dst []byte, count int) ([]E2, error) {
+	u, err := hashToFp(msg, dst, 2*count)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]E2, count)
+	for i := 0; i < count; i++ {
+		res[i].A0.Set(&u[2*i])
+		res[i].A1.Set(&u[2*i+1])
+	}
+	return res, nil
+}
+
+// sgn0 returns the parity of z (in regular form), cf RFC 9380, section 4.1
+func sgn0(z *fp.Element) uint64 {
+	_z := z.ToRegular()
+	return _z[0] & 1
+}
+
+// sgn0E2 returns the "sign" of z (A0 + u*A1), cf RFC 9380, section 4.1
+func sgn0E2(z *E2) uint64 {
+	sign0 := sgn0(&z.A0)
+	var zero0 uint64
+	if z.A0.IsZero() {
+		zero0 = 1
+	}
+	return sign0 | (zero0 & sgn0(&z.A1))
+}
+
+// sswuMapG1 simplified SWU map on E1', cf RFC 9380, section 6.6.2
+func sswuMapG1(u *fp.Element) G1Affine {
+
+	var res G1Affine
+	var tv1, tv2, x1, gx1, x2, gx2 fp.Element
+
+	// tv1 = inv0(Z**2 * u**4 + Z * u**2)
+	tv2.Square(u).Mul(&tv2, &sswuG1.Z)
+	tv1.Square(&tv2).Add(&tv1, &tv2).Inverse(&tv1)
+
+	// x1 = (-B / A) * (1 + tv1), or B / (Z * A) if tv1 == 0
+	if tv1.IsZero() {
+		x1.Set(&sswuG1.bOverZA)
+	} else {
+		var one fp.Element
+		one.SetOne()
+		x1.Add(&tv1, &one).Mul(&x1, &sswuG1.minusBOverA)
+	}
+
+	// gx1 = x1**3 + A * x1 + B
+	gx1.Square(&x1).Add(&gx1, &sswuG1.A).Mul(&gx1, &x1).Add(&gx1, &sswuG1.B)
+
+	if res.Y.Sqrt(&gx1) != nil {
+		res.X.Set(&x1)
+	} else {
+		// x2 = Z * u**2 * x1, gx2 = x2**3 + A * x2 + B
+		x2.Mul(&tv2, &x1)
+		gx2.Square(&x2).Add(&gx2, &sswuG1.A).Mul(&gx2, &x2).Add(&gx2, &sswuG1.B)
+		res.X.Set(&x2)
+		res.Y.Sqrt(&gx2)
+	}
+
+	if sgn0(u) != sgn0(&res.Y) {
+		res.Y.Neg(&res.Y)
+	}
+
+	return res
+}
+
+// sswuMapG2 simplified SWU map on E2', cf RFC 9380, section 6.6.2
+func sswuMapG2(u *E2) G2Affine {
+
+	var res G2Affine
+	var tv1, tv2, x1, gx1, x2, gx2 E2
+
+	// tv1 = inv0(Z**2 * u**4 + Z * u**2)
+	tv2.Square(u).Mul(&tv2, &sswuG2.Z)
+	tv1.Square(&tv2).Add(&tv1, &tv2)
+	if !tv1.IsZero() {
+		tv1.Inverse(&tv1)
+	}
+
+	// x1 = (-B / A) * (1 + tv1), or B / (Z * A) if tv1 == 0
+	if tv1.IsZero() {
+		x1.Set(&sswuG2.bOverZA)
+	} else {
+		var one E2
+		one.SetOne()
+		x1.Add(&tv1, &one).Mul(&x1, &sswuG2.minusBOverA)
+	}
+
+	// gx1 = x1**3 + A * x1 + B
+	gx1.Square(&x1).Add(&gx1, &sswuG2.A).Mul(&gx1, &x1).Add(&gx1, &sswuG2.B)
+
+	if res.Y.Sqrt(&gx1) != nil {
+		res.X.Set(&x1)
+	} else {
+		// x2 = Z * u**2 * x1, gx2 = x2**3 + A * x2 + B
+		x2.Mul(&tv2, &x1)
+		gx2.Square(&x2).Add(&gx2, &sswuG2.A).Mul(&gx2, &x2).Add(&gx2, &sswuG2.B)
+		res.X.Set(&x2)
+		res.Y.Sqrt(&gx2)
+	}
+
+	if sgn0E2(u) != sgn0E2(&res.Y) {
+		res.Y.Neg(&res.Y)
+	}
+
+	return res
+}
+
+// isogenyMapG1 maps p from E1' to E1 with the 11-isogeny
+// (x, y) -> (xNum(x) / xDen(x), y * yNum(x) / yDen(x))
+func isogenyMapG1(p *G1Affine) {
+	var xNum, xDen, yNum, yDen fp.Element
+	evalPolynomial(&xNum, isogenyG1[0], &p.X)
+	evalPolynomial(&xDen, isogenyG1[1], &p.X)
+	evalPolynomial(&yNum, isogenyG1[2], &p.X)
+	evalPolynomial(&yDen, isogenyG1[3], &p.X)
+
+	// the denominators vanish only on the kernel of the isogeny, which is mapped to infinity
+	if xDen.IsZero() || yDen.IsZero() {
+		p.X.SetZero()
+		p.Y.SetZero()
+		return
+	}
+	p.X.Div(&xNum, &xDen)
+	p.Y.Mul(&p.Y, &yNum).Div(&p.Y, &yDen)
+}
+
+// isogenyMapG2 maps p from E2' to E2 with the 3-isogeny
+// (x, y) -> (xNum(x) / xDen(x), y * yNum(x) / yDen(x))
+func isogenyMapG2(p *G2Affine) {
+	var xNum, xDen, yNum, yDen E2
+	evalPolynomialE2(&xNum, isogenyG2[0], &p.X)
+	evalPolynomialE2(&xDen, isogenyG2[1], &p.X)
+	evalPolynomialE2(&yNum, isogenyG2[2], &p.X)
+	evalPolynomialE2(&yDen, isogenyG2[3], &p.X)
+
+	// the denominators vanish only on the kernel of the isogeny, which is mapped to infinity
+	if xDen.IsZero() || yDen.IsZero() {
+		p.X.SetZero()
+		p.Y.SetZero()
+		return
+	}
+	xDen.Inverse(&xDen)
+	yDen.Inverse(&yDen)
+	p.X.Mul(&xNum, &xDen)
+	p.Y.Mul(&p.Y, &yNum).Mul(&p.Y, &yDen)
+}
+
+// evalPolynomial sets z to c[0] + c[1]*x + ... + c[n]*x**n (Horner)
+func evalPolynomial(z *fp.Element, c []fp.Element, x *fp.Element) {
+	z.Set(&c[len(c)-1])
+	for i := len(c) - 2; i >= 0; i-- {
+		z.Mul(z, x).Add(z, &c[i])
+	}
+}
+
+// evalPolynomialE2 sets z to c[0] + c[1]*x + ... + c[n]*x**n (Horner)
+func evalPolynomialE2(z *E2, c []E2, x *E2) {
+	z.Set(&c[len(c)-1])
+	for i := len(c) - 2; i >= 0; i-- {
+		z.Mul(z, x).Add(z, &c[i])
+	}
+}
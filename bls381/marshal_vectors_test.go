@@ -0,0 +1,175 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bls381
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"testing"
+)
+
+// test vectors from zkcrypto/bls12_381 (Zcash serialization format)
+// testdata/g{1,2}_{compressed,uncompressed}_valid_test_vectors.dat contain the encodings
+// of [i]G for i in [0, 1000), where G is the standard generator of G1 (resp. G2)
+
+const nbZcashVectors = 1000
+
+// standard generators of G1 and G2, Zcash compressed encoding
+const (
+	zcashG1Generator = "97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"
+	zcashG2Generator = "93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8"
+)
+
+func zcashG1Gen(t *testing.T) G1Jac {
+	var gen G1Affine
+	buf, _ := hex.DecodeString(zcashG1Generator)
+	if _, err := gen.SetBytes(buf); err != nil {
+		t.Fatal(err)
+	}
+	var res G1Jac
+	res.FromAffine(&gen)
+	return res
+}
+
+func zcashG2Gen(t *testing.T) G2Jac {
+	var gen G2Affine
+	buf, _ := hex.DecodeString(zcashG2Generator)
+	if _, err := gen.SetBytes(buf); err != nil {
+		t.Fatal(err)
+	}
+	var res G2Jac
+	res.FromAffine(&gen)
+	return res
+}
+
+func TestG1AffineZcashVectors(t *testing.T) {
+	compressed, err := ioutil.ReadFile("testdata/g1_compressed_valid_test_vectors.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uncompressed, err := ioutil.ReadFile("testdata/g1_uncompressed_valid_test_vectors.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gen := zcashG1Gen(t)
+	var acc G1Jac // [i]G, starting at infinity
+	acc.Set(&g1Infinity)
+
+	for i := 0; i < nbZcashVectors; i++ {
+		var expected, p1, p2 G1Affine
+		expected.FromJacobian(&acc)
+
+		vector := compressed[i*SizeOfG1AffineCompressed : (i+1)*SizeOfG1AffineCompressed]
+		if _, err := p1.SetBytes(vector); err != nil {
+			t.Fatal("compressed vector", i, err)
+		}
+		if buf := p1.Bytes(); !bytes.Equal(buf[:], vector) || !p1.Equal(&expected) {
+			t.Fatal("compressed vector", i, "mismatch")
+		}
+
+		vector = uncompressed[i*SizeOfG1AffineUncompressed : (i+1)*SizeOfG1AffineUncompressed]
+		if _, err := p2.SetBytes(vector); err != nil {
+			t.Fatal("uncompressed vector", i, err)
+		}
+		if buf := p2.RawBytes(); !bytes.Equal(buf[:], vector) || !p2.Equal(&expected) {
+			t.Fatal("uncompressed vector", i, "mismatch")
+		}
+
+		acc.AddAssign(&gen)
+	}
+}
+
+func TestG2AffineZcashVectors(t *testing.T) {
+	compressed, err := ioutil.ReadFile("testdata/g2_compressed_valid_test_vectors.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uncompressed, err := ioutil.ReadFile("testdata/g2_uncompressed_valid_test_vectors.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gen := zcashG2Gen(t)
+	var acc G2Jac // [i]G, starting at infinity
+	acc.Set(&g2Infinity)
+
+	for i := 0; i < nbZcashVectors; i++ {
+		var expected, p1, p2 G2Affine
+		expected.FromJacobian(&acc)
+
+		vector := compressed[i*SizeOfG2AffineCompressed : (i+1)*SizeOfG2AffineCompressed]
+		if _, err := p1.SetBytes(vector); err != nil {
+			t.Fatal("compressed vector", i, err)
+		}
+		if buf := p1.Bytes(); !bytes.Equal(buf[:], vector) || !p1.Equal(&expected) {
+			t.Fatal("compressed vector", i, "mismatch")
+		}
+
+		vector = uncompressed[i*SizeOfG2AffineUncompressed : (i+1)*SizeOfG2AffineUncompressed]
+		if _, err := p2.SetBytes(vector); err != nil {
+			t.Fatal("uncompressed vector", i, err)
+		}
+		if buf := p2.RawBytes(); !bytes.Equal(buf[:], vector) || !p2.Equal(&expected) {
+			t.Fatal("uncompressed vector", i, "mismatch")
+		}
+
+		acc.AddAssign(&gen)
+	}
+}
+
+func TestZcashInvalidEncodings(t *testing.T) {
+	g1, _ := hex.DecodeString(zcashG1Generator)
+	g2, _ := hex.DecodeString(zcashG2Generator)
+	var p1 G1Affine
+	var p2 G2Affine
+
+	// compressed, with the sort flag set but not the compression flag
+	for _, flags := range []byte{0b001 << 5, 0b011 << 5, 0b111 << 5} {
+		buf := append([]byte{}, g1...)
+		buf[0] = buf[0]&^mMask | flags
+		if _, err := p1.SetBytes(buf); err != ErrInvalidFlags {
+			t.Fatal("expected ErrInvalidFlags, got", err)
+		}
+		buf = append([]byte{}, g2...)
+		buf[0] = buf[0]&^mMask | flags
+		if _, err := p2.SetBytes(buf); err != ErrInvalidFlags {
+			t.Fatal("expected ErrInvalidFlags, got", err)
+		}
+	}
+
+	// uncompressed, all zeroes is not the point at infinity
+	var raw1 [SizeOfG1AffineUncompressed]byte
+	if _, err := p1.SetBytes(raw1[:]); err != ErrPointNotOnCurve {
+		t.Fatal("expected ErrPointNotOnCurve, got", err)
+	}
+	var raw2 [SizeOfG2AffineUncompressed]byte
+	if _, err := p2.SetBytes(raw2[:]); err != ErrPointNotOnCurve {
+		t.Fatal("expected ErrPointNotOnCurve, got", err)
+	}
+
+	// uncompressed infinity with non zero bits
+	raw1[0] = mUncompressedInfinity
+	raw1[SizeOfG1AffineUncompressed-1] = 1
+	if _, err := p1.SetBytes(raw1[:]); err != ErrInvalidInfinityEncoding {
+		t.Fatal("expected ErrInvalidInfinityEncoding, got", err)
+	}
+	raw2[0] = mUncompressedInfinity
+	raw2[SizeOfG2AffineUncompressed-1] = 1
+	if _, err := p2.SetBytes(raw2[:]); err != ErrInvalidInfinityEncoding {
+		t.Fatal("expected ErrInvalidInfinityEncoding, got", err)
+	}
+}
@@ -15,12 +15,37 @@
 package bls381
 
 import (
-	"math/bits"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gurvy/bls381/fp"
+	"github.com/consensys/gurvy/utils"
 )
 
 // PairingResult target group of the pairing
 type PairingResult = E12
 
+// ErrPairingInputSize is returned by Pair and PairingCheck when P and Q have different lengths
+var ErrPairingInputSize = errors.New("invalid inputs: P and Q must have the same length")
+
+// PairingOption sets an option of MillerLoopMulti, Pair and PairingCheck
+type PairingOption func(*pairingConfig)
+
+type pairingConfig struct {
+	affine bool
+}
+
+// AffineMillerLoop computes the Miller loop with the multiples of Q[i] in affine coordinates:
+// the slopes of the lines of all pairs share one batched inversion per doubling or addition step.
+// It outputs the same reduced pairing as the default (jacobian) Miller loop, but not the same
+// Miller loop value, and is faster when there are many pairs (from about 8 pairs).
+func AffineMillerLoop() PairingOption {
+	return func(cfg *pairingConfig) {
+		cfg.affine = true
+	}
+}
+
 type lineEvaluation struct {
 	r0 E2
 	r1 E2
@@ -129,9 +154,237 @@ func MillerLoop(P G1Affine, Q G2Affine) *PairingResult {
 	return &result
 }
 
+// Pair computes the reduced pairing e(P[0], Q[0]) * ... * e(P[n-1], Q[n-1])
+// with a single shared Miller loop and a single final exponentiation
+// pairs where P[i] or Q[i] is the point at infinity are skipped
+func Pair(P []G1Affine, Q []G2Affine, options ...PairingOption) (GT, error) {
+	var res GT
+	f, err := MillerLoopMulti(P, Q, options...)
+	if err != nil {
+		return res, err
+	}
+	res.FinalExponentiation(&f)
+	return res, nil
+}
+
+// PairingCheck returns true if e(P[0], Q[0]) * ... * e(P[n-1], Q[n-1]) == 1
+func PairingCheck(P []G1Affine, Q []G2Affine, options ...PairingOption) (bool, error) {
+	f, err := Pair(P, Q, options...)
+	if err != nil {
+		return false, err
+	}
+	return f.IsOne(), nil
+}
+
+// MillerLoopMulti computes the product of the Miller loops of the pairs (P[i], Q[i])
+// the accumulator is squared once per iteration for all pairs
+// pairs where P[i] or Q[i] is the point at infinity are skipped
+func MillerLoopMulti(P []G1Affine, Q []G2Affine, options ...PairingOption) (PairingResult, error) {
+
+	if len(P) != len(Q) {
+		var result PairingResult
+		result.SetOne()
+		return result, ErrPairingInputSize
+	}
+
+	var cfg pairingConfig
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	// filter out the points at infinity
+	p := make([]G1Affine, 0, len(P))
+	q := make([]G2Affine, 0, len(Q))
+	for i := range P {
+		if P[i].IsInfinity() || Q[i].IsInfinity() {
+			continue
+		}
+		p = append(p, P[i])
+		q = append(q, Q[i])
+	}
+
+	if cfg.affine {
+		return millerLoopAffine(p, q), nil
+	}
+	return millerLoopJacobian(p, q), nil
+}
+
+// millerLoopJacobian computes the product of the Miller loops of the pairs (p[k], q[k]), none at infinity
+// the multiples of q[k] are in jacobian coordinates
+func millerLoopJacobian(p []G1Affine, q []G2Affine) PairingResult {
+
+	var result PairingResult
+	result.SetOne()
+
+	n := len(p)
+
+	qJac := make([]G2Jac, n)
+	qBuf := make([]G2Jac, n)
+	for k := 0; k < n; k++ {
+		qJac[k].FromAffine(&q[k])
+		qBuf[k].FromAffine(&q[k])
+	}
+
+	var Q1 G2Jac
+	lEval := make([]lineEvaluation, n)
+	for i := len(loopCounter) - 2; i >= 0; i-- {
+
+		result.Square(&result)
+
+		for k := 0; k < n; k++ {
+			Q1.Set(&qJac[k])
+			qJac[k].Double(&Q1).Neg(&qJac[k])
+			lineEval(&Q1, &qJac[k], &p[k], &lEval[k]) // f(P), div(f) = 2(Q1)+(-2Q1)-3(O)
+			qJac[k].Neg(&qJac[k])
+		}
+		result.mulLines(lEval)
+
+		if loopCounter[i] == 1 {
+			for k := 0; k < n; k++ {
+				lineEval(&qJac[k], &qBuf[k], &p[k], &lEval[k]) // f(P), div(f) = (Q2)+(Q)+(-Q2-Q)-3(O)
+				qJac[k].AddMixed(&q[k])
+			}
+			result.mulLines(lEval)
+		}
+	}
+
+	return result
+}
+
+// millerLoopAffine computes the product of the Miller loops of the pairs (p[k], q[k]), none at infinity
+// the multiples of q[k] are in affine coordinates, the denominators of the slopes being inverted in a batch
+// the lines differ from those of millerLoopJacobian by E2 factors, so the result only matches
+// millerLoopJacobian after the final exponentiation
+func millerLoopAffine(p []G1Affine, q []G2Affine) PairingResult {
+
+	var result PairingResult
+	result.SetOne()
+
+	n := len(p)
+	qAcc := make([]G2Affine, n)
+	copy(qAcc, q)
+
+	den := make([]E2, n)
+	lEval := make([]lineEvaluation, n)
+	for i := len(loopCounter) - 2; i >= 0; i-- {
+
+		result.Square(&result)
+
+		for k := 0; k < n; k++ {
+			den[k].Double(&qAcc[k].Y)
+		}
+		batchInvertE2(den)
+		for k := 0; k < n; k++ {
+			doubleStep(&qAcc[k], &den[k], &p[k], &lEval[k]) // f(P), div(f) = 2(Q1)+(-2Q1)-3(O)
+		}
+		result.mulLines(lEval)
+
+		if loopCounter[i] == 1 {
+			for k := 0; k < n; k++ {
+				den[k].Sub(&q[k].X, &qAcc[k].X)
+			}
+			batchInvertE2(den)
+			for k := 0; k < n; k++ {
+				addStep(&qAcc[k], &q[k], &den[k], &p[k], &lEval[k]) // f(P), div(f) = (Q2)+(Q)+(-Q2-Q)-3(O)
+			}
+			result.mulLines(lEval)
+		}
+	}
+
+	return result
+}
+
+// doubleStep sets T to 2T and l to the evaluation at P of the tangent at T
+// inv must be 1/(2*T.Y)
+func doubleStep(T *G2Affine, inv *E2, P *G1Affine, l *lineEvaluation) {
+	var lambda, x3 E2
+
+	// lambda = 3x**2/2y
+	lambda.Square(&T.X)
+	x3.Double(&lambda)
+	lambda.Add(&lambda, &x3).Mul(&lambda, inv)
+
+	l.affineLine(T, &lambda, P)
+
+	x3.Square(&lambda).
+		Sub(&x3, &T.X).
+		Sub(&x3, &T.X)
+	// y3 = lambda*(xT-x3) - yT = r2 - lambda*x3
+	T.Y.Mul(&lambda, &x3).
+		Sub(&l.r2, &T.Y)
+	T.X.Set(&x3)
+}
+
+// addStep sets T to T+Q and l to the evaluation at P of the line through T and Q
+// inv must be 1/(Q.X-T.X)
+func addStep(T, Q *G2Affine, inv *E2, P *G1Affine, l *lineEvaluation) {
+	var lambda, x3 E2
+
+	// lambda = (yQ-yT)/(xQ-xT)
+	lambda.Sub(&Q.Y, &T.Y).Mul(&lambda, inv)
+
+	l.affineLine(T, &lambda, P)
+
+	x3.Square(&lambda).
+		Sub(&x3, &T.X).
+		Sub(&x3, &Q.X)
+	// y3 = lambda*(xT-x3) - yT = r2 - lambda*x3
+	T.Y.Mul(&lambda, &x3).
+		Sub(&l.r2, &T.Y)
+	T.X.Set(&x3)
+}
+
+// affineLine sets l to the evaluation at P of the line of slope lambda through T, returns l
+// the line is y - lambda*x + (lambda*xT - yT), i.e. r0 = yP, r1 = -lambda*xP, r2 = lambda*xT - yT (cf lineCoeffs)
+func (l *lineEvaluation) affineLine(T *G2Affine, lambda *E2, P *G1Affine) *lineEvaluation {
+	l.r0.A0.Set(&P.Y)
+	l.r0.A1.SetZero()
+	l.r1.MulByElement(lambda, &P.X).Neg(&l.r1)
+	l.r2.Mul(lambda, &T.X).Sub(&l.r2, &T.Y)
+	return l
+}
+
+// batchInvertE2 sets a[i] to 1/a[i] for all i, with a single inversion (Montgomery's trick)
+// zero elements are left unchanged
+func batchInvertE2(a []E2) {
+	if len(a) == 0 {
+		return
+	}
+	acc := make([]E2, len(a))
+	var accumulator E2
+	accumulator.SetOne()
+	for i := range a {
+		acc[i].Set(&accumulator)
+		if a[i].IsZero() {
+			continue
+		}
+		accumulator.Mul(&accumulator, &a[i])
+	}
+
+	accumulator.Inverse(&accumulator)
+
+	var tmp E2
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i].IsZero() {
+			continue
+		}
+		tmp.Mul(&accumulator, &acc[i])
+		accumulator.Mul(&accumulator, &a[i])
+		a[i].Set(&tmp)
+	}
+}
+
 // lineEval computes the evaluation of the line through Q, R (on the twist) at P
 // Q, R are in jacobian coordinates
 func lineEval(Q, R *G2Jac, P *G1Affine, result *lineEvaluation) {
+	lineCoeffs(Q, R, result)
+	result.evaluate(result, P)
+}
+
+// lineCoeffs computes the coefficients of the line through Q, R (on the twist),
+// independently of the point P at which it is evaluated (cf evaluate)
+// Q, R are in jacobian coordinates
+func lineCoeffs(Q, R *G2Jac, result *lineEvaluation) {
 
 	// converts _Q and _R to projective coords
 	var _Q, _R G2Proj
@@ -150,21 +403,162 @@ func lineEval(Q, R *G2Jac, P *G1Affine, result *lineEvaluation) {
 	result.r0.Sub(&result.r0, &_Q.X)
 	result.r2.Sub(&result.r2, &_Q.Y)
 
-	result.r1.MulByElement(&result.r1, &P.X)
-	result.r0.MulByElement(&result.r0, &P.Y)
 }
 
-// multiplies a result of a line evaluation to the current pairing result, taking care of mapping it
-// back to the original  The line evaluation l is f(P) where div(f)=(P')+(Q')+(-P'-Q')-3(O), the support
-// being on the twist.
-func (z *PairingResult) mulAssign(l *lineEvaluation) *PairingResult {
+// evaluate sets l to the evaluation at P of the line with coefficients c (cf lineCoeffs), returns l
+func (l *lineEvaluation) evaluate(c *lineEvaluation, P *G1Affine) *lineEvaluation {
+	l.r0.MulByElement(&c.r0, &P.Y)
+	l.r1.MulByElement(&c.r1, &P.X)
+	l.r2.Set(&c.r2)
+	return l
+}
+
+// G2Prepared line coefficients of the Miller loop for a fixed G2 point
+// they are computed once (cf FromAffine) and evaluated at P by MillerLoopFixedQ
+type G2Prepared struct {
+	lines    [68]lineEvaluation
+	infinity bool
+}
+
+// SizeOfG2Prepared represents the size in bytes that a G2Prepared needs in binary form
+const SizeOfG2Prepared = 1 + 68*3*2*fp.Limbs*8
+
+// FromAffine computes the line coefficients of the Miller loop for Q, sets p and returns it
+func (p *G2Prepared) FromAffine(Q *G2Affine) *G2Prepared {
+
+	p.lines = [68]lineEvaluation{}
+	p.infinity = Q.IsInfinity()
+	if p.infinity {
+		return p
+	}
+
+	var Q1, Q2, Qbuf G2Jac
+	Q2.FromAffine(Q)
+	Qbuf.FromAffine(Q)
+
+	j := 0
+	for i := len(loopCounter) - 2; i >= 0; i-- {
 
-	var a, b, c PairingResult
-	a.MulByVWNRInv(z, &l.r1)
-	b.MulByV2NRInv(z, &l.r0)
-	c.MulByWNRInv(z, &l.r2)
-	z.Add(&a, &b).Add(z, &c)
+		Q1.Set(&Q2)
+		Q2.Double(&Q1).Neg(&Q2)
+		lineCoeffs(&Q1, &Q2, &p.lines[j]) // div(f) = 2(Q1)+(-2Q2)-3(O)
+		Q2.Neg(&Q2)
+		j++
+
+		if loopCounter[i] == 1 {
+			lineCoeffs(&Q2, &Qbuf, &p.lines[j]) // div(f) = (Q2)+(Q)+(-Q2-Q)-3(O)
+			Q2.AddMixed(Q)
+			j++
+		}
+	}
+	return p
+}
+
+// MillerLoopFixedQ Miller loop, with the line coefficients precomputed in Q
+// it outputs the same result as MillerLoop
+func MillerLoopFixedQ(P G1Affine, Q *G2Prepared) *PairingResult {
+
+	var result PairingResult
+	result.SetOne()
 
+	if P.IsInfinity() || Q.infinity {
+		return &result
+	}
+
+	var lEval lineEvaluation
+	j := 0
+	for i := len(loopCounter) - 2; i >= 0; i-- {
+
+		result.Square(&result)
+		result.mulAssign(lEval.evaluate(&Q.lines[j], &P))
+		j++
+
+		if loopCounter[i] == 1 {
+			result.mulAssign(lEval.evaluate(&Q.lines[j], &P))
+			j++
+		}
+	}
+	return &result
+}
+
+// Bytes returns the binary encoding of p, SizeOfG2Prepared bytes: an infinity flag byte (0 or 1) followed by
+// the line coefficients r0, r1, r2 of each line, as regular big-endian fp.Element (A0 then A1)
+// (all zero if p is at infinity)
+func (p *G2Prepared) Bytes() []byte {
+	r := make([]byte, SizeOfG2Prepared)
+	const fpSize = fp.Limbs * 8
+	if p.infinity {
+		r[0] = 1
+		return r
+	}
+	for i := range p.lines {
+		for j, e := range p.lines[i].coordinates() {
+			offset := 1 + (6*i+j)*fpSize
+			copy(r[offset:offset+fpSize], e.Bytes())
+		}
+	}
+	return r
+}
+
+// SetBytes interprets e as the bytes of a G2Prepared (see Bytes)
+// it returns an error if a coordinate is not reduced modulo q
+func (p *G2Prepared) SetBytes(e []byte) error {
+	if len(e) < SizeOfG2Prepared {
+		return io.ErrShortBuffer
+	}
+	if e[0] > 1 {
+		return ErrInvalidInfinityEncoding
+	}
+	const fpSize = fp.Limbs * 8
+	var res G2Prepared
+	res.infinity = e[0] == 1
+	for i := range res.lines {
+		for j, c := range res.lines[i].coordinates() {
+			offset := 1 + (6*i+j)*fpSize
+			if err := setElementBytes(c, e[offset:offset+fpSize]); err != nil {
+				return err
+			}
+			if res.infinity && !c.IsZero() {
+				return ErrInvalidInfinityEncoding
+			}
+		}
+	}
+	*p = res
+	return nil
+}
+
+// coordinates returns pointers to the fp.Element coordinates of l
+func (l *lineEvaluation) coordinates() [6]*fp.Element {
+	return [6]*fp.Element{&l.r0.A0, &l.r0.A1, &l.r1.A0, &l.r1.A1, &l.r2.A0, &l.r2.A1}
+}
+
+// mulAssign multiplies the current pairing result by the line evaluation l and returns it
+// The line evaluation l is f(P) where div(f)=(P')+(Q')+(-P'-Q')-3(O), the support being on the twist:
+// mapped back to E12 it is ξ**-1 * (r0*v**2 + r1*v*w + r2*w). It is multiplied by ξ*w**3 (in E4,
+// so the final exponentiation is unchanged) to get the sparse element ξ*r1 + r2*v**2 + ξ*r0*w (cf MulBy023)
+func (z *PairingResult) mulAssign(l *lineEvaluation) *PairingResult {
+	var c0, c3 E2
+	c0.MulByNonResidue(&l.r1)
+	c3.MulByNonResidue(&l.r0)
+	return z.MulBy023(z, &c0, &l.r2, &c3)
+}
+
+// mulLines multiplies the current pairing result by the line evaluations l and returns it
+// the lines are multiplied two by two first (cf Mul023By023)
+func (z *PairingResult) mulLines(l []lineEvaluation) *PairingResult {
+	var c0, c3, d0, d3 E2
+	k := 0
+	for ; k+1 < len(l); k += 2 {
+		c0.MulByNonResidue(&l[k].r1)
+		c3.MulByNonResidue(&l[k].r0)
+		d0.MulByNonResidue(&l[k+1].r1)
+		d3.MulByNonResidue(&l[k+1].r0)
+		prod := Mul023By023(&c0, &l[k].r2, &c3, &d0, &l[k+1].r2, &d3)
+		z.MulBy01235(z, &prod)
+	}
+	if k < len(l) {
+		z.mulAssign(&l[k])
+	}
 	return z
 }
 
@@ -253,23 +647,23 @@ func (z *PairingResult) MulByWNRInv(x *PairingResult, y *E2) *PairingResult {
 	return z
 }
 
-// Expt set z to x^t in PairingResult and return z
-func (z *PairingResult) Expt(x *PairingResult) *PairingResult {
+// tAbsVal absolute value of the curve parameter t (t is negative)
+const tAbsVal uint64 = 15132376222941642752
 
-	tAbsVal := uint64(15132376222941642752) // negative
+// tNAF non-adjacent form of tAbsVal, least significant digit first
+var tNAF = func() []int8 {
+	var res [66]int8
+	n := utils.NafDecomposition(new(big.Int).SetUint64(tAbsVal), res[:])
+	return res[:n]
+}()
 
-	var result PairingResult
-	result.Set(x)
-
-	l := bits.Len64(tAbsVal) - 2
-	for i := l; i >= 0; i-- {
-		result.CyclotomicSquare(&result)
-		if tAbsVal&(1<<uint(i)) != 0 {
-			result.Mul(&result, x)
-		}
-	}
-	result.Conjugate(&result) // because tAbsVal is negative
-
-	z.Set(&result)
+// Expt set z to x^t in PairingResult and return z (t is the generator of the curve)
+// x must be in the cyclotomic subgroup (in particular x != 0), this is not checked: for other inputs,
+// 0 included, the output is meaningless (it may be 0 or 1 depending on the bits of t)
+//
+// the squarings are done in compressed form and decompressed in a batch (cf E12.expNAF)
+func (z *PairingResult) Expt(x *PairingResult) *PairingResult {
+	z.expNAF(x, tNAF)
+	z.Conjugate(z) // because t is negative
 	return z
 }
@@ -17,6 +17,7 @@
 package bls381
 
 import (
+	"fmt"
 	"math/big"
 	"testing"
 
@@ -104,6 +105,218 @@ func TestPairing(t *testing.T) {
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
+func TestMultiPairing(t *testing.T) {
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 10
+
+	properties := gopter.NewProperties(parameters)
+
+	genR1 := GenFr()
+	genR2 := GenFr()
+
+	var g1GenAff G1Affine
+	var g2GenAff G2Affine
+	g1GenAff.FromJacobian(&g1Gen)
+	g2GenAff.FromJacobian(&g2Gen)
+
+	properties.Property("MillerLoopMulti on a single pair should output the same result as MillerLoop", prop.ForAll(
+		func(a fr.Element) bool {
+			var abigint big.Int
+			var aG1 G1Jac
+			var ag1 G1Affine
+			a.ToBigIntRegular(&abigint)
+			aG1.ScalarMultiplication(&g1GenAff, &abigint)
+			ag1.FromJacobian(&aG1)
+
+			f, err := MillerLoopMulti([]G1Affine{ag1}, []G2Affine{g2GenAff})
+			return err == nil && f.Equal(MillerLoop(ag1, g2GenAff))
+		},
+		genR1,
+	))
+
+	properties.Property("Pair should output the product of the pairings", prop.ForAll(
+		func(a, b fr.Element) bool {
+			var abigint, bbigint big.Int
+			var aG1 G1Jac
+			var bG2 G2Jac
+			var ag1 G1Affine
+			var bg2 G2Affine
+			a.ToBigIntRegular(&abigint)
+			b.ToBigIntRegular(&bbigint)
+			aG1.ScalarMultiplication(&g1GenAff, &abigint)
+			bG2.ScalarMultiplication(&g2GenAff, &bbigint)
+			ag1.FromJacobian(&aG1)
+			bg2.FromJacobian(&bG2)
+
+			res, err := Pair([]G1Affine{ag1, g1GenAff}, []G2Affine{g2GenAff, bg2})
+			if err != nil {
+				return false
+			}
+			expected := FinalExponentiation(MillerLoop(ag1, g2GenAff), MillerLoop(g1GenAff, bg2))
+			return res.e.Equal(&expected)
+		},
+		genR1,
+		genR2,
+	))
+
+	properties.Property("PairingCheck of e([a]P, [b]Q) * e(-[ab]P, Q) should be true, points at infinity being skipped", prop.ForAll(
+		func(a, b fr.Element) bool {
+			var abigint, bbigint, ab big.Int
+			var aG1, abG1 G1Jac
+			var bG2 G2Jac
+			var ag1, abg1, inf1 G1Affine
+			var bg2, inf2 G2Affine
+			a.ToBigIntRegular(&abigint)
+			b.ToBigIntRegular(&bbigint)
+			ab.Mul(&abigint, &bbigint)
+			aG1.ScalarMultiplication(&g1GenAff, &abigint)
+			abG1.ScalarMultiplication(&g1GenAff, &ab)
+			bG2.ScalarMultiplication(&g2GenAff, &bbigint)
+			ag1.FromJacobian(&aG1)
+			abg1.FromJacobian(abG1.Neg(&abG1))
+			bg2.FromJacobian(&bG2)
+			inf1.FromJacobian(&g1Infinity)
+			inf2.FromJacobian(&g2Infinity)
+
+			ok, err := PairingCheck([]G1Affine{ag1, inf1, abg1, g1GenAff}, []G2Affine{bg2, g2GenAff, g2GenAff, inf2})
+			if err != nil || !ok {
+				return false
+			}
+			ok, err = PairingCheck([]G1Affine{ag1, g1GenAff}, []G2Affine{bg2, g2GenAff})
+			return err == nil && !ok
+		},
+		genR1,
+		genR2,
+	))
+
+	properties.Property("AffineMillerLoop should output the same reduced pairing as the jacobian Miller loop", prop.ForAll(
+		func(a, b fr.Element) bool {
+			var abigint, bbigint, ab big.Int
+			var aG1, abG1 G1Jac
+			var bG2 G2Jac
+			var ag1, abg1, inf1 G1Affine
+			var bg2 G2Affine
+			a.ToBigIntRegular(&abigint)
+			b.ToBigIntRegular(&bbigint)
+			ab.Mul(&abigint, &bbigint)
+			aG1.ScalarMultiplication(&g1GenAff, &abigint)
+			abG1.ScalarMultiplication(&g1GenAff, &ab)
+			bG2.ScalarMultiplication(&g2GenAff, &bbigint)
+			ag1.FromJacobian(&aG1)
+			abg1.FromJacobian(abG1.Neg(&abG1))
+			bg2.FromJacobian(&bG2)
+			inf1.FromJacobian(&g1Infinity)
+
+			P := []G1Affine{ag1, g1GenAff, inf1, ag1}
+			Q := []G2Affine{g2GenAff, bg2, bg2, bg2}
+			expected, err := Pair(P, Q)
+			if err != nil {
+				return false
+			}
+			res, err := Pair(P, Q, AffineMillerLoop())
+			if err != nil || !res.Equal(&expected) {
+				return false
+			}
+			ok, err := PairingCheck([]G1Affine{ag1, inf1, abg1}, []G2Affine{bg2, g2GenAff, g2GenAff}, AffineMillerLoop())
+			return err == nil && ok
+		},
+		genR1,
+		genR2,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	if _, err := Pair([]G1Affine{g1GenAff}, []G2Affine{g2GenAff, g2GenAff}); err != ErrPairingInputSize {
+		t.Fatal("Pair should fail when P and Q have different lengths")
+	}
+	if ok, err := PairingCheck(nil, nil); err != nil || !ok {
+		t.Fatal("PairingCheck of an empty product should be true")
+	}
+}
+
+func TestMillerLoopFixedQ(t *testing.T) {
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 10
+
+	properties := gopter.NewProperties(parameters)
+
+	genR1 := GenFr()
+	genR2 := GenFr()
+
+	var g1GenAff G1Affine
+	var g2GenAff G2Affine
+	g1GenAff.FromJacobian(&g1Gen)
+	g2GenAff.FromJacobian(&g2Gen)
+
+	properties.Property("MillerLoopFixedQ should output the same result as MillerLoop", prop.ForAll(
+		func(a, b fr.Element) bool {
+			var abigint, bbigint big.Int
+			var aG1 G1Jac
+			var bG2 G2Jac
+			var ag1 G1Affine
+			var bg2 G2Affine
+			a.ToBigIntRegular(&abigint)
+			b.ToBigIntRegular(&bbigint)
+			aG1.ScalarMultiplication(&g1GenAff, &abigint)
+			bG2.ScalarMultiplication(&g2GenAff, &bbigint)
+			ag1.FromJacobian(&aG1)
+			bg2.FromJacobian(&bG2)
+
+			var prepared G2Prepared
+			prepared.FromAffine(&bg2)
+			return MillerLoopFixedQ(ag1, &prepared).Equal(MillerLoop(ag1, bg2))
+		},
+		genR1,
+		genR2,
+	))
+
+	properties.Property("G2Prepared: SetBytes(Bytes()) should stay the same", prop.ForAll(
+		func(b fr.Element) bool {
+			var bbigint big.Int
+			var bG2 G2Jac
+			var bg2 G2Affine
+			b.ToBigIntRegular(&bbigint)
+			bG2.ScalarMultiplication(&g2GenAff, &bbigint)
+			bg2.FromJacobian(&bG2)
+
+			var prepared, decoded G2Prepared
+			prepared.FromAffine(&bg2)
+			buf := prepared.Bytes()
+			if len(buf) != SizeOfG2Prepared {
+				return false
+			}
+			if err := decoded.SetBytes(buf); err != nil {
+				return false
+			}
+			return decoded == prepared
+		},
+		genR2,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	var one PairingResult
+	one.SetOne()
+
+	var inf G2Affine
+	inf.FromJacobian(&g2Infinity)
+	var prepared, decoded G2Prepared
+	prepared.FromAffine(&inf)
+	if !MillerLoopFixedQ(g1GenAff, &prepared).Equal(&one) {
+		t.Fatal("MillerLoopFixedQ with Q at infinity should output 1")
+	}
+	buf := prepared.Bytes()
+	if err := decoded.SetBytes(buf); err != nil || decoded != prepared {
+		t.Fatal("G2Prepared at infinity: SetBytes(Bytes()) should stay the same")
+	}
+	buf[len(buf)-1] = 1
+	if err := decoded.SetBytes(buf); err != ErrInvalidInfinityEncoding {
+		t.Fatal("G2Prepared at infinity with non zero coefficients should be rejected")
+	}
+}
+
 // ------------------------------------------------------------
 // benches
 
@@ -121,6 +334,95 @@ func BenchmarkPairing(b *testing.B) {
 	}
 }
 
+func BenchmarkMillerLoop(b *testing.B) {
+
+	var g1GenAff G1Affine
+	var g2GenAff G2Affine
+
+	g1GenAff.FromJacobian(&g1Gen)
+	g2GenAff.FromJacobian(&g2Gen)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MillerLoop(g1GenAff, g2GenAff)
+	}
+}
+
+func BenchmarkMillerLoopFixedQ(b *testing.B) {
+
+	var g1GenAff G1Affine
+	var g2GenAff G2Affine
+
+	g1GenAff.FromJacobian(&g1Gen)
+	g2GenAff.FromJacobian(&g2Gen)
+
+	var prepared G2Prepared
+	prepared.FromAffine(&g2GenAff)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MillerLoopFixedQ(g1GenAff, &prepared)
+	}
+}
+
+func BenchmarkMultiPair(b *testing.B) {
+
+	var g1GenAff G1Affine
+	var g2GenAff G2Affine
+
+	g1GenAff.FromJacobian(&g1Gen)
+	g2GenAff.FromJacobian(&g2Gen)
+
+	n := 10
+	P := make([]G1Affine, n)
+	Q := make([]G2Affine, n)
+	for i := 0; i < n; i++ {
+		P[i] = g1GenAff
+		Q[i] = g2GenAff
+	}
+
+	for i := 2; i <= n; i += 2 {
+		b.Run(fmt.Sprintf("%d pairs", i), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				Pair(P[:i], Q[:i])
+			}
+		})
+	}
+}
+
+func BenchmarkMultiPairAffine(b *testing.B) {
+
+	var g1GenAff G1Affine
+	var g2GenAff G2Affine
+
+	g1GenAff.FromJacobian(&g1Gen)
+	g2GenAff.FromJacobian(&g2Gen)
+
+	n := 64
+	P := make([]G1Affine, n)
+	Q := make([]G2Affine, n)
+	for i := 0; i < n; i++ {
+		P[i] = g1GenAff
+		Q[i] = g2GenAff
+	}
+
+	for i := 2; i <= n; i *= 2 {
+		b.Run(fmt.Sprintf("%d pairs (jacobian)", i), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				MillerLoopMulti(P[:i], Q[:i])
+			}
+		})
+		b.Run(fmt.Sprintf("%d pairs (affine)", i), func(b *testing.B) {
+			b.ResetTimer()
+			for j := 0; j < b.N; j++ {
+				MillerLoopMulti(P[:i], Q[:i], AffineMillerLoop())
+			}
+		})
+	}
+}
+
 func BenchmarkFinalExponentiation(b *testing.B) {
 
 	var a E12
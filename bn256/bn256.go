@@ -22,6 +22,9 @@ var ID = gurvy.BN256
 // B b coeff of the curve
 var B fp.Element
 
+// bTwistCurveCoeff b coeff of the twist (defined over Fp2) curve
+var bTwistCurveCoeff E2
+
 // generators of the r-torsion group, resp. in ker(pi-id), ker(Tr)
 var g1Gen G1Jac
 var g2Gen G2Jac
@@ -42,17 +45,30 @@ var thirdRootOneG1 fp.Element
 var thirdRootOneG2 fp.Element
 var lambdaGLV big.Int
 
-// parameters for pippenger ScalarMulByGen
-// TODO get rid of this, keep only double and add, and the multi exp
-const sGen = 4
-const bGen = sGen
+// glvBasis is a reduced basis of the lattice {(a, b) | a + b*lambdaGLV = 0 mod r}, used to split
+// the scalars of the GLV scalar multiplication in two halves
+var glvBasis [2][2]big.Int
+
+// seed x of the curve
+var xGen big.Int
+
+// gtLambda eigenvalue of the Frobenius on GT: z**p = z**gtLambda
+var gtLambda big.Int
 
-var tGenG1 [((1 << bGen) - 1)]G1Jac
-var tGenG2 [((1 << bGen) - 1)]G2Jac
+// psi endomorphism on the twist: psi(x,y) = (conj(x)*psiFactorX, conj(y)*psiFactorY)
+var psiFactorX, psiFactorY E2
+
+// glsBasis is a reduced basis of the lattice {a | a[0] + a[1]*mu + a[2]*lambdaGLV + a[3]*mu*lambdaGLV = 0 mod r},
+// mu = gtLambda being the eigenvalue of psi on G2, used to split the scalars of the GLS scalar multiplication
+// in four quarters. glsBabai is r times the first row of glsBasis**-1
+var glsBasis [4][4]big.Int
+var glsBabai [4]big.Int
 
 func init() {
 
-	B.SetUint64(1)
+	B.SetUint64(3)
+	bTwistCurveCoeff.SetString("19485874751759354771024239261021720505790618469301721065564631296452457478373",
+		"266929791119991161246907387137283842545076965332900288569378510910307636690") // 3/(9+u)
 
 	g1Gen.X.SetString("20567171726433170376993012834626974355708098753738075953327671604980729474588")
 	g1Gen.Y.SetString("14259118686601658563517637559143782061303537174604067025175876803301021346267")
@@ -74,18 +90,60 @@ func init() {
 	thirdRootOneG2.Square(&thirdRootOneG1)
 	lambdaGLV.SetString("4407920970296243842393367215006156084916469457145843978461", 10)
 
+	glvBasis[0][0].SetString("147946756881789319010696353538189108491", 10)  // 6x**2+4x+1
+	glvBasis[0][1].SetString("9931322734385697763", 10)                      // 2x+1
+	glvBasis[1][0].SetString("9931322734385697763", 10)                      // 2x+1
+	glvBasis[1][1].SetString("-147946756881789319000765030803803410728", 10) // -(6x**2+2x)
+
 	// binary decomposition of 15132376222941642752 little endian
 	optimaAteLoop, _ := new(big.Int).SetString("29793968203157093288", 10)
 	utils.NafDecomposition(optimaAteLoop, loopCounter[:])
 
-	tGenG1[0].Set(&g1Gen)
-	for j := 1; j < len(tGenG1)-1; j = j + 2 {
-		tGenG1[j].Set(&tGenG1[j/2]).DoubleAssign()
-		tGenG1[j+1].Set(&tGenG1[(j+1)/2]).AddAssign(&tGenG1[j/2])
-	}
-	tGenG2[0].Set(&g2Gen)
-	for j := 1; j < len(tGenG2)-1; j = j + 2 {
-		tGenG2[j].Set(&tGenG2[j/2]).DoubleAssign()
-		tGenG2[j+1].Set(&tGenG2[(j+1)/2]).AddAssign(&tGenG2[j/2])
+	xGen.SetString("4965661367192848881", 10)
+	gtLambda.Mul(&xGen, &xGen).Mul(&gtLambda, big.NewInt(6)) // p = 6x**2 mod r
+
+	psiFactorX.SetString("21575463638280843010398324269430826099269044274347216827212613867836435027261",
+		"10307601595873709700152284273816112264069230130616436755625194854815875713954")
+	psiFactorY.SetString("2821565182194536844548159561693502659359617185244120367078079554186484126554",
+		"3505843767911556378687030309984248845540243509899259641013678093033130930403")
+
+	glsBasis[0][0].SetString("-9931322734385697763", 10) // -(2x+1)
+	glsBasis[0][2].SetString("9931322734385697762", 10)  // 2x
+	glsBasis[0][3].SetInt64(1)
+	glsBasis[1][0].SetString("9931322734385697762", 10)  // 2x
+	glsBasis[1][1].SetString("4965661367192848882", 10)  // x+1
+	glsBasis[1][2].SetString("4965661367192848881", 10)  // x
+	glsBasis[1][3].SetString("-4965661367192848881", 10) // -x
+	glsBasis[2][0].SetString("-4965661367192848882", 10) // -(x+1)
+	glsBasis[2][1].SetString("-4965661367192848881", 10) // -x
+	glsBasis[2][2].SetString("4965661367192848881", 10)  // x
+	glsBasis[2][3].SetString("-9931322734385697762", 10) // -2x
+	glsBasis[3][0].SetString("9931322734385697763", 10)  // 2x+1
+	glsBasis[3][1].SetString("-4965661367192848881", 10) // -x
+	glsBasis[3][2].SetString("4965661367192848882", 10)  // x+1
+	glsBasis[3][3].SetString("4965661367192848881", 10)  // x
+
+	glsBabai[0].SetString("-734653495049373973806201247608587340319794091592875701774", 10) // -(6x**3+6x**2+2x)
+	glsBabai[1].SetString("734653495049373973658254490726798021314063399421879442165", 10)  // 6x**3-x
+	glsBabai[2].SetString("-9931322734385697763", 10)                                       // -(2x+1)
+	glsBabai[3].SetString("734653495049373973806201247608587340314828430225682852893", 10)  // 6x**3+6x**2+x
+}
+
+// Generators returns the generators of G1 and G2 used in this package, in jacobian and affine coordinates
+func Generators() (g1Jac G1Jac, g2Jac G2Jac, g1Aff G1Affine, g2Aff G2Affine) {
+	g1Jac.Set(&g1Gen)
+	g2Jac.Set(&g2Gen)
+	g1Aff.FromJacobian(&g1Gen)
+	g2Aff.FromJacobian(&g2Gen)
+	return
+}
+
+// CurveParams returns the parameters of the curve: field moduli, cofactors, embedding degree,
+// twist type and seed (cf gurvy.CurveParams)
+func CurveParams() gurvy.Params {
+	params, err := gurvy.CurveParams(ID)
+	if err != nil {
+		panic(err) // ID is always a known curve
 	}
+	return params
 }
@@ -17,9 +17,12 @@ limitations under the License.
 package twistededwards
 
 import (
+	"crypto/subtle"
+	"math/big"
 	"math/bits"
 
 	"github.com/consensys/gurvy/bn256/fr"
+	"github.com/consensys/gurvy/utils"
 )
 
 // Point point on a twisted Edwards curve
@@ -140,11 +143,11 @@ func (p *PointProj) Add(p1, p2 *PointProj) *PointProj {
 	res.X.Mul(&H, &I).
 		Sub(&res.X, &C).
 		Sub(&res.X, &D).
-		Mul(&res.X, &p1.Z).
+		Mul(&res.X, &A).
 		Mul(&res.X, &F)
 	H.Mul(&ecurve.A, &C)
 	res.Y.Sub(&D, &H).
-		Mul(&res.Y, &p.Z).
+		Mul(&res.Y, &A).
 		Mul(&res.Y, &G)
 	res.Z.Mul(&F, &G)
 
@@ -210,3 +213,56 @@ func (p *Point) ScalarMul(p1 *Point, scalar fr.Element) *Point {
 
 	return p
 }
+
+// ScalarMulCT scalar multiplication of a point in constant time, for secret scalars
+// p1 points on the twisted Edwards curve
+// scal scalar NOT in Montgomery form
+// It runs a fixed window method on ceil(fr.Bits/4) windows with constant time table lookups; the projective
+// addition being complete, there is no exceptional case. Z is inverted with a fixed exponentiation (Fermat)
+// modifies p
+func (p *Point) ScalarMulCT(p1 *Point, scalar fr.Element) *Point {
+
+	const w = 4
+	const nbWindows = (fr.Bits + w - 1) / w
+
+	// table[i] = [i]p1
+	var table [1 << w]PointProj
+	table[0].X.SetZero()
+	table[0].Y.SetOne()
+	table[0].Z.SetOne()
+	table[1].FromAffine(p1)
+	for i := 2; i < len(table); i++ {
+		table[i].Add(&table[i-1], &table[1])
+	}
+
+	var resProj, tmp PointProj
+	resProj.lookupCT(table[:], utils.Window(scalar[:], w*(nbWindows-1), w))
+	for i := nbWindows - 2; i >= 0; i-- {
+		for j := 0; j < w; j++ {
+			resProj.Double(&resProj)
+		}
+		tmp.lookupCT(table[:], utils.Window(scalar[:], uint(w*i), w))
+		resProj.Add(&resProj, &tmp)
+	}
+
+	// 1/Z = Z**(q-2)
+	var zInv fr.Element
+	var e big.Int
+	e.Sub(fr.Modulus(), big.NewInt(2))
+	zInv.Exp(resProj.Z, &e)
+	p.X.Mul(&resProj.X, &zInv)
+	p.Y.Mul(&resProj.Y, &zInv)
+
+	return p
+}
+
+// lookupCT sets p to table[idx] by scanning the whole table, and returns p
+func (p *PointProj) lookupCT(table []PointProj, idx uint64) *PointProj {
+	for i := range table {
+		cond := uint64(subtle.ConstantTimeEq(int32(i), int32(idx)))
+		utils.CondCopy(p.X[:], table[i].X[:], cond)
+		utils.CondCopy(p.Y[:], table[i].Y[:], cond)
+		utils.CondCopy(p.Z[:], table[i].Z[:], cond)
+	}
+	return p
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package twistededwards
 
 import (
+	"math"
 	"testing"
 
 	"github.com/consensys/gurvy/bn256/fr"
+	"github.com/consensys/gurvy/internal/timing"
 )
 
 func TestAdd(t *testing.T) {
@@ -150,3 +152,82 @@ func TestScalarMul(t *testing.T) {
 	}
 
 }
+
+func TestScalarMulCT(t *testing.T) {
+
+	// set curve parameters
+	ed := GetEdwardsCurve()
+
+	var scalars [4]fr.Element
+	scalars[1].SetUint64(23902374).FromMont()
+	scalars[2].SetOne().Neg(&scalars[2]).FromMont()
+	scalars[3].SetRandom().FromMont()
+
+	for i := range scalars {
+		var p1, p2 Point
+		p1.ScalarMul(&ed.Base, scalars[i])
+		p2.ScalarMulCT(&ed.Base, scalars[i])
+		if !p1.X.Equal(&p2.X) || !p1.Y.Equal(&p2.Y) {
+			t.Fatal("ScalarMulCT and ScalarMul should output the same result")
+		}
+	}
+
+	// the projective addition with a distinct receiver
+	var p1, p2, res PointProj
+	var p3, p4 Point
+	p1.FromAffine(&ed.Base)
+	p2.Double(&p1)
+	res.Add(&p2, &p1)
+	p3.FromProj(&res)
+	scalars[0].SetUint64(3).FromMont()
+	p4.ScalarMul(&ed.Base, scalars[0])
+	if !p3.X.Equal(&p4.X) || !p3.Y.Equal(&p4.Y) {
+		t.Fatal("PointProj.Add should not depend on the receiver")
+	}
+}
+
+func TestScalarMulCTTiming(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the timing test in short mode")
+	}
+
+	const nbMeasures = 1000
+
+	// fix-vs-random: class 0 multiplies by 1, class 1 by a random scalar
+	ed := GetEdwardsCurve()
+	var res Point
+	var one fr.Element
+	one.SetOne().FromMont()
+	scalars := make([]fr.Element, nbMeasures+2)
+	for i := range scalars {
+		scalars[i].SetRandom().FromMont()
+	}
+
+	// the double and add leaks the scalar: this checks that the harness detects it
+	i := 0
+	tDoubleAndAdd := timing.WelchT(nbMeasures, func(class int) {
+		s := one
+		if class == 1 {
+			s = scalars[i]
+		}
+		i++
+		res.ScalarMul(&ed.Base, s)
+	})
+	if math.Abs(tDoubleAndAdd) < timing.Threshold {
+		t.Fatalf("the timings of ScalarMul should depend on the scalar (t = %.2f)", tDoubleAndAdd)
+	}
+
+	i = 0
+	tCT := timing.WelchT(nbMeasures, func(class int) {
+		s := one
+		if class == 1 {
+			s = scalars[i]
+		}
+		i++
+		res.ScalarMulCT(&ed.Base, s)
+	})
+	t.Logf("t statistic: %.2f (ScalarMul), %.2f (ScalarMulCT)", tDoubleAndAdd, tCT)
+	if math.Abs(tCT) > timing.Threshold {
+		t.Fatalf("the timings of ScalarMulCT should not depend on the scalar (t = %.2f)", tCT)
+	}
+}
@@ -38,6 +38,9 @@ var ID = gurvy.BW761
 // B b coeff of the curve
 var B fp.Element
 
+// bTwistCurveCoeff b coeff of the twist (defined over Fp) curve
+var bTwistCurveCoeff fp.Element
+
 // generators of the r-torsion group, resp. in ker(pi-id), ker(Tr)
 var g1Gen G1Jac
 var g2Gen G2Jac
@@ -49,20 +52,47 @@ var g2Infinity G2Jac
 // optimal Ate loop counters
 // Miller loop 1: f(P), div(f) = (x+1)(Q)-([x+1]Q)-x(O)
 // Miller loop 2: f(P), div(f) = (x**3-x**2-x)(Q) -([x**3-x**2-x]Q)-(x**3-x**2-x-1)(O)
+// Miller loop 2 of AteVariant2: f(P), div(f) = (x**3-x**2)(Q) -([x**3-x**2]Q)-(x**3-x**2-1)(O)
 var loopCounter1 [64]int8
 var loopCounter2 [127]int8
-
-// parameters for pippenger ScalarMulByGen
-// TODO get rid of this, keep only double and add, and the multi exp
-const sGen = 4
-const bGen = sGen
-
-var tGenG1 [((1 << bGen) - 1)]G1Jac
-var tGenG2 [((1 << bGen) - 1)]G2Jac
+var loopCounter2Variant2 [127]int8
+
+// Parameters useful for the GLV scalar multiplication. The third roots define the
+//  endomorphisms phi1 and phi2 for <G1> and <G2>. lambda is such that <r, phi-lambda> lies above
+// <r> in the ring Z[phi]. More concretely it's the associated eigenvalue
+// of phi1 (resp phi2) restricted to <G1> (resp <G2>)
+// cf https://www.cosic.esat.kuleuven.be/nessie/reports/phase2/GLV.pdf
+var thirdRootOneG1 fp.Element
+var thirdRootOneG2 fp.Element
+var lambdaGLV big.Int
+
+// glvBasis is a reduced basis of the lattice {(a, b) | a + b*lambdaGLV = 0 mod r}, used to split
+// the scalars of the GLV scalar multiplication in two halves
+var glvBasis [2][2]big.Int
+
+// seed x of the curve
+var xGen big.Int
+
+// gtLambda eigenvalue of the Frobenius on GT: z**p = z**gtLambda
+// gtLattice is a reduced basis of {(a, b) : a + b*gtLambda = 0 mod r}, used to split exponents on GT
+var gtLambda big.Int
+var gtLattice [2][2]big.Int
+
+// short vectors (a0, a1) of the lattice {(a0, a1) | a0 + a1*lambda = 0 mod r}, used in the
+// subgroup membership tests [a0]P + phi([a1]P) = 0, lambda being the eigenvalue of phi1 (resp phi2)
+var subGroupCheckG1 [2]big.Int
+var subGroupCheckG2 [2]big.Int
+
+// coefficients (in increasing powers of x) of polynomials a0, a1 such that a0(x) + a1(x)*phi1 (resp phi2)
+// equals 3*(pi-1)/rho in Z[phi], pi being the Frobenius and rho the prime above r. It annihilates
+// the cofactor part of E(Fp) (resp E'(Fp)) and is used to clear the cofactor
+var cofactorCleaningG1 = [2][4]int64{{16, 20, 7, -7}, {-10, 19, 17, -20}}
+var cofactorCleaningG2 = [2][4]int64{{23, 13, -7, 7}, {4, 26, 10, -13}}
 
 func init() {
 
 	B.SetOne().Neg(&B)
+	bTwistCurveCoeff.SetUint64(4)
 
 	g1Gen.X.SetString("5492337019202608651620810666633622531924946248948182754748114963334556774714407693672822645637243083342924475378144397780999025266189779523629084326871556483802038026432771927197170911996417793635501066231650458516636932478125208")
 	g1Gen.Y.SetString("4874298780810344118673004453041997030286302865034758641338313952140849332867290574388366379298818956144982860224857872858166812124104845663394852158352478303048122861831479086904887356602146134586313962565783961814162269209043907")
@@ -75,8 +105,19 @@ func init() {
 	//binary decomposition of 9586122913090633729, little endian
 	loopCounter1 = [64]int8{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 1}
 
+	thirdRootOneG1.SetString("1968985824090209297278610739700577151397666382303825728450741611566800370218827257750865013421937292370006175842381275743914023380727582819905021229583192207421122272650305267822868639090213645505120388400344940985710520836292650")
+	thirdRootOneG2.Square(&thirdRootOneG1)
+	lambdaGLV.SetString("80949648264912719408558363140637477264845294720710499478137287262712535938301461879813459410945", 10) // (x**5-3x**4+3x**3-x+1)
+
+	glvBasis[0][0].SetString("587269870971281361444171168277668240640243801025419411456", 10)  // (2x**3-2x**2-x+1)/3
+	glvBasis[0][1].SetString("293634935485640680722085584138834120315328839056164388863", 10)  // (x**3-x**2-2x-1)/3
+	glvBasis[1][0].SetString("293634935485640680722085584138834120315328839056164388863", 10)  // (x**3-x**2-2x-1)/3
+	glvBasis[1][1].SetString("-293634935485640680722085584138834120324914961969255022593", 10) // -(x**3-x**2+x+2)/3
+
 	T, _ := new(big.Int).SetString("91893752504881257691937156713741811711", 10)
 	utils.NafDecomposition(T, loopCounter2[:])
+	T.SetString("91893752504881257691937156713741811712", 10) // x**2-x
+	utils.NafDecomposition(T, loopCounter2Variant2[:])
 	// fmt.Print("[")
 	// for _, v := range loopCounter2 {
 	// 	fmt.Printf("%d,", v)
@@ -89,16 +130,37 @@ func init() {
 	g2Infinity.X.SetOne()
 	g2Infinity.Y.SetOne()
 
-	tGenG1[0].Set(&g1Gen)
-	for j := 1; j < len(tGenG1)-1; j = j + 2 {
-		tGenG1[j].Set(&tGenG1[j/2]).DoubleAssign()
-		tGenG1[j+1].Set(&tGenG1[(j+1)/2]).AddAssign(&tGenG1[j/2])
-	}
-	tGenG2[0].Set(&g2Gen)
-	for j := 1; j < len(tGenG2)-1; j = j + 2 {
-		tGenG2[j].Set(&tGenG2[j/2]).DoubleAssign()
-		tGenG2[j+1].Set(&tGenG2[(j+1)/2]).AddAssign(&tGenG2[j/2])
+	xGen.SetString("9586122913090633729", 10)
+	gtLambda.SetString("80949648264912719408558363140637477264845294720710499478137287262712535938301461879813459410946", 10) // p mod r
+
+	gtLattice[0][0].SetString("293634935485640680722085584138834120324914961969255022593", 10) // (x**3-x**2+x+2)/3
+	gtLattice[0][1].SetString("293634935485640680722085584138834120315328839056164388863", 10) // (x**3-x**2-2x-1)/3
+	gtLattice[1][0].Set(&gtLattice[0][1])
+	gtLattice[1][1].SetString("-587269870971281361444171168277668240640243801025419411456", 10) // -(2x**3-2x**2-x+1)/3
+
+	subGroupCheckG1[0].SetString("9586122913090633730", 10)                                       // x+1
+	subGroupCheckG1[1].SetString("880904806456922042166256752416502360965158762994674434049", 10) // x**3-x**2+1
+	subGroupCheckG2[0].SetString("293634935485640680722085584138834120324914961969255022593", 10) // (x**3-x**2+x+2)/3
+	subGroupCheckG2[1].SetString("587269870971281361444171168277668240640243801025419411456", 10) // (2x**3-2x**2-x+1)/3
+}
+
+// Generators returns the generators of G1 and G2 used in this package, in jacobian and affine coordinates
+func Generators() (g1Jac G1Jac, g2Jac G2Jac, g1Aff G1Affine, g2Aff G2Affine) {
+	g1Jac.Set(&g1Gen)
+	g2Jac.Set(&g2Gen)
+	g1Aff.FromJacobian(&g1Gen)
+	g2Aff.FromJacobian(&g2Gen)
+	return
+}
+
+// CurveParams returns the parameters of the curve: field moduli, cofactors, embedding degree,
+// twist type and seed (cf gurvy.CurveParams)
+func CurveParams() gurvy.Params {
+	params, err := gurvy.CurveParams(ID)
+	if err != nil {
+		panic(err) // ID is always a known curve
 	}
+	return params
 }
 
 // // E: y**2=x**3-1
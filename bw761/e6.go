@@ -14,7 +14,12 @@
 
 package bw761
 
-import "math/big"
+import (
+	"io"
+	"math/big"
+
+	"github.com/consensys/gurvy/bw761/fp"
+)
 
 // E6 is a degree-three finite field extension of fp2
 type E6 struct {
@@ -27,6 +32,11 @@ func (z *E6) Equal(x *E6) bool {
 	return z.B0.Equal(&x.B0) && z.B1.Equal(&x.B1) && z.B2.Equal(&x.B2)
 }
 
+// IsZero returns true if z is 0
+func (z *E6) IsZero() bool {
+	return z.B0.IsZero() && z.B1.IsZero() && z.B2.IsZero()
+}
+
 // SetString sets a E6 elmt from stringf
 func (z *E6) SetString(s1, s2, s3, s4, s5, s6 string) *E6 {
 	z.B0.SetString(s1, s2)
@@ -159,6 +169,45 @@ func (z *E6) MulByE2(x *E6, y *E2) *E6 {
 	return z
 }
 
+// MulBy01 sets z to x*(c0 + c1*v), returns z
+// (multiplication by a sparse element, 5 E2 multiplications)
+func (z *E6) MulBy01(x *E6, c0, c1 *E2) *E6 {
+	var a, b, t0, t1, t2, tmp E2
+	a.Mul(&x.B0, c0)
+	b.Mul(&x.B1, c1)
+
+	// t0 = x0*c0 + u*x2*c1
+	t0.Mul(&x.B2, c1).MulByNonResidue(&t0).Add(&t0, &a)
+
+	// t1 = (x0 + x1)*(c0 + c1) - x0*c0 - x1*c1
+	t1.Add(c0, c1)
+	tmp.Add(&x.B0, &x.B1)
+	t1.Mul(&t1, &tmp).Sub(&t1, &a).Sub(&t1, &b)
+
+	// t2 = x2*c0 + x1*c1
+	t2.Mul(&x.B2, c0).Add(&t2, &b)
+
+	z.B0.Set(&t0)
+	z.B1.Set(&t1)
+	z.B2.Set(&t2)
+	return z
+}
+
+// Mul01By01 sets z to the product of the sparse elements (c0 + c1*v) and (d0 + d1*v), returns z
+func (z *E6) Mul01By01(c0, c1, d0, d1 *E2) *E6 {
+	var x00, x11, x01, tmp E2
+	x00.Mul(c0, d0)
+	x11.Mul(c1, d1)
+	x01.Add(c0, c1)
+	tmp.Add(d0, d1)
+	x01.Mul(&x01, &tmp).Sub(&x01, &x00).Sub(&x01, &x11)
+
+	z.B0.Set(&x00)
+	z.B1.Set(&x01)
+	z.B2.Set(&x11)
+	return z
+}
+
 // Square sets z to the E6-product of x,x, returns z
 func (z *E6) Square(x *E6) *E6 {
 
@@ -254,3 +303,40 @@ func (z *E6) Exp(x *E6, e big.Int) *E6 {
 	z.Set(&res)
 	return z
 }
+
+// SizeOfGT represents the size in bytes that a GT element needs in binary form
+const SizeOfGT = fp.Limbs * 8 * 6
+
+// Bytes returns the regular (non montgomery) value of z as a big-endian byte array,
+// the fp.Element coordinates are stored in order B0.A0, B0.A1, B1.A0, ..., B2.A1
+func (z *E6) Bytes() (r [SizeOfGT]byte) {
+	const fpSize = fp.Limbs * 8
+	for i, e := range z.coordinates() {
+		copy(r[i*fpSize:(i+1)*fpSize], e.Bytes())
+	}
+	return
+}
+
+// SetBytes interprets e as the bytes of a big-endian E6 (see Bytes)
+// it returns an error if a coordinate is not reduced modulo q
+func (z *E6) SetBytes(e []byte) error {
+	if len(e) < SizeOfGT {
+		return io.ErrShortBuffer
+	}
+	const fpSize = fp.Limbs * 8
+	var res E6
+	for i, c := range res.coordinates() {
+		if err := setElementBytes(c, e[i*fpSize:(i+1)*fpSize]); err != nil {
+			return err
+		}
+	}
+	z.Set(&res)
+	return nil
+}
+
+// coordinates returns pointers to the fp.Element coordinates of z
+func (z *E6) coordinates() [6]*fp.Element {
+	return [6]*fp.Element{
+		&z.B0.A0, &z.B0.A1, &z.B1.A0, &z.B1.A1, &z.B2.A0, &z.B2.A1,
+	}
+}
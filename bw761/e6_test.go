@@ -156,6 +156,50 @@ func TestE6ReceiverIsOperand(t *testing.T) {
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
+func TestE6MulBySparse(t *testing.T) {
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	genA := GenE6()
+	genB := GenE2()
+	genC := GenE2()
+
+	properties.Property("MulBy01 should match Mul", prop.ForAll(
+		func(a *E6, c0, c1 *E2) bool {
+			var b, c E6
+			c.B0.Set(c0)
+			c.B1.Set(c1)
+			b.Mul(a, &c)
+			a.MulBy01(a, c0, c1)
+			return a.Equal(&b)
+		},
+		genA,
+		genB,
+		genC,
+	))
+
+	properties.Property("Mul01By01 should match Mul", prop.ForAll(
+		func(a *E6, c0, c1 *E2) bool {
+			var b, c, d E6
+			c.B0.Set(c0)
+			c.B1.Set(c1)
+			d.B0.Set(&a.B0)
+			d.B1.Set(&a.B1)
+			b.Mul(&c, &d)
+			d.Mul01By01(c0, c1, &a.B0, &a.B1)
+			return d.Equal(&b)
+		},
+		genA,
+		genB,
+		genC,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
 func TestE6State(t *testing.T) {
 
 	parameters := gopter.DefaultTestParameters()
@@ -334,6 +378,24 @@ func TestE6State(t *testing.T) {
 		},
 	}
 
+	setbytes := &commands.ProtoCommand{
+		Name: "SETBYTES",
+		RunFunc: func(systemUnderTest commands.SystemUnderTest) commands.Result {
+			var a E6
+			b := systemUnderTest.(*E6).Bytes()
+			if err := a.SetBytes(b[:]); err != nil {
+				return false
+			}
+			return systemUnderTest.(*E6).Equal(&a)
+		},
+		PostConditionFunc: func(state commands.State, result commands.Result) *gopter.PropResult {
+			if result.(bool) {
+				return &gopter.PropResult{Status: gopter.PropTrue}
+			}
+			return &gopter.PropResult{Status: gopter.PropFalse}
+		},
+	}
+
 	e6commands := &commands.ProtoCommands{
 		NewSystemUnderTestFunc: func(_ commands.State) commands.SystemUnderTest {
 			var a E6
@@ -342,7 +404,7 @@ func TestE6State(t *testing.T) {
 		},
 		InitialStateGen: gen.Const(false),
 		GenCommandFunc: func(state commands.State) gopter.Gen {
-			return gen.OneConstOf(subadd, mulinverse, inversetwice, negtwice, squaremul, doubleadd, frobenius, frobeniussquare, frobeniuscube, cyclotomicsquare)
+			return gen.OneConstOf(subadd, mulinverse, inversetwice, negtwice, squaremul, doubleadd, frobenius, frobeniussquare, frobeniuscube, cyclotomicsquare, setbytes)
 		},
 	}
 
@@ -385,6 +447,18 @@ func BenchmarkE6Mul(b *testing.B) {
 	}
 }
 
+func BenchmarkE6MulBy01(b *testing.B) {
+	var a E6
+	var c0, c1 E2
+	a.SetRandom()
+	c0.SetRandom()
+	c1.SetRandom()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.MulBy01(&a, &c0, &c1)
+	}
+}
+
 func BenchmarkE6Square(b *testing.B) {
 	var a E6
 	a.SetRandom()
@@ -0,0 +1,212 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bw761
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gurvy/bw761/fr"
+	"github.com/consensys/gurvy/utils"
+)
+
+// GT target group of the pairing: the subgroup of order r of the cyclotomic subgroup of E6
+//
+// A GT element is obtained from Pair, from FinalExponentiation or from SetE6 / SetBytes, which check
+// the subgroup membership, so that an arbitrary E6 can not be mistaken for a pairing output.
+// The zero value of GT is the identity
+type GT struct {
+	e E6 // the zero E6 stands for the identity, cf value
+}
+
+// ErrNotInGT is returned when an E6 element is not in the target group of the pairing
+var ErrNotInGT = errors.New("invalid GT element: not in the subgroup of order r of the cyclotomic subgroup")
+
+// FinalExponentiation sets z to the final exponentiation of x (cf PairingResult.FinalExponentiation), returns z
+func (z *GT) FinalExponentiation(x *PairingResult) *GT {
+	z.e.FinalExponentiation(x)
+	return z
+}
+
+// SetE6 sets z to x and returns nil if x is in GT, ErrNotInGT otherwise (z is left unchanged)
+func (z *GT) SetE6(x *E6) error {
+	if !x.IsInSubGroup() {
+		return ErrNotInGT
+	}
+	z.e.Set(x)
+	return nil
+}
+
+// E6 returns the value of z in E6
+func (z *GT) E6() E6 {
+	return z.value()
+}
+
+// value returns the value of z in E6, the identity if z.e is zero (zero value of GT)
+func (z *GT) value() E6 {
+	var res E6
+	if z.e.Equal(&res) {
+		res.SetOne()
+		return res
+	}
+	return z.e
+}
+
+// Set sets z to x, returns z
+func (z *GT) Set(x *GT) *GT {
+	z.e.Set(&x.e)
+	return z
+}
+
+// SetOne sets z to the identity of GT, returns z
+func (z *GT) SetOne() *GT {
+	z.e.SetOne()
+	return z
+}
+
+// IsOne returns true if z is the identity of GT
+func (z *GT) IsOne() bool {
+	var one E6
+	one.SetOne()
+	e := z.value()
+	return e.Equal(&one)
+}
+
+// Equal returns true if z equals x
+func (z *GT) Equal(x *GT) bool {
+	a, b := z.value(), x.value()
+	return a.Equal(&b)
+}
+
+// Mul sets z to x*y, returns z
+func (z *GT) Mul(x, y *GT) *GT {
+	a, b := x.value(), y.value()
+	z.e.Mul(&a, &b)
+	return z
+}
+
+// Square sets z to x**2, returns z
+func (z *GT) Square(x *GT) *GT {
+	a := x.value()
+	z.e.CyclotomicSquare(&a)
+	return z
+}
+
+// Inverse sets z to x**-1, returns z
+// (GT elements have an order dividing p**3+1, the inverse is Frob**3)
+func (z *GT) Inverse(x *GT) *GT {
+	a := x.value()
+	z.e.FrobeniusCube(&a)
+	return z
+}
+
+// Exp sets z to x**k, returns z
+//
+// k is split as k = k0 + k1*gtLambda mod r with |k0|, |k1| ~ sqrt(r) using the reduced basis gtLattice,
+// so that x**k = x**k0 * Frob(x)**k1 is computed with half the squarings (Straus-Shamir trick)
+func (z *GT) Exp(x *GT, k *fr.Element) *GT {
+
+	var s big.Int
+	k.ToBigIntRegular(&s)
+
+	var digits [2]big.Int
+	utils.SplitScalar(&s, &gtLattice, &digits)
+
+	var bases [2]E6
+	bases[0] = x.value()
+	bases[1].Frobenius(&bases[0])
+
+	z.e.expJoint(&bases, &digits)
+	return z
+}
+
+// expJoint sets z to bases[0]**digits[0] * bases[1]**digits[1], returns z
+// bases must be in the cyclotomic subgroup, digits may be negative
+func (z *E6) expJoint(bases *[2]E6, digits *[2]big.Int) *E6 {
+
+	var table [4]E6
+	var d [2]big.Int
+	table[0].SetOne()
+	for i := 0; i < 2; i++ {
+		d[i].Abs(&digits[i])
+		table[1<<uint(i)].Set(&bases[i])
+		if digits[i].Sign() < 0 {
+			table[1<<uint(i)].FrobeniusCube(&table[1<<uint(i)])
+		}
+	}
+	table[3].Mul(&table[1], &table[2])
+
+	n := d[0].BitLen()
+	if d[1].BitLen() > n {
+		n = d[1].BitLen()
+	}
+
+	var res E6
+	res.SetOne()
+	for i := n - 1; i >= 0; i-- {
+		res.CyclotomicSquare(&res)
+		if idx := d[0].Bit(i) | d[1].Bit(i)<<1; idx != 0 {
+			res.Mul(&res, &table[idx])
+		}
+	}
+
+	z.Set(&res)
+	return z
+}
+
+// Bytes returns the binary encoding of z (cf E6.Bytes)
+func (z *GT) Bytes() [SizeOfGT]byte {
+	e := z.value()
+	return e.Bytes()
+}
+
+// SetBytes interprets e as the bytes of a GT element (cf E6.SetBytes)
+// it returns ErrNotInGT if the decoded element is not in GT
+func (z *GT) SetBytes(e []byte) error {
+	var res E6
+	if err := res.SetBytes(e); err != nil {
+		return err
+	}
+	return z.SetE6(&res)
+}
+
+// IsInCyclotomicSubGroup returns true if z is in the cyclotomic subgroup of E6,
+// of order p**2 - p + 1, i.e. if z != 0 and z * Frob**2(z) = Frob(z)
+func (z *E6) IsInCyclotomicSubGroup() bool {
+	if z.IsZero() {
+		return false
+	}
+	var a, b E6
+	b.Frobenius(z)
+	a.FrobeniusSquare(z).Mul(&a, z)
+	return a.Equal(&b)
+}
+
+// IsInSubGroup returns true if z is in GT, the subgroup of order r of the cyclotomic subgroup
+// For z in the cyclotomic subgroup, it checks that z**a0 * Frob(z)**b0 = 1 with (a0, b0) = gtLattice[0]:
+// the gcd of a0 + b0*p and p**2 - p + 1 is r, so this holds exactly for the elements of order r
+func (z *E6) IsInSubGroup() bool {
+	if !z.IsInCyclotomicSubGroup() {
+		return false
+	}
+	var bases [2]E6
+	bases[0].Set(z)
+	bases[1].Frobenius(z)
+
+	var res, one E6
+	res.expJoint(&bases, &gtLattice[0])
+	one.SetOne()
+	return res.Equal(&one)
+}
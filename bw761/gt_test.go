@@ -0,0 +1,202 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bw761
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gurvy/bw761/fr"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+// ------------------------------------------------------------
+// tests
+
+func TestGT(t *testing.T) {
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 20
+
+	properties := gopter.NewProperties(parameters)
+
+	genA := GenE6()
+	genR := GenFr()
+
+	properties.Property("FinalExponentiation should output an element of GT", prop.ForAll(
+		func(a *E6) bool {
+			var res GT
+			res.FinalExponentiation(a)
+			e := res.E6()
+			return e.IsInCyclotomicSubGroup() && e.IsInSubGroup()
+		},
+		genA,
+	))
+
+	properties.Property("a random E6 should not be in GT", prop.ForAll(
+		func(a *E6) bool {
+			var res GT
+			return !a.IsInCyclotomicSubGroup() && res.SetE6(a) == ErrNotInGT
+		},
+		genA,
+	))
+
+	properties.Property("the easy part of the final exponentiation should not output an element of GT", prop.ForAll(
+		func(a *E6) bool {
+			// a**((p**3-1)*(p+1)) is in the cyclotomic subgroup, of order p**2 - p + 1 = r*h
+			var b E6
+			b.Inverse(a)
+			a.FrobeniusCube(a).Mul(a, &b)
+			b.Frobenius(a)
+			a.Mul(a, &b)
+			return a.IsInCyclotomicSubGroup() && !a.IsInSubGroup()
+		},
+		genA,
+	))
+
+	properties.Property("Exp should match E6.Exp", prop.ForAll(
+		func(a *E6, k fr.Element) bool {
+			var x, res GT
+			var expected E6
+			var e big.Int
+			x.FinalExponentiation(a)
+			res.Exp(&x, &k)
+			k.ToBigIntRegular(&e)
+			expected.Exp(&x.e, e)
+			return res.e.Equal(&expected)
+		},
+		genA,
+		genR,
+	))
+
+	properties.Property("Exp by r-1 should output the inverse", prop.ForAll(
+		func(a *E6) bool {
+			var x, res, inv GT
+			var k fr.Element
+			x.FinalExponentiation(a)
+			k.SetOne().Neg(&k)
+			res.Exp(&x, &k)
+			inv.Inverse(&x)
+			return res.Equal(&inv) && res.Mul(&res, &x).IsOne()
+		},
+		genA,
+	))
+
+	properties.Property("Square should match Mul", prop.ForAll(
+		func(a *E6) bool {
+			var x, s, m GT
+			x.FinalExponentiation(a)
+			s.Square(&x)
+			m.Mul(&x, &x)
+			return s.Equal(&m)
+		},
+		genA,
+	))
+
+	properties.Property("the zero value of GT should be the identity", prop.ForAll(
+		func(a *E6) bool {
+			var zero, one, x, res GT
+			one.SetOne()
+			x.FinalExponentiation(a)
+			res.Mul(&x, &zero)
+			return zero.IsOne() && zero.Equal(&one) && one.Equal(&zero) &&
+				res.Equal(&x) && zero.Bytes() == one.Bytes()
+		},
+		genA,
+	))
+
+	properties.Property("SetBytes(Bytes()) should stay the same", prop.ForAll(
+		func(a *E6) bool {
+			var x, res GT
+			x.FinalExponentiation(a)
+			buf := x.Bytes()
+			if err := res.SetBytes(buf[:]); err != nil {
+				return false
+			}
+			return res.Equal(&x)
+		},
+		genA,
+	))
+
+	properties.Property("SetBytes should reject an element not in GT", prop.ForAll(
+		func(a *E6) bool {
+			var res GT
+			buf := a.Bytes()
+			return res.SetBytes(buf[:]) == ErrNotInGT
+		},
+		genA,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestGTIdentityEncoding(t *testing.T) {
+	var one, res GT
+	one.SetOne()
+	buf := one.Bytes()
+	if err := res.SetBytes(buf[:]); err != nil || !res.IsOne() {
+		t.Fatal("SetBytes(Bytes()) of the identity should stay the same")
+	}
+
+	// the zero E6 is not in GT, the identity has a single encoding
+	var zero [SizeOfGT]byte
+	if err := res.SetBytes(zero[:]); err != ErrNotInGT {
+		t.Fatal("SetBytes should reject the zero E6")
+	}
+	var e E6
+	if err := res.SetE6(&e); err != ErrNotInGT {
+		t.Fatal("SetE6 should reject the zero E6")
+	}
+}
+
+// ------------------------------------------------------------
+// benches
+
+func BenchmarkGTExp(b *testing.B) {
+	var a E6
+	var x, res GT
+	var k fr.Element
+	var e big.Int
+	a.SetRandom()
+	x.FinalExponentiation(&a)
+	k.SetRandom()
+	k.ToBigIntRegular(&e)
+
+	b.Run("GT.Exp", func(b *testing.B) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			res.Exp(&x, &k)
+		}
+	})
+
+	b.Run("E6.Exp", func(b *testing.B) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			a.Exp(&x.e, e)
+		}
+	})
+}
+
+func BenchmarkGTIsInSubGroup(b *testing.B) {
+	var a E6
+	var x GT
+	a.SetRandom()
+	x.FinalExponentiation(&a)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.e.IsInSubGroup()
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bw761
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gurvy/bw761/fp"
+)
+
+// test vectors of the BW6-761 G1 SVDW suites, generated by internal/hash_to_curve_vectors.py
+type hashToCurveVectors struct {
+	Dst     string `json:"dst"`
+	Vectors []struct {
+		Msg string           `json:"msg"`
+		P   hashToCurvePoint `json:"P"`
+		Q   hashToCurvePoint `json:"Q"`
+		Q0  hashToCurvePoint `json:"Q0"`
+		Q1  hashToCurvePoint `json:"Q1"`
+		U   []string         `json:"u"`
+	} `json:"vectors"`
+}
+
+type hashToCurvePoint struct {
+	X string `json:"x"`
+	Y string `json:"y"`
+}
+
+func readHashToCurveVectors(t *testing.T, suite string) hashToCurveVectors {
+	raw, err := ioutil.ReadFile("testdata/BW6761" + suite + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res hashToCurveVectors
+	if err := json.Unmarshal(raw, &res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func hexToFp(t *testing.T, s string) fp.Element {
+	var b big.Int
+	if _, ok := b.SetString(strings.TrimPrefix(s, "0x"), 16); !ok {
+		t.Fatal("invalid hex string", s)
+	}
+	var res fp.Element
+	res.SetBigInt(&b)
+	return res
+}
+
+func (p hashToCurvePoint) g1(t *testing.T) G1Affine {
+	return G1Affine{X: hexToFp(t, p.X), Y: hexToFp(t, p.Y)}
+}
+
+func TestHashToG1Vectors(t *testing.T) {
+	vectors := readHashToCurveVectors(t, "G1_XMD_SHA-256_SVDW_RO_")
+	for i, v := range vectors.Vectors {
+		u, err := hashToFp([]byte(v.Msg), []byte(vectors.Dst), 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := range u {
+			if expected := hexToFp(t, v.U[j]); !u[j].Equal(&expected) {
+				t.Fatal("vector", i, "hash_to_field mismatch")
+			}
+		}
+		q0, q1 := MapToG1(u[0]), MapToG1(u[1])
+		if expected := v.Q0.g1(t); !q0.Equal(&expected) {
+			t.Fatal("vector", i, "map_to_curve(u0) mismatch")
+		}
+		if expected := v.Q1.g1(t); !q1.Equal(&expected) {
+			t.Fatal("vector", i, "map_to_curve(u1) mismatch")
+		}
+		p, err := HashToG1([]byte(v.Msg), []byte(vectors.Dst))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := v.P.g1(t); !p.Equal(&expected) || !p.IsInSubGroup() {
+			t.Fatal("vector", i, "hash_to_curve mismatch")
+		}
+	}
+}
+
+func TestEncodeToG1Vectors(t *testing.T) {
+	vectors := readHashToCurveVectors(t, "G1_XMD_SHA-256_SVDW_NU_")
+	for i, v := range vectors.Vectors {
+		u, err := hashToFp([]byte(v.Msg), []byte(vectors.Dst), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := hexToFp(t, v.U[0]); !u[0].Equal(&expected) {
+			t.Fatal("vector", i, "hash_to_field mismatch")
+		}
+		if q, expected := MapToG1(u[0]), v.Q.g1(t); !q.Equal(&expected) {
+			t.Fatal("vector", i, "map_to_curve mismatch")
+		}
+		p, err := EncodeToG1([]byte(v.Msg), []byte(vectors.Dst))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := v.P.g1(t); !p.Equal(&expected) || !p.IsInSubGroup() {
+			t.Fatal("vector", i, "encode_to_curve mismatch")
+		}
+	}
+}
+
+func TestMapToCurveExceptionalCase(t *testing.T) {
+	// u**2 = ±1 / c1 hits the exceptional case of the SVDW map (tv3 = inv0(0))
+	var u fp.Element
+	u.Inverse(&svdwG1.c1)
+	if u.Sqrt(&u) == nil {
+		u.Inverse(&svdwG1.c1).Neg(&u)
+		u.Sqrt(&u)
+	}
+	if p := MapToG1(u); !p.IsOnCurve() {
+		t.Fatal("MapToG1(sqrt(±1/c1)) is not on the curve")
+	}
+}
+
+func BenchmarkHashToG1(b *testing.B) {
+	msg := []byte("abc")
+	dst := []byte("QUUX-V01-CS02-with-BW6761G1_XMD:SHA-256_SVDW_RO_")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		HashToG1(msg, dst)
+	}
+}
@@ -15,12 +15,94 @@
 package bw761
 
 import (
+	"errors"
+	"io"
+
 	"github.com/consensys/gurvy/bw761/fp"
 )
 
 // PairingResult target group of the pairing
 type PairingResult = E6
 
+// ErrPairingInputSize is returned by Pair and PairingCheck when P and Q have different lengths
+var ErrPairingInputSize = errors.New("invalid inputs: P and Q must have the same length")
+
+// PairingOption sets an option of MillerLoop, MillerLoopMulti, Pair and PairingCheck
+type PairingOption func(*pairingConfig)
+
+type pairingConfig struct {
+	affine  bool
+	variant AteVariant
+}
+
+// newPairingConfig returns the configuration set by options
+func newPairingConfig(options []PairingOption) pairingConfig {
+	var cfg pairingConfig
+	for _, option := range options {
+		option(&cfg)
+	}
+	return cfg
+}
+
+// AteVariant identifies one of the two optimal ate pairings of BW6-761, x being the seed of the curve
+// (cf https://eprint.iacr.org/2020/351.pdf). They are different (non-degenerate, bilinear) pairings:
+// one is a fixed power of the other.
+type AteVariant uint8
+
+const (
+	// AteVariant1 is the pairing (f_{x+1,Q}(P) * f_{x**3-x**2-x,Q}(P)**q)**((p**6-1)/r), it is the default
+	AteVariant1 AteVariant = iota
+
+	// AteVariant2 is the pairing (f_{x**3-x**2+1,Q}(P) * f_{x+1,Q}(P)**(-q))**((p**6-1)/r)
+	AteVariant2
+)
+
+// WithAteVariant selects the optimal ate pairing computed by the Miller loop
+func WithAteVariant(v AteVariant) PairingOption {
+	return func(cfg *pairingConfig) {
+		cfg.variant = v
+	}
+}
+
+// loopCounter2 returns the loop counter of the second part of the Miller loop of v,
+// run on [x]Q (cf loopCounter2, loopCounter2Variant2)
+func (v AteVariant) loopCounter2() []int8 {
+	if v == AteVariant2 {
+		return loopCounter2Variant2[:]
+	}
+	return loopCounter2[:]
+}
+
+// combine sets result to the Miller loop of v, from f(P) the output of the second part of
+// the Miller loop and g(P), div(g)=(x+1)(Q)-([x+1]Q)-x(O)
+// for AteVariant2, f(P) must already include the last line, through [x**3-x**2]Q and Q
+func (v AteVariant) combine(result, f, g *PairingResult) {
+	if v == AteVariant2 {
+		// f(P)*(g(P)**q)**-1, the inverse being replaced by the conjugate g**(p**3)
+		// as p**3+1 is a multiple of (p**2-p+1) and so (p**6-1)*(p**3+1)/r an exponent of the final expo
+		var buf PairingResult
+		buf.Frobenius(g).FrobeniusCube(&buf)
+		result.Mul(f, &buf)
+		return
+	}
+	// g(P)*(f(P)**q)
+	// div(g)=(x+1)(Q)-([x+1]Q)-x(O)
+	// div(f)=(x**3-x**2-x)(Q)-([x**3-x**2-x](Q)-(x**3-x**2-x-1)(O)
+	var buf PairingResult
+	buf.Frobenius(f)
+	result.Mul(&buf, g)
+}
+
+// AffineMillerLoop computes the Miller loop with the multiples of Q[i] in affine coordinates:
+// the slopes of the lines of all pairs share one batched inversion per doubling or addition step.
+// It outputs the same reduced pairing as the default (jacobian) Miller loop, but not the same
+// Miller loop value, and is faster when there are many pairs (from about 16 pairs).
+func AffineMillerLoop() PairingOption {
+	return func(cfg *pairingConfig) {
+		cfg.affine = true
+	}
+}
+
 type lineEvaluation struct {
 	r0 fp.Element
 	r1 fp.Element
@@ -167,14 +249,19 @@ func (z *PairingResult) FinalExponentiation(x *PairingResult) *PairingResult {
 }
 
 // MillerLoop Miller loop
-func MillerLoop(P G1Affine, Q G2Affine) *PairingResult {
+// it computes AteVariant1 unless another variant is selected (cf WithAteVariant)
+func MillerLoop(P G1Affine, Q G2Affine, options ...PairingOption) *PairingResult {
 
 	var result PairingResult
+	result.SetOne()
 
 	if P.IsInfinity() || Q.IsInfinity() {
 		return &result
 	}
 
+	cfg := newPairingConfig(options)
+	counter2 := cfg.variant.loopCounter2()
+
 	ch := make(chan struct{}, 213)
 
 	var evaluations1 [69]lineEvaluation
@@ -186,7 +273,6 @@ func MillerLoop(P G1Affine, Q G2Affine) *PairingResult {
 
 	// Miller loop part 1
 	// computes f(P), div(f)=x(Q)-([x]Q)-(x-1)(O)
-	result.SetOne()
 	go preCompute1(&evaluations1, &xQjac, &P, ch)
 	j := 0
 	for i := len(loopCounter1) - 2; i >= 0; i-- {
@@ -206,6 +292,7 @@ func MillerLoop(P G1Affine, Q G2Affine) *PairingResult {
 	// store mx=g(P), mxInv=1/g(P), div(g)=x(Q)-([x]Q)-(x-1)(O), because the second Miller loop
 	// computes f(P), div(f)=(x**3-x**2-x)(Q)-([x**3-x**2-x](Q)-(x**3-x**2-x-1)(O) and
 	// f(P)=g(P)**(u**2-u-1)*h(P), div(h)=(x**2-x-1)([x]Q)-([x**2-x-1][x]Q)-(x**2-x-2)(O)
+	// (resp. x**3-x**2 and x**2-x for AteVariant2)
 	var mx, mxInv, mxplusone PairingResult
 	mx.Set(&result)
 	mxInv.Inverse(&result)
@@ -217,20 +304,20 @@ func MillerLoop(P G1Affine, Q G2Affine) *PairingResult {
 
 	// Miller loop part 2 (xQjac = [x]Q)
 	// computes f(P), div(f)=(x**3-x**2-x)(Q)-([x**3-x**2-x](Q)-(x**3-x**2-x-1)(O)
-	go preCompute2(&evaluations2, &xQjac, &P, ch)
+	go preCompute2(evaluations2[:], counter2, &xQjac, &P, ch)
 	j = 0
-	for i := len(loopCounter2) - 2; i >= 0; i-- {
+	for i := len(counter2) - 2; i >= 0; i-- {
 
 		result.Square(&result)
 		<-ch
 		result.mulAssign(&evaluations2[j])
 		j++
 
-		if loopCounter2[i] == 1 {
+		if counter2[i] == 1 {
 			<-ch
 			result.mulAssign(&evaluations2[j]).MulAssign(&mx) // accumulate g(P), div(g)=x(Q)-([x]Q)-(x-1)(O)
 			j++
-		} else if loopCounter2[i] == -1 {
+		} else if counter2[i] == -1 {
 			<-ch
 			result.mulAssign(&evaluations2[j]).MulAssign(&mxInv) // accumulate g(P), div(g)=x(Q)-([x]Q)-(x-1)(O)
 			j++
@@ -239,17 +326,385 @@ func MillerLoop(P G1Affine, Q G2Affine) *PairingResult {
 
 	close(ch)
 
-	// g(P)*(f(P)**q)
-	// div(g)=(x+1)(Q)-([x+1]Q)-x(O)
-	// div(f)=(x**3-x**2-x)(Q)-([x**3-x**2-x](Q)-(x**3-x**2-x-1)(O)
-	result.Frobenius(&result).MulAssign(&mxplusone)
+	if cfg.variant == AteVariant2 {
+		// f(P), div(f)=(x**3-x**2+1)(Q)-([x**3-x**2+1]Q)-(x**3-x**2)(O) (drop the vertical line)
+		lineEval(&xQjac, &QjacSaved, &P, &lEval)
+		result.mulAssign(&lEval)
+	}
 
+	cfg.variant.combine(&result, &result, &mxplusone)
 	return &result
 }
 
+// Pair computes the reduced pairing e(P[0], Q[0]) * ... * e(P[n-1], Q[n-1])
+// with a single shared Miller loop and a single final exponentiation
+// pairs where P[i] or Q[i] is the point at infinity are skipped
+func Pair(P []G1Affine, Q []G2Affine, options ...PairingOption) (GT, error) {
+	var res GT
+	f, err := MillerLoopMulti(P, Q, options...)
+	if err != nil {
+		return res, err
+	}
+	res.FinalExponentiation(&f)
+	return res, nil
+}
+
+// PairingCheck returns true if e(P[0], Q[0]) * ... * e(P[n-1], Q[n-1]) == 1
+func PairingCheck(P []G1Affine, Q []G2Affine, options ...PairingOption) (bool, error) {
+	f, err := Pair(P, Q, options...)
+	if err != nil {
+		return false, err
+	}
+	return f.IsOne(), nil
+}
+
+// MillerLoopMulti computes the product of the Miller loops of the pairs (P[i], Q[i])
+// the accumulator is squared once per iteration for all pairs
+// pairs where P[i] or Q[i] is the point at infinity are skipped
+func MillerLoopMulti(P []G1Affine, Q []G2Affine, options ...PairingOption) (PairingResult, error) {
+
+	if len(P) != len(Q) {
+		var result PairingResult
+		result.SetOne()
+		return result, ErrPairingInputSize
+	}
+
+	cfg := newPairingConfig(options)
+
+	// filter out the points at infinity
+	p := make([]G1Affine, 0, len(P))
+	q := make([]G2Affine, 0, len(Q))
+	for i := range P {
+		if P[i].IsInfinity() || Q[i].IsInfinity() {
+			continue
+		}
+		p = append(p, P[i])
+		q = append(q, Q[i])
+	}
+
+	if cfg.affine {
+		return millerLoopAffine(p, q, cfg.variant), nil
+	}
+	return millerLoopJacobian(p, q, cfg.variant), nil
+}
+
+// millerLoopJacobian computes the product of the Miller loops of the pairs (p[k], q[k]), none at infinity
+// the multiples of q[k] are in jacobian coordinates
+func millerLoopJacobian(p []G1Affine, q []G2Affine, variant AteVariant) PairingResult {
+
+	var result PairingResult
+	result.SetOne()
+
+	n := len(p)
+
+	xQjac := make([]G2Jac, n)
+	qBuf := make([]G2Jac, n)
+	for k := 0; k < n; k++ {
+		xQjac[k].FromAffine(&q[k])
+		qBuf[k].FromAffine(&q[k])
+	}
+
+	// Miller loop part 1
+	// computes f(P), div(f)=x(Q)-([x]Q)-(x-1)(O), for all pairs
+	var Q1 G2Jac
+	lEval := make([]lineEvaluation, n)
+	for i := len(loopCounter1) - 2; i >= 0; i-- {
+
+		result.Square(&result)
+
+		for k := 0; k < n; k++ {
+			Q1.Set(&xQjac[k])
+			xQjac[k].Double(&Q1).Neg(&xQjac[k])
+			lineEval(&Q1, &xQjac[k], &p[k], &lEval[k]) // f(P), div(f) = 2(Q1)+(-2Q)-3(O)
+			xQjac[k].Neg(&xQjac[k])
+		}
+		result.mulLines(lEval)
+
+		if loopCounter1[i] == 1 {
+			for k := 0; k < n; k++ {
+				lineEval(&xQjac[k], &qBuf[k], &p[k], &lEval[k]) // f(P), div(f) = (Q)+(Qbuf)+(-Q-Qbuf)-3(O)
+				xQjac[k].AddAssign(&qBuf[k])
+			}
+			result.mulLines(lEval)
+		}
+	}
+
+	// mx and mxInv are the products over all pairs of g(P), 1/g(P), div(g)=x(Q)-([x]Q)-(x-1)(O)
+	var mx, mxInv, mxplusone PairingResult
+	mx.Set(&result)
+	mxInv.Inverse(&result)
+
+	// finishes the computation of g(P), div(g)=(x+1)(Q)-([x+1]Q)-x(O) (drop the vertical line)
+	mxplusone.Set(&mx)
+	for k := 0; k < n; k++ {
+		lineEval(&xQjac[k], &qBuf[k], &p[k], &lEval[k])
+	}
+	mxplusone.mulLines(lEval)
+
+	// Miller loop part 2 (xQjac = [x]Q)
+	// computes f(P), div(f)=(x**3-x**2-x)(Q)-([x**3-x**2-x](Q)-(x**3-x**2-x-1)(O), for all pairs
+	xQBuf := make([]G2Jac, n)
+	xQNeg := make([]G2Jac, n)
+	for k := 0; k < n; k++ {
+		xQBuf[k].Set(&xQjac[k])
+		xQNeg[k].Neg(&xQjac[k])
+	}
+	counter2 := variant.loopCounter2()
+	for i := len(counter2) - 2; i >= 0; i-- {
+
+		result.Square(&result)
+
+		for k := 0; k < n; k++ {
+			Q1.Set(&xQjac[k])
+			xQjac[k].Double(&Q1).Neg(&xQjac[k])
+			lineEval(&Q1, &xQjac[k], &p[k], &lEval[k]) // f(P), div(f) = 2(Q1)+(-2Q)-3(O)
+			xQjac[k].Neg(&xQjac[k])
+		}
+		result.mulLines(lEval)
+
+		if counter2[i] == 1 {
+			for k := 0; k < n; k++ {
+				lineEval(&xQjac[k], &xQBuf[k], &p[k], &lEval[k]) // f(P), div(f) = (Q)+(Qbuf)+(-Q-Qbuf)-3(O)
+				xQjac[k].AddAssign(&xQBuf[k])
+			}
+			result.mulLines(lEval)
+			result.MulAssign(&mx) // accumulate g(P), div(g)=x(Q)-([x]Q)-(x-1)(O)
+		} else if counter2[i] == -1 {
+			for k := 0; k < n; k++ {
+				lineEval(&xQjac[k], &xQNeg[k], &p[k], &lEval[k]) // f(P), div(f) = (Q)+(-Qbuf)+(-Q+Qbuf)-3(O)
+				xQjac[k].AddAssign(&xQNeg[k])
+			}
+			result.mulLines(lEval)
+			result.MulAssign(&mxInv) // accumulate g(P), div(g)=x(Q)-([x]Q)-(x-1)(O)
+		}
+	}
+
+	if variant == AteVariant2 {
+		// f(P), div(f)=(x**3-x**2+1)(Q)-([x**3-x**2+1]Q)-(x**3-x**2)(O) (drop the vertical line)
+		for k := 0; k < n; k++ {
+			lineEval(&xQjac[k], &qBuf[k], &p[k], &lEval[k])
+		}
+		result.mulLines(lEval)
+	}
+
+	variant.combine(&result, &result, &mxplusone)
+
+	return result
+}
+
+// millerLoopAffine computes the product of the Miller loops of the pairs (p[k], q[k]), none at infinity
+// the multiples of q[k] are in affine coordinates, the denominators of the slopes being inverted in a batch
+// the lines differ from those of millerLoopJacobian by fp factors, so the result only matches
+// millerLoopJacobian after the final exponentiation
+func millerLoopAffine(p []G1Affine, q []G2Affine, variant AteVariant) PairingResult {
+
+	var result PairingResult
+	result.SetOne()
+
+	n := len(p)
+	xQ := make([]G2Affine, n)
+	copy(xQ, q)
+
+	// Miller loop part 1
+	// computes f(P), div(f)=x(Q)-([x]Q)-(x-1)(O), for all pairs
+	den := make([]fp.Element, n)
+	lEval := make([]lineEvaluation, n)
+	for i := len(loopCounter1) - 2; i >= 0; i-- {
+
+		result.Square(&result)
+
+		for k := 0; k < n; k++ {
+			den[k].Double(&xQ[k].Y)
+		}
+		batchInvertFp(den)
+		for k := 0; k < n; k++ {
+			doubleStep(&xQ[k], &den[k], &p[k], &lEval[k]) // f(P), div(f) = 2(Q1)+(-2Q)-3(O)
+		}
+		result.mulLines(lEval)
+
+		if loopCounter1[i] == 1 {
+			for k := 0; k < n; k++ {
+				den[k].Sub(&q[k].X, &xQ[k].X)
+			}
+			batchInvertFp(den)
+			for k := 0; k < n; k++ {
+				addStep(&xQ[k], &q[k], &den[k], &p[k], &lEval[k]) // f(P), div(f) = (Q)+(Qbuf)+(-Q-Qbuf)-3(O)
+			}
+			result.mulLines(lEval)
+		}
+	}
+
+	// mx and mxInv are the products over all pairs of g(P), 1/g(P), div(g)=x(Q)-([x]Q)-(x-1)(O)
+	var mx, mxInv, mxplusone PairingResult
+	mx.Set(&result)
+	mxInv.Inverse(&result)
+
+	// finishes the computation of g(P), div(g)=(x+1)(Q)-([x+1]Q)-x(O) (drop the vertical line)
+	mxplusone.Set(&mx)
+	for k := 0; k < n; k++ {
+		den[k].Sub(&q[k].X, &xQ[k].X)
+	}
+	batchInvertFp(den)
+	var lambda fp.Element
+	for k := 0; k < n; k++ {
+		lambda.Sub(&q[k].Y, &xQ[k].Y).Mul(&lambda, &den[k])
+		lEval[k].affineLine(&xQ[k], &lambda, &p[k])
+	}
+	mxplusone.mulLines(lEval)
+
+	// Miller loop part 2 (xQ = [x]Q)
+	// computes f(P), div(f)=(x**3-x**2-x)(Q)-([x**3-x**2-x](Q)-(x**3-x**2-x-1)(O), for all pairs
+	xQBuf := make([]G2Affine, n)
+	xQNeg := make([]G2Affine, n)
+	for k := 0; k < n; k++ {
+		xQBuf[k].Set(&xQ[k])
+		xQNeg[k].Neg(&xQ[k])
+	}
+	counter2 := variant.loopCounter2()
+	for i := len(counter2) - 2; i >= 0; i-- {
+
+		result.Square(&result)
+
+		for k := 0; k < n; k++ {
+			den[k].Double(&xQ[k].Y)
+		}
+		batchInvertFp(den)
+		for k := 0; k < n; k++ {
+			doubleStep(&xQ[k], &den[k], &p[k], &lEval[k]) // f(P), div(f) = 2(Q1)+(-2Q)-3(O)
+		}
+		result.mulLines(lEval)
+
+		if counter2[i] == 1 {
+			for k := 0; k < n; k++ {
+				den[k].Sub(&xQBuf[k].X, &xQ[k].X)
+			}
+			batchInvertFp(den)
+			for k := 0; k < n; k++ {
+				addStep(&xQ[k], &xQBuf[k], &den[k], &p[k], &lEval[k]) // f(P), div(f) = (Q)+(Qbuf)+(-Q-Qbuf)-3(O)
+			}
+			result.mulLines(lEval)
+			result.MulAssign(&mx) // accumulate g(P), div(g)=x(Q)-([x]Q)-(x-1)(O)
+		} else if counter2[i] == -1 {
+			for k := 0; k < n; k++ {
+				den[k].Sub(&xQNeg[k].X, &xQ[k].X)
+			}
+			batchInvertFp(den)
+			for k := 0; k < n; k++ {
+				addStep(&xQ[k], &xQNeg[k], &den[k], &p[k], &lEval[k]) // f(P), div(f) = (Q)+(-Qbuf)+(-Q+Qbuf)-3(O)
+			}
+			result.mulLines(lEval)
+			result.MulAssign(&mxInv) // accumulate g(P), div(g)=x(Q)-([x]Q)-(x-1)(O)
+		}
+	}
+
+	if variant == AteVariant2 {
+		// f(P), div(f)=(x**3-x**2+1)(Q)-([x**3-x**2+1]Q)-(x**3-x**2)(O) (drop the vertical line)
+		for k := 0; k < n; k++ {
+			den[k].Sub(&q[k].X, &xQ[k].X)
+		}
+		batchInvertFp(den)
+		for k := 0; k < n; k++ {
+			lambda.Sub(&q[k].Y, &xQ[k].Y).Mul(&lambda, &den[k])
+			lEval[k].affineLine(&xQ[k], &lambda, &p[k])
+		}
+		result.mulLines(lEval)
+	}
+
+	variant.combine(&result, &result, &mxplusone)
+
+	return result
+}
+
+// doubleStep sets T to 2T and l to the evaluation at P of the tangent at T
+// inv must be 1/(2*T.Y)
+func doubleStep(T *G2Affine, inv *fp.Element, P *G1Affine, l *lineEvaluation) {
+	var lambda, x3 fp.Element
+
+	// lambda = 3x**2/2y
+	lambda.Square(&T.X)
+	x3.Double(&lambda)
+	lambda.Add(&lambda, &x3).Mul(&lambda, inv)
+
+	l.affineLine(T, &lambda, P)
+
+	x3.Square(&lambda).
+		Sub(&x3, &T.X).
+		Sub(&x3, &T.X)
+	// y3 = lambda*(xT-x3) - yT = r2 - lambda*x3
+	T.Y.Mul(&lambda, &x3).
+		Sub(&l.r2, &T.Y)
+	T.X.Set(&x3)
+}
+
+// addStep sets T to T+Q and l to the evaluation at P of the line through T and Q
+// inv must be 1/(Q.X-T.X)
+func addStep(T, Q *G2Affine, inv *fp.Element, P *G1Affine, l *lineEvaluation) {
+	var lambda, x3 fp.Element
+
+	// lambda = (yQ-yT)/(xQ-xT)
+	lambda.Sub(&Q.Y, &T.Y).Mul(&lambda, inv)
+
+	l.affineLine(T, &lambda, P)
+
+	x3.Square(&lambda).
+		Sub(&x3, &T.X).
+		Sub(&x3, &Q.X)
+	// y3 = lambda*(xT-x3) - yT = r2 - lambda*x3
+	T.Y.Mul(&lambda, &x3).
+		Sub(&l.r2, &T.Y)
+	T.X.Set(&x3)
+}
+
+// affineLine sets l to the evaluation at P of the line of slope lambda through T, returns l
+// the line is y - lambda*x + (lambda*xT - yT), i.e. r0 = yP, r1 = -lambda*xP, r2 = lambda*xT - yT (cf lineCoeffs)
+func (l *lineEvaluation) affineLine(T *G2Affine, lambda *fp.Element, P *G1Affine) *lineEvaluation {
+	l.r0.Set(&P.Y)
+	l.r1.Mul(lambda, &P.X).Neg(&l.r1)
+	l.r2.Mul(lambda, &T.X).Sub(&l.r2, &T.Y)
+	return l
+}
+
+// batchInvertFp sets a[i] to 1/a[i] for all i, with a single inversion (Montgomery's trick)
+// zero elements are left unchanged
+func batchInvertFp(a []fp.Element) {
+	if len(a) == 0 {
+		return
+	}
+	acc := make([]fp.Element, len(a))
+	var accumulator fp.Element
+	accumulator.SetOne()
+	for i := range a {
+		acc[i].Set(&accumulator)
+		if a[i].IsZero() {
+			continue
+		}
+		accumulator.Mul(&accumulator, &a[i])
+	}
+
+	accumulator.Inverse(&accumulator)
+
+	var tmp fp.Element
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i].IsZero() {
+			continue
+		}
+		tmp.Mul(&accumulator, &acc[i])
+		accumulator.Mul(&accumulator, &a[i])
+		a[i].Set(&tmp)
+	}
+}
+
 // lineEval computes the evaluation of the line through Q, R (on the twist) at P
 // Q, R are in jacobian coordinates
 func lineEval(Q, R *G2Jac, P *G1Affine, result *lineEvaluation) {
+	lineCoeffs(Q, R, result)
+	result.evaluate(result, P)
+}
+
+// lineCoeffs computes the coefficients of the line through Q, R (on the twist),
+// independently of the point P at which it is evaluated (cf evaluate)
+// Q, R are in jacobian coordinates
+func lineCoeffs(Q, R *G2Jac, result *lineEvaluation) {
 
 	// converts _Q and _R to projective coords
 	var _Q, _R G2Proj
@@ -268,21 +723,222 @@ func lineEval(Q, R *G2Jac, P *G1Affine, result *lineEvaluation) {
 	result.r0.Sub(&result.r0, &_Q.X)
 	result.r2.Sub(&result.r2, &_Q.Y)
 
-	result.r1.Mul(&result.r1, &P.X)
-	result.r0.Mul(&result.r0, &P.Y)
 }
 
-func (z *PairingResult) mulAssign(l *lineEvaluation) *PairingResult {
+// evaluate sets l to the evaluation at P of the line with coefficients c (cf lineCoeffs), returns l
+func (l *lineEvaluation) evaluate(c *lineEvaluation, P *G1Affine) *lineEvaluation {
+	l.r0.Mul(&c.r0, &P.Y)
+	l.r1.Mul(&c.r1, &P.X)
+	l.r2.Set(&c.r2)
+	return l
+}
+
+// G2Prepared line coefficients of the Miller loop for a fixed G2 point
+// they are computed once (cf FromAffine) and evaluated at P by MillerLoopFixedQ
+type G2Prepared struct {
+	lines    [214]lineEvaluation // 69 lines for part 1, 1 line for (x+1), 144 lines for part 2
+	infinity bool
+}
+
+// SizeOfG2Prepared represents the size in bytes that a G2Prepared needs in binary form
+const SizeOfG2Prepared = 1 + 214*3*fp.Limbs*8
+
+// FromAffine computes the line coefficients of the Miller loop for Q, sets p and returns it
+func (p *G2Prepared) FromAffine(Q *G2Affine) *G2Prepared {
+
+	p.lines = [214]lineEvaluation{}
+	p.infinity = Q.IsInfinity()
+	if p.infinity {
+		return p
+	}
+
+	var Q1, xQ, Qbuf, xQbuf, xQneg G2Jac
+	xQ.FromAffine(Q)
+	Qbuf.FromAffine(Q)
+
+	// part 1
+	j := 0
+	for i := len(loopCounter1) - 2; i >= 0; i-- {
+
+		Q1.Set(&xQ)
+		xQ.Double(&Q1).Neg(&xQ)
+		lineCoeffs(&Q1, &xQ, &p.lines[j]) // div(f) = 2(Q1)+(-2Q)-3(O)
+		xQ.Neg(&xQ)
+		j++
+
+		if loopCounter1[i] == 1 {
+			lineCoeffs(&xQ, &Qbuf, &p.lines[j]) // div(f) = (Q)+(Qbuf)+(-Q-Qbuf)-3(O)
+			xQ.AddAssign(&Qbuf)
+			j++
+		}
+	}
+
+	// line through [x]Q and Q
+	lineCoeffs(&xQ, &Qbuf, &p.lines[j])
+	j++
+
+	// part 2
+	xQbuf.Set(&xQ)
+	xQneg.Neg(&xQ)
+	for i := len(loopCounter2) - 2; i >= 0; i-- {
+
+		Q1.Set(&xQ)
+		xQ.Double(&Q1).Neg(&xQ)
+		lineCoeffs(&Q1, &xQ, &p.lines[j]) // div(f) = 2(Q1)+(-2Q)-3(O)
+		xQ.Neg(&xQ)
+		j++
+
+		if loopCounter2[i] == 1 {
+			lineCoeffs(&xQ, &xQbuf, &p.lines[j]) // div(f) = (Q)+(Qbuf)+(-Q-Qbuf)-3(O)
+			xQ.AddAssign(&xQbuf)
+			j++
+		} else if loopCounter2[i] == -1 {
+			lineCoeffs(&xQ, &xQneg, &p.lines[j]) // div(f) = (Q)+(-Qbuf)+(-Q+Qbuf)-3(O)
+			xQ.AddAssign(&xQneg)
+			j++
+		}
+	}
+	return p
+}
+
+// MillerLoopFixedQ Miller loop, with the line coefficients precomputed in Q
+// it outputs the same result as MillerLoop (AteVariant1)
+func MillerLoopFixedQ(P G1Affine, Q *G2Prepared) *PairingResult {
+
+	var result PairingResult
+	result.SetOne()
+
+	if P.IsInfinity() || Q.infinity {
+		return &result
+	}
+
+	var lEval lineEvaluation
+	// part 1, computes g(P), div(g)=x(Q)-([x]Q)-(x-1)(O)
+	j := 0
+	for i := len(loopCounter1) - 2; i >= 0; i-- {
+
+		result.Square(&result)
+		result.mulAssign(lEval.evaluate(&Q.lines[j], &P))
+		j++
+
+		if loopCounter1[i] == 1 {
+			result.mulAssign(lEval.evaluate(&Q.lines[j], &P))
+			j++
+		}
+	}
+
+	var mx, mxInv, mxplusone PairingResult
+	mx.Set(&result)
+	mxInv.Inverse(&result)
+	mxplusone.Set(&mx).mulAssign(lEval.evaluate(&Q.lines[j], &P))
+	j++
 
-	var a, b, c PairingResult
-	a.MulByVMinusThree(z, &l.r1)
-	b.MulByVminusTwo(z, &l.r0)
-	c.MulByVminusFive(z, &l.r2)
-	z.Add(&a, &b).Add(z, &c)
+	// part 2
+	for i := len(loopCounter2) - 2; i >= 0; i-- {
+
+		result.Square(&result)
+		result.mulAssign(lEval.evaluate(&Q.lines[j], &P))
+		j++
 
+		if loopCounter2[i] == 1 {
+			result.mulAssign(lEval.evaluate(&Q.lines[j], &P)).MulAssign(&mx)
+			j++
+		} else if loopCounter2[i] == -1 {
+			result.mulAssign(lEval.evaluate(&Q.lines[j], &P)).MulAssign(&mxInv)
+			j++
+		}
+	}
+
+	result.Frobenius(&result).MulAssign(&mxplusone)
+	return &result
+}
+
+// Bytes returns the binary encoding of p, SizeOfG2Prepared bytes: an infinity flag byte (0 or 1) followed by
+// the line coefficients r0, r1, r2 of each line, as regular big-endian fp.Element
+// (all zero if p is at infinity)
+func (p *G2Prepared) Bytes() []byte {
+	r := make([]byte, SizeOfG2Prepared)
+	const fpSize = fp.Limbs * 8
+	if p.infinity {
+		r[0] = 1
+		return r
+	}
+	for i := range p.lines {
+		for j, e := range p.lines[i].coordinates() {
+			offset := 1 + (3*i+j)*fpSize
+			copy(r[offset:offset+fpSize], e.Bytes())
+		}
+	}
+	return r
+}
+
+// SetBytes interprets e as the bytes of a G2Prepared (see Bytes)
+// it returns an error if a coordinate is not reduced modulo q
+func (p *G2Prepared) SetBytes(e []byte) error {
+	if len(e) < SizeOfG2Prepared {
+		return io.ErrShortBuffer
+	}
+	if e[0] > 1 {
+		return ErrInvalidInfinityEncoding
+	}
+	const fpSize = fp.Limbs * 8
+	var res G2Prepared
+	res.infinity = e[0] == 1
+	for i := range res.lines {
+		for j, c := range res.lines[i].coordinates() {
+			offset := 1 + (3*i+j)*fpSize
+			if err := setElementBytes(c, e[offset:offset+fpSize]); err != nil {
+				return err
+			}
+			if res.infinity && !c.IsZero() {
+				return ErrInvalidInfinityEncoding
+			}
+		}
+	}
+	*p = res
+	return nil
+}
+
+// coordinates returns pointers to the fp.Element coordinates of l
+func (l *lineEvaluation) coordinates() [3]*fp.Element {
+	return [3]*fp.Element{&l.r0, &l.r1, &l.r2}
+}
+
+// mulAssign multiplies the current pairing result by the line evaluation l and returns it
+// The line evaluation l is f(P) where div(f)=(P')+(Q')+(-P'-Q')-3(O), the support being on the twist:
+// mapped back to E6 it is r0*v**-2 + r1*v**-3 + r2*v**-5. It is multiplied by v**9 = -4*u (in E2,
+// so the final exponentiation is unchanged) to get the sparse element -4*r1 + (-4*r0 + r2*u)*v (cf MulBy01)
+func (z *PairingResult) mulAssign(l *lineEvaluation) *PairingResult {
+	var c0, c1 E2
+	l.sparse(&c0, &c1)
+	return z.MulBy01(z, &c0, &c1)
+}
+
+// mulLines multiplies the current pairing result by the line evaluations l and returns it
+// the lines are multiplied two by two first (cf Mul01By01)
+func (z *PairingResult) mulLines(l []lineEvaluation) *PairingResult {
+	var c0, c1, d0, d1 E2
+	var prod E6
+	k := 0
+	for ; k+1 < len(l); k += 2 {
+		l[k].sparse(&c0, &c1)
+		l[k+1].sparse(&d0, &d1)
+		z.Mul(z, prod.Mul01By01(&c0, &c1, &d0, &d1))
+	}
+	if k < len(l) {
+		z.mulAssign(&l[k])
+	}
 	return z
 }
 
+// sparse sets c0, c1 to the coefficients of the sparse element -4*r1 + (-4*r0 + r2*u)*v (cf mulAssign)
+func (l *lineEvaluation) sparse(c0, c1 *E2) {
+	c0.A0.Double(&l.r1).Double(&c0.A0).Neg(&c0.A0)
+	c0.A1.SetZero()
+	c1.A0.Double(&l.r0).Double(&c1.A0).Neg(&c1.A0)
+	c1.A1.Set(&l.r2)
+}
+
 // precomputes the line evaluations used during the Miller loop.
 func preCompute1(evaluations *[69]lineEvaluation, Q *G2Jac, P *G1Affine, ch chan struct{}) {
 
@@ -311,8 +967,8 @@ func preCompute1(evaluations *[69]lineEvaluation, Q *G2Jac, P *G1Affine, ch chan
 
 }
 
-// precomputes the line evaluations used during the Miller loop.
-func preCompute2(evaluations *[144]lineEvaluation, Q *G2Jac, P *G1Affine, ch chan struct{}) {
+// precomputes the line evaluations used during the second part of the Miller loop, of loop counter counter.
+func preCompute2(evaluations []lineEvaluation, counter []int8, Q *G2Jac, P *G1Affine, ch chan struct{}) {
 
 	var Q1, Qbuf, Qneg G2Jac
 	Q1.Set(Q)
@@ -321,7 +977,7 @@ func preCompute2(evaluations *[144]lineEvaluation, Q *G2Jac, P *G1Affine, ch cha
 
 	j := 0
 
-	for i := len(loopCounter2) - 2; i >= 0; i-- {
+	for i := len(counter) - 2; i >= 0; i-- {
 
 		Q1.Set(Q)
 		Q.Double(&Q1).Neg(Q)
@@ -330,12 +986,12 @@ func preCompute2(evaluations *[144]lineEvaluation, Q *G2Jac, P *G1Affine, ch cha
 		ch <- struct{}{}
 		j++
 
-		if loopCounter2[i] == 1 {
+		if counter[i] == 1 {
 			lineEval(Q, &Qbuf, P, &evaluations[j]) // f(P), div(f) = (Q)+(Qbuf)+(-Q-Qbuf)-3(O)
 			Q.AddAssign(&Qbuf)
 			ch <- struct{}{}
 			j++
-		} else if loopCounter2[i] == -1 {
+		} else if counter[i] == -1 {
 			lineEval(Q, &Qneg, P, &evaluations[j]) // f(P), div(f) = (Q)+(-Qbuf)+(-Q+Qbuf)-3(O)
 			Q.AddAssign(&Qneg)
 			ch <- struct{}{}
@@ -0,0 +1,155 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gurvy
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+)
+
+// The interfaces below give curve-agnostic access to the curve packages, so that protocol code
+// can be written once for all curves. The implementations are registered by the curve packages
+// (cf Register) and returned by Get: importing a curve package, possibly as
+//
+//	import _ "github.com/consensys/gurvy/bn256"
+//
+// makes it available. The values created by an Engine can only be combined with values created
+// by the same Engine: the methods panic otherwise. The methods setting their receiver return it.
+
+// Scalar element of the scalar field Fr of a curve, the integers modulo r
+type Scalar interface {
+	Set(a Scalar) Scalar
+	SetUint64(v uint64) Scalar
+	SetBigInt(v *big.Int) Scalar // sets v mod r
+	SetRandom() Scalar
+	Add(a, b Scalar) Scalar
+	Sub(a, b Scalar) Scalar
+	Mul(a, b Scalar) Scalar
+	Neg(a Scalar) Scalar
+	Inverse(a Scalar) Scalar // 1/a, or 0 if a is 0
+	Equal(a Scalar) bool
+	IsZero() bool
+	BigInt(res *big.Int) *big.Int // sets res to the scalar in [0, r), returns res
+	Bytes() []byte                // big endian, in [0, r)
+	SetBytes(e []byte) Scalar     // interprets e as a big endian integer, sets it mod r
+	String() string
+}
+
+// G1 point of the r-torsion subgroup G1 of E(Fp)
+type G1 interface {
+	Set(a G1) G1
+	SetInfinity() G1
+	Add(a, b G1) G1
+	Double(a G1) G1
+	Neg(a G1) G1
+	ScalarMul(a G1, s Scalar) G1
+	Equal(a G1) bool
+	IsInfinity() bool
+	IsInSubGroup() bool
+	Bytes() []byte                    // compressed encoding
+	SetBytes(buf []byte) (int, error) // compressed or uncompressed encoding, fails if not in the subgroup; returns the number of bytes read
+	String() string
+}
+
+// G2 point of the r-torsion subgroup G2 of the twist E'
+type G2 interface {
+	Set(a G2) G2
+	SetInfinity() G2
+	Add(a, b G2) G2
+	Double(a G2) G2
+	Neg(a G2) G2
+	ScalarMul(a G2, s Scalar) G2
+	Equal(a G2) bool
+	IsInfinity() bool
+	IsInSubGroup() bool
+	Bytes() []byte                    // compressed encoding
+	SetBytes(buf []byte) (int, error) // compressed or uncompressed encoding, fails if not in the subgroup; returns the number of bytes read
+	String() string
+}
+
+// GT element of the target group of the pairing, the subgroup of order r of Fp**k
+type GT interface {
+	Set(a GT) GT
+	SetOne() GT
+	Mul(a, b GT) GT
+	Square(a GT) GT
+	Inverse(a GT) GT
+	Exp(a GT, s Scalar) GT
+	Equal(a GT) bool
+	IsOne() bool
+	Bytes() []byte
+	SetBytes(e []byte) error // fails if e does not encode an element of GT
+}
+
+// Engine pairing engine of a curve: it creates scalars and group elements, and computes pairings
+type Engine interface {
+	ID() ID
+	Params() Params
+
+	NewScalar() Scalar // 0
+	NewG1() G1         // point at infinity
+	NewG2() G2         // point at infinity
+	NewGT() GT         // 1
+
+	G1Generator() G1
+	G2Generator() G2
+
+	// Pair computes the product of the reduced pairings e(P[i], Q[i]), it fails if P and Q have different lengths
+	Pair(P []G1, Q []G2) (GT, error)
+
+	// PairingCheck returns true if the product of the reduced pairings e(P[i], Q[i]) is 1
+	PairingCheck(P []G1, Q []G2) (bool, error)
+}
+
+// ErrCurveNotRegistered is returned by Get when the package of a known curve was not imported
+var ErrCurveNotRegistered = errors.New("curve not registered, its package must be imported")
+
+var (
+	enginesLock sync.RWMutex
+	engines     = make(map[ID]Engine)
+)
+
+// Register makes the engine e available through Get(e.ID())
+// it is called by the curve packages (in their init function) and panics if e.ID() is unknown or already registered
+func Register(e Engine) {
+	id := e.ID()
+	if _, ok := params[id]; !ok {
+		panic(ErrUnknownCurve)
+	}
+	enginesLock.Lock()
+	defer enginesLock.Unlock()
+	if _, ok := engines[id]; ok {
+		panic("gurvy: Register called twice for curve " + id.String())
+	}
+	engines[id] = e
+}
+
+// Get returns the engine of the curve id
+// it returns ErrUnknownCurve if id is not a supported curve, and ErrCurveNotRegistered if its package was not imported
+func Get(id ID) (Engine, error) {
+	if _, ok := params[id]; !ok {
+		return nil, ErrUnknownCurve
+	}
+	enginesLock.RLock()
+	defer enginesLock.RUnlock()
+	e, ok := engines[id]
+	if !ok {
+		return nil, ErrCurveNotRegistered
+	}
+	return e, nil
+}
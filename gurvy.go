@@ -18,6 +18,12 @@ limitations under the License.
 // bls381, bls377 and bn256
 package gurvy
 
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
 // do not modify the order of this enum
 const (
 	UNKNOWN ID = iota
@@ -31,6 +37,18 @@ const (
 // (used in serialization checks)
 type ID uint16
 
+// ParseID returns the ID of the curve named name (the output of ID.String, case insensitive)
+// or ErrUnknownCurve
+func ParseID(name string) (ID, error) {
+	for id := range params {
+		if strings.EqualFold(name, id.String()) {
+			return id, nil
+		}
+	}
+	return UNKNOWN, ErrUnknownCurve
+}
+
+// String returns the name of the curve, "unknown" if id is not a supported curve
 func (id ID) String() string {
 	switch id {
 	case BLS377:
@@ -42,6 +60,113 @@ func (id ID) String() string {
 	case BW761:
 		return "bw761"
 	default:
-		panic("unimplemented curve ID")
+		return "unknown"
+	}
+}
+
+// ErrUnknownCurve is returned when a curve ID does not match a supported curve
+var ErrUnknownCurve = errors.New("unknown curve ID")
+
+// TwistType type of the sextic twist E' of E on which G2 is defined, E: y**2=x**3+b
+// and E': y**2=x**3+b' being isomorphic over Fp**k, xi the sextic non-residue of the tower
+type TwistType uint8
+
+const (
+	// DTwist b' = b/xi
+	DTwist TwistType = iota
+	// MTwist b' = b*xi
+	MTwist
+)
+
+// String returns the name of the twist type, "unknown" if t is not a twist type
+func (t TwistType) String() string {
+	switch t {
+	case DTwist:
+		return "D-twist"
+	case MTwist:
+		return "M-twist"
+	default:
+		return "unknown"
+	}
+}
+
+// Params parameters of a pairing-friendly curve
+type Params struct {
+	ID              ID
+	FpModulus       *big.Int  // p, characteristic of the field of definition of E
+	FrModulus       *big.Int  // r, order of G1, G2 and GT
+	CofactorG1      *big.Int  // #E(Fp)/r
+	CofactorG2      *big.Int  // #E'(Fp**(k/6))/r
+	EmbeddingDegree int       // k, GT is a subgroup of Fp**k
+	Twist           TwistType // type of the twist E' on which G2 is defined
+	Seed            *big.Int  // x, the parameter of the curve family (for BW761, the seed of BLS377)
+}
+
+// params decimal strings of p, r, the cofactors of G1, G2, and of the seed, then k and the twist type
+var params = map[ID]struct {
+	p, r, h1, h2, x string
+	k               int
+	twist           TwistType
+}{
+	BLS377: {
+		p:     "258664426012969094010652733694893533536393512754914660539884262666720468348340822774968888139573360124440321458177",
+		r:     "8444461749428370424248824938781546531375899335154063827935233455917409239041",
+		h1:    "30631250834960419227450344600217059328",
+		h2:    "7923214915284317143930293550643874566881017850177945424769256759165301436616933228209277966774092486467289478618404761412630691835764674559376407658497",
+		x:     "9586122913090633729",
+		k:     12,
+		twist: DTwist,
+	},
+	BLS381: {
+		p:     "4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559787",
+		r:     "52435875175126190479447740508185965837690552500527637822603658699938581184513",
+		h1:    "76329603384216526031706109802092473003",
+		h2:    "305502333931268344200999753193121504214466019254188142667664032982267604182971884026507427359259977847832272839041616661285803823378372096355777062779109",
+		x:     "-15132376222941642752",
+		k:     12,
+		twist: MTwist,
+	},
+	BN256: {
+		p:     "21888242871839275222246405745257275088696311157297823662689037894645226208583",
+		r:     "21888242871839275222246405745257275088548364400416034343698204186575808495617",
+		h1:    "1",
+		h2:    "21888242871839275222246405745257275088844257914179612981679871602714643921549",
+		x:     "4965661367192848881",
+		k:     12,
+		twist: DTwist,
+	},
+	BW761: {
+		p:     "6891450384315732539396789682275657542479668912536150109513790160209623422243491736087683183289411687640864567753786613451161759120554247759349511699125301598951605099378508850372543631423596795951899700429969112842764913119068299",
+		r:     "258664426012969094010652733694893533536393512754914660539884262666720468348340822774968888139573360124440321458177",
+		h1:    "26642435879335816683987677701488073867751118270052650655942102502312977592501693353047140953112195348280268661194876",
+		h2:    "26642435879335816683987677701488073867751118270052650655942102502312977592501693353047140953112195348280268661194869",
+		x:     "9586122913090633729",
+		k:     6,
+		twist: MTwist,
+	},
+}
+
+// CurveParams returns the parameters of the curve id, or ErrUnknownCurve
+// the big.Int are new copies, they can be modified by the caller
+func CurveParams(id ID) (Params, error) {
+	c, ok := params[id]
+	if !ok {
+		return Params{}, ErrUnknownCurve
+	}
+	res := Params{
+		ID:              id,
+		FpModulus:       new(big.Int),
+		FrModulus:       new(big.Int),
+		CofactorG1:      new(big.Int),
+		CofactorG2:      new(big.Int),
+		EmbeddingDegree: c.k,
+		Twist:           c.twist,
+		Seed:            new(big.Int),
 	}
+	res.FpModulus.SetString(c.p, 10)
+	res.FrModulus.SetString(c.r, 10)
+	res.CofactorG1.SetString(c.h1, 10)
+	res.CofactorG2.SetString(c.h2, 10)
+	res.Seed.SetString(c.x, 10)
+	return res, nil
 }
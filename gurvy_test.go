@@ -0,0 +1,195 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gurvy_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gurvy"
+	"github.com/consensys/gurvy/bls377"
+	"github.com/consensys/gurvy/bls381"
+	"github.com/consensys/gurvy/bn256"
+	"github.com/consensys/gurvy/bw761"
+)
+
+var curves = []gurvy.ID{gurvy.BLS377, gurvy.BLS381, gurvy.BN256, gurvy.BW761}
+
+var errPointNotInSubGroup = map[gurvy.ID]error{
+	gurvy.BLS377: bls377.ErrPointNotInSubGroup,
+	gurvy.BLS381: bls381.ErrPointNotInSubGroup,
+	gurvy.BN256:  bn256.ErrPointNotInSubGroup,
+	gurvy.BW761:  bw761.ErrPointNotInSubGroup,
+}
+
+func TestParseID(t *testing.T) {
+	for _, id := range curves {
+		for _, name := range []string{id.String(), strings.ToUpper(id.String())} {
+			if parsed, err := gurvy.ParseID(name); err != nil || parsed != id {
+				t.Fatalf("ParseID(%q) should return %s", name, id)
+			}
+		}
+	}
+	if _, err := gurvy.ParseID("bls12-380"); err != gurvy.ErrUnknownCurve {
+		t.Fatal("ParseID should fail on an unknown curve name")
+	}
+	if _, err := gurvy.ParseID(gurvy.UNKNOWN.String()); err != gurvy.ErrUnknownCurve {
+		t.Fatal("ParseID should fail on the name of UNKNOWN")
+	}
+}
+
+func TestStringUnknown(t *testing.T) {
+	if s := gurvy.UNKNOWN.String(); s != "unknown" {
+		t.Fatalf("UNKNOWN.String() should return \"unknown\", got %q", s)
+	}
+	if s := gurvy.ID(42).String(); s != "unknown" {
+		t.Fatalf("ID(42).String() should return \"unknown\", got %q", s)
+	}
+	if s := gurvy.TwistType(42).String(); s != "unknown" {
+		t.Fatalf("TwistType(42).String() should return \"unknown\", got %q", s)
+	}
+}
+
+func TestGet(t *testing.T) {
+	if _, err := gurvy.Get(gurvy.UNKNOWN); err != gurvy.ErrUnknownCurve {
+		t.Fatal("Get should fail on an unknown curve")
+	}
+
+	for _, id := range curves {
+		e, err := gurvy.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.ID() != id || e.Params().ID != id {
+			t.Fatalf("%s: wrong engine", id)
+		}
+		if err := testEngine(e); err != "" {
+			t.Fatalf("%s: %s", id, err)
+		}
+	}
+}
+
+// testEngine checks an engine through the gurvy interfaces only, it returns a description of the first failure
+func testEngine(e gurvy.Engine) string {
+
+	a := e.NewScalar().SetRandom()
+	b := e.NewScalar().SetRandom()
+	ab := e.NewScalar().Mul(a, b)
+
+	// scalars
+	var r, x big.Int
+	r.Set(e.Params().FrModulus)
+	if !e.NewScalar().SetBigInt(&r).IsZero() {
+		return "r should be 0 in Fr"
+	}
+	inv := e.NewScalar().Inverse(a)
+	if !e.NewScalar().Mul(inv, a).Equal(e.NewScalar().SetUint64(1)) {
+		return "a*(1/a) should be 1"
+	}
+	if !e.NewScalar().SetBytes(a.Bytes()).Equal(a) || a.BigInt(&x).Cmp(&r) >= 0 {
+		return "SetBytes(Bytes()) should stay the same"
+	}
+	if !e.NewScalar().Sub(a, b).Equal(e.NewScalar().Add(a, e.NewScalar().Neg(b))) {
+		return "a-b should be a+(-b)"
+	}
+
+	// groups
+	g1, g2 := e.G1Generator(), e.G2Generator()
+	ag1 := e.NewG1().ScalarMul(g1, a)
+	bg2 := e.NewG2().ScalarMul(g2, b)
+	if !ag1.IsInSubGroup() || !bg2.IsInSubGroup() || ag1.IsInfinity() || bg2.IsInfinity() {
+		return "[a]G1 and [b]G2 should be in the subgroups"
+	}
+	if !e.NewG1().Add(g1, g1).Equal(e.NewG1().Double(g1)) || !e.NewG2().Add(g2, g2).Equal(e.NewG2().Double(g2)) {
+		return "P+P should be 2P"
+	}
+	if !e.NewG1().Add(ag1, e.NewG1().Neg(ag1)).IsInfinity() || !e.NewG2().Add(bg2, e.NewG2().Neg(bg2)).IsInfinity() {
+		return "P-P should be 0"
+	}
+	_ag1, _bg2 := e.NewG1(), e.NewG2()
+	if _, err := _ag1.SetBytes(ag1.Bytes()); err != nil || !_ag1.Equal(ag1) {
+		return "G1: SetBytes(Bytes()) should stay the same"
+	}
+	if _, err := _bg2.SetBytes(bg2.Bytes()); err != nil || !_bg2.Equal(bg2) {
+		return "G2: SetBytes(Bytes()) should stay the same"
+	}
+
+	// pairing
+	res, err := e.Pair([]gurvy.G1{ag1}, []gurvy.G2{bg2})
+	if err != nil {
+		return err.Error()
+	}
+	expected, err := e.Pair([]gurvy.G1{g1}, []gurvy.G2{g2})
+	if err != nil {
+		return err.Error()
+	}
+	if expected.IsOne() {
+		return "the pairing should be non-degenerate"
+	}
+	expected.Exp(expected, ab)
+	if !res.Equal(expected) {
+		return "the pairing should be bilinear"
+	}
+	abg1 := e.NewG1().ScalarMul(g1, ab)
+	ok, err := e.PairingCheck([]gurvy.G1{ag1, e.NewG1().Neg(abg1)}, []gurvy.G2{bg2, g2})
+	if err != nil || !ok {
+		return "PairingCheck of e([a]G1, [b]G2)*e(-[ab]G1, G2) should be true"
+	}
+	if _, err := e.Pair([]gurvy.G1{g1}, nil); err == nil {
+		return "Pair should fail when P and Q have different lengths"
+	}
+	_res := e.NewGT()
+	if err := _res.SetBytes(res.Bytes()); err != nil || !_res.Equal(res) {
+		return "GT: SetBytes(Bytes()) should stay the same"
+	}
+	if !e.NewGT().Mul(res, e.NewGT().Inverse(res)).IsOne() {
+		return "z*(1/z) should be 1"
+	}
+
+	return ""
+}
+
+func TestSetBytesSubGroup(t *testing.T) {
+	for _, id := range curves {
+		e, err := gurvy.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// changing the last byte of the encoding of the generator changes x: the points on the curve found this
+		// way are not in the subgroup, except on the G1 of BN256, of cofactor 1
+		check := func(group string, buf []byte, setBytes func([]byte) (int, error), cofactorOne bool) {
+			var nbRejected int
+			for k := 1; k < 256; k++ {
+				_buf := append([]byte{}, buf...)
+				_buf[len(_buf)-1] += byte(k)
+				_, err := setBytes(_buf)
+				if err == errPointNotInSubGroup[id] {
+					nbRejected++
+				} else if err == nil && !cofactorOne {
+					t.Fatalf("%s %s: SetBytes should reject the points not in the subgroup", id, group)
+				}
+			}
+			if (nbRejected == 0) != cofactorOne {
+				t.Fatalf("%s %s: SetBytes should return ErrPointNotInSubGroup on the points not in the subgroup", id, group)
+			}
+		}
+		check("G1", e.G1Generator().Bytes(), e.NewG1().SetBytes, id == gurvy.BN256)
+		check("G2", e.G2Generator().Bytes(), e.NewG2().SetBytes, false)
+	}
+}
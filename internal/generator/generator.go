@@ -5,6 +5,7 @@ import (
 
 	"github.com/consensys/bavard"
 	goff "github.com/consensys/goff/cmd"
+	"github.com/consensys/gurvy/internal/templates/engine"
 	"github.com/consensys/gurvy/internal/templates/fq12over6over2"
 	"github.com/consensys/gurvy/internal/templates/pairing"
 	"github.com/consensys/gurvy/internal/templates/point"
@@ -136,6 +137,24 @@ func GenerateFq12over6over2(conf CurveConfig) error {
 		return err
 	}
 
+	// GT wrapper
+	src = []string{
+		fq12over6over2.Gt,
+	}
+	pathSrc = filepath.Join(conf.OutputDir, "gt.go")
+	if err := bavard.Generate(pathSrc, src, conf, bavardOpts...); err != nil {
+		return err
+	}
+
+	// GT tests
+	src = []string{
+		fq12over6over2.GtTests,
+	}
+	pathSrc = filepath.Join(conf.OutputDir, "gt_test.go")
+	if err := bavard.Generate(pathSrc, src, conf, bavardOpts...); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -198,3 +217,76 @@ func GeneratePairing(conf CurveConfig) error {
 
 	return nil
 }
+
+// GenerateMarshal generates binary encoding of G1Affine and G2Affine
+// conf.CoordType must be set to the coordinate type of G2
+func GenerateMarshal(conf CurveConfig) error {
+
+	bavardOpts := []func(*bavard.Bavard) error{
+		bavard.Apache2("ConsenSys AG", 2020),
+		bavard.Package(conf.CurveName),
+		bavard.GeneratedBy("gurvy"),
+	}
+
+	src := []string{
+		point.Marshal,
+	}
+
+	pathSrc := filepath.Join(conf.OutputDir, "marshal.go")
+	if err := bavard.Generate(pathSrc, src, conf, bavardOpts...); err != nil {
+		return err
+	}
+
+	src = []string{
+		point.MarshalTests,
+	}
+
+	pathSrc = filepath.Join(conf.OutputDir, "marshal_test.go")
+	if err := bavard.Generate(pathSrc, src, conf, bavardOpts...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GenerateMultiExp generates the options and errors of the multi exponentiations in G1 and G2
+func GenerateMultiExp(conf CurveConfig) error {
+
+	bavardOpts := []func(*bavard.Bavard) error{
+		bavard.Apache2("ConsenSys AG", 2020),
+		bavard.Package(conf.CurveName),
+		bavard.GeneratedBy("gurvy"),
+	}
+
+	src := []string{
+		point.MultiExp,
+	}
+
+	pathSrc := filepath.Join(conf.OutputDir, "multiexp.go")
+	if err := bavard.Generate(pathSrc, src, conf, bavardOpts...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GenerateEngine generates the implementation of gurvy.Engine of the curve
+func GenerateEngine(conf CurveConfig) error {
+
+	bavardOpts := []func(*bavard.Bavard) error{
+		bavard.Apache2("ConsenSys AG", 2020),
+		bavard.Package(conf.CurveName),
+		bavard.GeneratedBy("gurvy"),
+	}
+
+	src := []string{
+		engine.Engine,
+	}
+
+	pathSrc := filepath.Join(conf.OutputDir, "engine.go")
+	if err := bavard.Generate(pathSrc, src, conf, bavardOpts...); err != nil {
+		return err
+	}
+
+	return nil
+}
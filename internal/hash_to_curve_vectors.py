@@ -0,0 +1,463 @@
+#!/usr/bin/env python3
+# Copyright 2020 ConsenSys AG
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+"""Generates the hash to curve test vectors of the SVDW suites (RFC 9380, section 6.6.1)
+
+RFC 9380 has no test vectors for bn256 (BN254), bls377 (BLS12-377) and bw761 (BW6-761): this script
+computes them from the RFC's pseudo-code, in plain python integers, independently of the go code.
+It writes the testdata/*_SVDW_*.json files of the bn256, bls377 and bw761 packages, in the format of the
+RFC's vectors (cf bls381/testdata), read by hash_to_curve_test.go.
+
+Usage (python >= 3.6, no dependency), from this directory:
+
+    python3 hash_to_curve_vectors.py
+"""
+
+import hashlib
+import json
+import os
+
+MSGS = ['', 'abc', 'abcdef0123456789', 'q128_' + 'q' * 128, 'a512_' + 'a' * 512]
+
+
+# ------------------------------------------------------------
+# fields
+
+
+def legendre(a, p):
+    a %= p
+    if a == 0:
+        return 0
+    return 1 if pow(a, (p - 1) // 2, p) == 1 else -1
+
+
+def sqrt_mod(a, p):
+    """square root of a mod p (Tonelli-Shanks), None if a is not a square"""
+    a %= p
+    if a == 0:
+        return 0
+    if legendre(a, p) != 1:
+        return None
+    if p % 4 == 3:
+        return pow(a, (p + 1) // 4, p)
+    q, s = p - 1, 0
+    while q % 2 == 0:
+        q, s = q // 2, s + 1
+    z = 2
+    while legendre(z, p) != -1:
+        z += 1
+    m, c, t, r = s, pow(z, q, p), pow(a, q, p), pow(a, (q + 1) // 2, p)
+    while t != 1:
+        i, tt = 0, t
+        while tt != 1:
+            tt, i = tt * tt % p, i + 1
+        b = pow(c, 1 << (m - i - 1), p)
+        m, c, t, r = i, b * b % p, t * b * b % p, r * b % p
+    return r
+
+
+class Fp:
+    def __init__(self, v, p):
+        self.p, self.v = p, v % p
+
+    def mk(self, v):
+        return Fp(v, self.p)
+
+    def __add__(self, o):
+        return self.mk(self.v + o.v)
+
+    def __sub__(self, o):
+        return self.mk(self.v - o.v)
+
+    def __neg__(self):
+        return self.mk(-self.v)
+
+    def __mul__(self, o):
+        if isinstance(o, int):
+            return self.mk(self.v * o)
+        return self.mk(self.v * o.v)
+
+    __rmul__ = __mul__
+
+    def __eq__(self, o):
+        return self.v == o.v
+
+    def inv(self):
+        return self.mk(pow(self.v, self.p - 2, self.p))
+
+    def __truediv__(self, o):
+        return self * o.inv()
+
+    def is_zero(self):
+        return self.v == 0
+
+    def is_square(self):
+        return legendre(self.v, self.p) >= 0
+
+    def sqrt(self):
+        r = sqrt_mod(self.v, self.p)
+        return None if r is None else self.mk(r)
+
+    def sgn0(self):
+        return self.v & 1
+
+    def hex(self, n):
+        return '0x%0*x' % (n, self.v)
+
+
+class Fp2:
+    """a0 + a1*u with u**2 = beta"""
+
+    def __init__(self, a0, a1, p, beta):
+        self.p, self.beta, self.a0, self.a1 = p, beta, a0 % p, a1 % p
+
+    def mk(self, a0, a1=0):
+        return Fp2(a0, a1, self.p, self.beta)
+
+    def __add__(self, o):
+        return self.mk(self.a0 + o.a0, self.a1 + o.a1)
+
+    def __sub__(self, o):
+        return self.mk(self.a0 - o.a0, self.a1 - o.a1)
+
+    def __neg__(self):
+        return self.mk(-self.a0, -self.a1)
+
+    def __mul__(self, o):
+        if isinstance(o, int):
+            return self.mk(self.a0 * o, self.a1 * o)
+        return self.mk(self.a0 * o.a0 + self.beta * self.a1 * o.a1, self.a0 * o.a1 + self.a1 * o.a0)
+
+    __rmul__ = __mul__
+
+    def __eq__(self, o):
+        return self.a0 == o.a0 and self.a1 == o.a1
+
+    def __pow__(self, e):
+        r, b = self.mk(1), self
+        while e:
+            if e & 1:
+                r = r * b
+            b, e = b * b, e >> 1
+        return r
+
+    def norm(self):
+        return (self.a0 * self.a0 - self.beta * self.a1 * self.a1) % self.p
+
+    def inv(self):
+        n = pow(self.norm(), self.p - 2, self.p)
+        return self.mk(self.a0 * n, -self.a1 * n)
+
+    def __truediv__(self, o):
+        return self * o.inv()
+
+    def conj(self):
+        return self.mk(self.a0, -self.a1)
+
+    def is_zero(self):
+        return self.a0 == 0 and self.a1 == 0
+
+    def is_square(self):
+        return legendre(self.norm(), self.p) >= 0
+
+    def sqrt(self):
+        p = self.p
+        if self.a1 == 0:
+            r = sqrt_mod(self.a0, p)
+            if r is not None:
+                return self.mk(r)
+            return self.mk(0, sqrt_mod(self.a0 * pow(self.beta, p - 2, p), p))
+        lam = sqrt_mod(self.norm(), p)
+        if lam is None:
+            return None
+        half = pow(2, p - 2, p)
+        x0 = sqrt_mod((self.a0 + lam) * half, p)
+        if x0 is None:
+            x0 = sqrt_mod((self.a0 - lam) * half, p)
+        r = self.mk(x0, self.a1 * pow(2 * x0, p - 2, p))
+        assert r * r == self
+        return r
+
+    def sgn0(self):
+        # RFC 9380, section 4.1
+        return (self.a0 & 1) | (self.a0 == 0 and self.a1 & 1)
+
+    def hex(self, n):
+        return '0x%0*x,0x%0*x' % (n, self.a0, n, self.a1)
+
+
+# ------------------------------------------------------------
+# short Weierstrass curves y**2 = x**3 + b, affine coordinates, None is the point at infinity
+
+
+def add(P, Q):
+    if P is None:
+        return Q
+    if Q is None:
+        return P
+    (x1, y1), (x2, y2) = P, Q
+    if x1 == x2:
+        if (y1 + y2).is_zero():
+            return None
+        l = (x1 * x1 * 3) / (y1 * 2)
+    else:
+        l = (y2 - y1) / (x2 - x1)
+    x3 = l * l - x1 - x2
+    return (x3, l * (x1 - x3) - y1)
+
+
+def neg(P):
+    return None if P is None else (P[0], -P[1])
+
+
+def mul(P, k):
+    if k < 0:
+        return mul(neg(P), -k)
+    R = None
+    while k:
+        if k & 1:
+            R = add(R, P)
+        P, k = add(P, P), k >> 1
+    return R
+
+
+def on_curve(P, b):
+    x, y = P
+    return y * y == x * x * x + b
+
+
+# ------------------------------------------------------------
+# hash to curve, RFC 9380
+
+
+def expand_message_xmd(msg, dst, n):
+    # section 5.3.1, with SHA-256
+    ell = (n + 31) // 32
+    dst_prime = dst + bytes([len(dst)])
+    b0 = hashlib.sha256(bytes(64) + msg + n.to_bytes(2, 'big') + b'\x00' + dst_prime).digest()
+    bi = hashlib.sha256(b0 + b'\x01' + dst_prime).digest()
+    out = bi
+    for i in range(2, ell + 1):
+        bi = hashlib.sha256(bytes(a ^ b for a, b in zip(b0, bi)) + bytes([i]) + dst_prime).digest()
+        out += bi
+    return out[:n]
+
+
+def hash_to_field(msg, dst, count, m, L, one):
+    # section 5.2
+    p = one.p
+    ub = expand_message_xmd(msg, dst, count * m * L)
+    e = [int.from_bytes(ub[i * L:(i + 1) * L], 'big') % p for i in range(count * m)]
+    if m == 1:
+        return [one.mk(v) for v in e]
+    return [one.mk(e[2 * i], e[2 * i + 1]) for i in range(count)]
+
+
+class SVDW:
+    """Shallue-van de Woestijne map to y**2 = x**3 + A*x + B, section 6.6.1"""
+
+    def __init__(self, one, A, B):
+        self.one, self.A, self.B = one, A, B
+        self.Z = self.find_z(one)
+        Z = self.Z
+        f = 3 * Z * Z + 4 * A
+        self.c1 = self.g(Z)
+        self.c2 = -Z / (one * 2)
+        c3 = (-self.g(Z) * f).sqrt()
+        if c3.sgn0() == 1:
+            c3 = -c3
+        self.c3 = c3
+        self.c4 = -self.g(Z) * 4 / f
+
+    def g(self, x):
+        return x * x * x + self.A * x + self.B
+
+    def find_z(self, one):
+        # appendix H.1
+        def h(Z):
+            return -(3 * Z * Z + 4 * self.A) / (4 * self.g(Z))
+
+        ctr = 1
+        while True:
+            for Z in (one * ctr, -(one * ctr)):
+                if self.g(Z).is_zero() or h(Z).is_zero() or not h(Z).is_square():
+                    continue
+                if self.g(Z).is_square() or self.g(-Z / (one * 2)).is_square():
+                    return Z
+            ctr += 1
+
+    def map(self, u):
+        # appendix F.1
+        one = self.one
+        tv1 = u * u * self.c1
+        tv2 = one + tv1
+        tv1 = one - tv1
+        tv3 = tv1 * tv2
+        tv3 = tv3 if tv3.is_zero() else tv3.inv()
+        tv4 = u * tv1 * tv3 * self.c3
+        x1 = self.c2 - tv4
+        x2 = self.c2 + tv4
+        x3 = tv2 * tv2 * tv3
+        x3 = x3 * x3 * self.c4 + self.Z
+        if self.g(x1).is_square():
+            x = x1
+        elif self.g(x2).is_square():
+            x = x2
+        else:
+            x = x3
+        y = self.g(x).sqrt()
+        if u.sgn0() != y.sgn0():
+            y = -y
+        return (x, y)
+
+
+def generate(outdir, prefix, curve, G, L, nhex, m, b, clear_cofactor, r):
+    """writes the RO and NU suites of G (1 or 2) in outdir/testdata"""
+    for ro in (True, False):
+        suite = '%sG%d_XMD:SHA-256_SVDW_%s_' % (prefix, G, 'RO' if ro else 'NU')
+        dst = 'QUUX-V01-CS02-with-' + suite
+
+        def pt(P):
+            return {'x': P[0].hex(nhex), 'y': P[1].hex(nhex)}
+
+        vectors = []
+        for msg in MSGS:
+            u = hash_to_field(msg.encode(), dst.encode(), 2 if ro else 1, G, L, m.one)
+            Q = [m.map(x) for x in u]
+            for q in Q:
+                assert on_curve(q, b)
+            P = add(Q[0], Q[1]) if ro else Q[0]
+            P = clear_cofactor(P)
+            assert P is not None and mul(P, r) is None
+            v = {'P': pt(P), 'msg': msg, 'u': [x.hex(nhex) for x in u]}
+            if ro:
+                v['Q0'], v['Q1'] = pt(Q[0]), pt(Q[1])
+            else:
+                v['Q'] = pt(Q[0])
+            vectors.append(v)
+
+        d = {
+            'L': hex(L), 'Z': m.Z.hex(nhex), 'ciphersuite': suite, 'curve': '%s G%d' % (curve, G), 'dst': dst,
+            'expand': 'XMD', 'field': {'m': '0x%x' % G, 'p': '0x%0*x' % (nhex, m.one.p)}, 'hash': 'sha256',
+            'k': '0x80', 'map': {'name': 'SVDW'}, 'randomOracle': ro, 'vectors': vectors,
+        }
+        path = os.path.join(outdir, 'testdata', suite.replace(':', '_') + '.json')
+        os.makedirs(os.path.dirname(path), exist_ok=True)
+        with open(path, 'w') as f:
+            json.dump(d, f, indent=2, sort_keys=True)
+            f.write('\n')
+        print(path)
+
+
+def psi(P, cx, cy):
+    """untwist-Frobenius-twist endomorphism of G2"""
+    if P is None:
+        return None
+    return (P[0].conj() * cx, P[1].conj() * cy)
+
+
+# ------------------------------------------------------------
+# curves
+
+
+def bn256():
+    x = 4965661367192848881
+    p = 21888242871839275222246405745257275088696311157297823662689037894645226208583
+    r = 21888242871839275222246405745257275088548364400416034343698204186575808495617
+    # fp2 = fp[u]/(u**2 + 1), as in bn256/e2.go
+    one, one2 = Fp(1, p), Fp2(1, 0, p, -1)
+    zero, zero2 = Fp(0, p), Fp2(0, 0, p, -1)
+
+    # G1: y**2 = x**3 + 3, cofactor 1
+    b1 = one * 3
+    generate('../bn256', 'BN254', 'BN254', 1, 48, 64, SVDW(one, zero, b1), b1, lambda P: P, r)
+
+    # G2: y**2 = x**3 + 3/(9+u) (D-twist), cofactor cleared with [x]P + psi([3x]P) + psi2([x]P) + psi3(P)
+    xi = one2.mk(9, 1)
+    b2 = one2 * 3 / xi
+    cx, cy = xi ** ((p - 1) // 3), xi ** ((p - 1) // 2)
+
+    def clear(P):
+        xP = mul(P, x)
+        res = add(xP, psi(mul(P, 3 * x), cx, cy))
+        res = add(res, psi(psi(xP, cx, cy), cx, cy))
+        return add(res, psi(psi(psi(P, cx, cy), cx, cy), cx, cy))
+
+    generate('../bn256', 'BN254', 'BN254', 2, 48, 64, SVDW(one2, zero2, b2), b2, clear, r)
+
+
+def bls377():
+    x = 0x8508c00000000001
+    p = 0x01ae3a4617c510eac63b05c06ca1493b1a22d9f300f5138f1ef3622fba094800170b5d44300000008508c00000000001
+    r = 0x12ab655e9a2ca55660b44d1e5c37b00159aa76fed00000010a11800000000001
+    # fp2 = fp[u]/(u**2 - 5), as in bls377/e2.go
+    one, one2 = Fp(1, p), Fp2(1, 0, p, 5)
+    zero, zero2 = Fp(0, p), Fp2(0, 0, p, 5)
+
+    # G1: y**2 = x**3 + 1, cofactor cleared with [1-x]P
+    b1 = one
+    generate('../bls377', 'BLS12377', 'BLS12-377', 1, 64, 96, SVDW(one, zero, b1), b1, lambda P: mul(P, 1 - x), r)
+
+    # G2: y**2 = x**3 + 1/u (D-twist), cofactor cleared with [x**2-x-1]P + [x-1]psi(P) + psi2([2]P)
+    xi = one2.mk(0, 1)
+    b2 = one2 / xi
+    cx, cy = xi ** ((p - 1) // 3), xi ** ((p - 1) // 2)
+
+    def clear(P):
+        t = add(add(mul(P, x), neg(P)), psi(P, cx, cy))
+        res = add(add(mul(t, x), neg(P)), neg(psi(P, cx, cy)))
+        return add(res, psi(psi(mul(P, 2), cx, cy), cx, cy))
+
+    generate('../bls377', 'BLS12377', 'BLS12-377', 2, 64, 96, SVDW(one2, zero2, b2), b2, clear, r)
+
+
+def bw761():
+    x = 0x8508c00000000001
+    p = int('6891450384315732539396789682275657542479668912536150109513790160209623422243491736087683183289411687640'
+            '864567753786613451161759120554247759349511699125301598951605099378508850372543631423596795951899700429'
+            '969112842764913119068299')
+    r = 0x01ae3a4617c510eac63b05c06ca1493b1a22d9f300f5138f1ef3622fba094800170b5d44300000008508c00000000001
+    one, zero = Fp(1, p), Fp(0, p)
+
+    # cube root of 1 in fp, the endomorphism (x, y) -> (w*x, y) of G1
+    w = int('1968985824090209297278610739700577151397666382303825728450741611566800370218827257750865013421937292370'
+            '006175842381275743914023380727582819905021229583192207421122272650305267822868639090213645505120388400'
+            '344940985710520836292650')
+
+    # G1: y**2 = x**3 - 1, cofactor cleared with the polynomials c0(x) + c1(x)*phi in the powers of x
+    cc = [[16, 20, 7, -7], [-10, 19, 17, -20]]
+
+    def clear(P):
+        xP = [P]
+        for _ in range(3):
+            xP.append(mul(xP[-1], x))
+        res = []
+        for c in cc:
+            R = None
+            for i in range(4):
+                R = add(R, mul(xP[i], c[i]))
+            res.append(R)
+        phi = None if res[1] is None else (res[1][0] * w, res[1][1])
+        return add(phi, res[0])
+
+    b1 = -one
+    generate('../bw761', 'BW6761', 'BW6-761', 1, 112, 192, SVDW(one, zero, b1), b1, clear, r)
+
+
+if __name__ == '__main__':
+    os.chdir(os.path.dirname(os.path.abspath(__file__)))
+    bn256()
+    bls377()
+    bw761()
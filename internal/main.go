@@ -40,21 +40,43 @@ func main() {
 			os.Exit(-1)
 		}
 
-		if confs[i].CurveName != "bw761" {
+		confs[i].CoordType = "fp.Element"
+		confs[i].PointName = "g1"
+		if err := generator.GeneratePoint(confs[i]); err != nil {
+			fmt.Printf("\n%s\n", err.Error())
+			os.Exit(-1)
+		}
 
+		// bw761 G2 is defined over fp (degree 6 twist, degree 6 extension). Its types are generated
+		// like the other curves' ones, distinct from the G1 types (they used to be aliases of them), so that G2 gets
+		// its own generator, twist equation, subgroup check and encoding
+		if confs[i].CurveName == "bw761" {
 			confs[i].CoordType = "fp.Element"
-			confs[i].PointName = "g1"
-			if err := generator.GeneratePoint(confs[i]); err != nil {
-				fmt.Printf("\n%s\n", err.Error())
-				os.Exit(-1)
-			}
-
+		} else {
 			confs[i].CoordType = "E2"
-			confs[i].PointName = "g2"
-			if err := generator.GeneratePoint(confs[i]); err != nil {
-				fmt.Printf("\n%s\n", err.Error())
-				os.Exit(-1)
-			}
+		}
+		confs[i].PointName = "g2"
+		if err := generator.GeneratePoint(confs[i]); err != nil {
+			fmt.Printf("\n%s\n", err.Error())
+			os.Exit(-1)
+		}
+
+		if err := generator.GenerateMarshal(confs[i]); err != nil {
+			fmt.Printf("\n%s\n", err.Error())
+			os.Exit(-1)
+		}
+
+		if err := generator.GenerateMultiExp(confs[i]); err != nil {
+			fmt.Printf("\n%s\n", err.Error())
+			os.Exit(-1)
+		}
+
+		if err := generator.GenerateEngine(confs[i]); err != nil {
+			fmt.Printf("\n%s\n", err.Error())
+			os.Exit(-1)
+		}
+
+		if confs[i].CurveName != "bw761" {
 
 			if err := generator.GenerateFq12over6over2(confs[i]); err != nil {
 				fmt.Printf("\n%s\n", err.Error())
@@ -0,0 +1,365 @@
+package engine
+
+// Engine implements gurvy.Engine with the types of a curve package, registered in the curve registry
+const Engine = `
+
+import (
+	"math/big"
+
+	"github.com/consensys/gurvy"
+	"github.com/consensys/gurvy/{{ toLower .CurveName}}/fr"
+)
+
+func init() {
+	gurvy.Register(engine{})
+}
+
+// engine implements gurvy.Engine with the types of this package (cf gurvy.Get)
+type engine struct{}
+
+// scalar implements gurvy.Scalar
+type scalar struct {
+	e fr.Element
+}
+
+// g1 implements gurvy.G1
+type g1 struct {
+	p G1Jac
+}
+
+// g2 implements gurvy.G2
+type g2 struct {
+	p G2Jac
+}
+
+// gt implements gurvy.GT
+type gt struct {
+	e GT
+}
+
+func (engine) ID() gurvy.ID {
+	return ID
+}
+
+func (engine) Params() gurvy.Params {
+	return CurveParams()
+}
+
+func (engine) NewScalar() gurvy.Scalar {
+	return &scalar{}
+}
+
+func (engine) NewG1() gurvy.G1 {
+	return &g1{p: g1Infinity}
+}
+
+func (engine) NewG2() gurvy.G2 {
+	return &g2{p: g2Infinity}
+}
+
+func (engine) NewGT() gurvy.GT {
+	var res gt
+	res.e.SetOne()
+	return &res
+}
+
+func (engine) G1Generator() gurvy.G1 {
+	return &g1{p: g1Gen}
+}
+
+func (engine) G2Generator() gurvy.G2 {
+	return &g2{p: g2Gen}
+}
+
+func (e engine) Pair(P []gurvy.G1, Q []gurvy.G2) (gurvy.GT, error) {
+	if len(P) != len(Q) {
+		return nil, ErrPairingInputSize
+	}
+	_P := make([]G1Affine, len(P))
+	_Q := make([]G2Affine, len(Q))
+	for i := range P {
+		_P[i].FromJacobian(&P[i].(*g1).p)
+		_Q[i].FromJacobian(&Q[i].(*g2).p)
+	}
+	res, err := Pair(_P, _Q)
+	if err != nil {
+		return nil, err
+	}
+	return &gt{e: res}, nil
+}
+
+func (e engine) PairingCheck(P []gurvy.G1, Q []gurvy.G2) (bool, error) {
+	res, err := e.Pair(P, Q)
+	if err != nil {
+		return false, err
+	}
+	return res.IsOne(), nil
+}
+
+func (z *scalar) Set(a gurvy.Scalar) gurvy.Scalar {
+	z.e.Set(&a.(*scalar).e)
+	return z
+}
+
+func (z *scalar) SetUint64(v uint64) gurvy.Scalar {
+	z.e.SetUint64(v)
+	return z
+}
+
+func (z *scalar) SetBigInt(v *big.Int) gurvy.Scalar {
+	z.e.SetBigInt(v)
+	return z
+}
+
+func (z *scalar) SetRandom() gurvy.Scalar {
+	z.e.SetRandom()
+	return z
+}
+
+func (z *scalar) Add(a, b gurvy.Scalar) gurvy.Scalar {
+	z.e.Add(&a.(*scalar).e, &b.(*scalar).e)
+	return z
+}
+
+func (z *scalar) Sub(a, b gurvy.Scalar) gurvy.Scalar {
+	z.e.Sub(&a.(*scalar).e, &b.(*scalar).e)
+	return z
+}
+
+func (z *scalar) Mul(a, b gurvy.Scalar) gurvy.Scalar {
+	z.e.Mul(&a.(*scalar).e, &b.(*scalar).e)
+	return z
+}
+
+func (z *scalar) Neg(a gurvy.Scalar) gurvy.Scalar {
+	z.e.Neg(&a.(*scalar).e)
+	return z
+}
+
+func (z *scalar) Inverse(a gurvy.Scalar) gurvy.Scalar {
+	z.e.Inverse(&a.(*scalar).e)
+	return z
+}
+
+func (z *scalar) Equal(a gurvy.Scalar) bool {
+	return z.e.Equal(&a.(*scalar).e)
+}
+
+func (z *scalar) IsZero() bool {
+	return z.e.IsZero()
+}
+
+func (z *scalar) BigInt(res *big.Int) *big.Int {
+	return z.e.ToBigIntRegular(res)
+}
+
+func (z *scalar) Bytes() []byte {
+	return z.e.Bytes()
+}
+
+func (z *scalar) SetBytes(e []byte) gurvy.Scalar {
+	z.e.SetBytes(e)
+	return z
+}
+
+func (z *scalar) String() string {
+	return z.e.String()
+}
+
+func (p *g1) Set(a gurvy.G1) gurvy.G1 {
+	p.p.Set(&a.(*g1).p)
+	return p
+}
+
+func (p *g1) SetInfinity() gurvy.G1 {
+	p.p.Set(&g1Infinity)
+	return p
+}
+
+func (p *g1) Add(a, b gurvy.G1) gurvy.G1 {
+	var res G1Jac
+	res.Set(&a.(*g1).p)
+	res.AddAssign(&b.(*g1).p)
+	p.p.Set(&res)
+	return p
+}
+
+func (p *g1) Double(a gurvy.G1) gurvy.G1 {
+	p.p.Double(&a.(*g1).p)
+	return p
+}
+
+func (p *g1) Neg(a gurvy.G1) gurvy.G1 {
+	p.p.Neg(&a.(*g1).p)
+	return p
+}
+
+func (p *g1) ScalarMul(a gurvy.G1, s gurvy.Scalar) gurvy.G1 {
+	var e big.Int
+	s.(*scalar).e.ToBigIntRegular(&e)
+	p.p.mulWindowed(&a.(*g1).p, &e)
+	return p
+}
+
+func (p *g1) Equal(a gurvy.G1) bool {
+	return p.p.Equal(&a.(*g1).p)
+}
+
+func (p *g1) IsInfinity() bool {
+	return p.p.Z.IsZero()
+}
+
+func (p *g1) IsInSubGroup() bool {
+	return p.p.IsInSubGroup()
+}
+
+func (p *g1) Bytes() []byte {
+	var a G1Affine
+	a.FromJacobian(&p.p)
+	res := a.Bytes()
+	return res[:]
+}
+
+func (p *g1) SetBytes(buf []byte) (int, error) {
+	var a G1Affine
+	n, err := a.SetBytes(buf)
+	if err != nil {
+		return n, err
+	}
+	var _p G1Jac
+	_p.FromAffine(&a)
+	if !_p.IsInSubGroup() {
+		return n, ErrPointNotInSubGroup
+	}
+	p.p.Set(&_p)
+	return n, nil
+}
+
+func (p *g1) String() string {
+	var a G1Affine
+	a.FromJacobian(&p.p)
+	return a.String()
+}
+
+func (p *g2) Set(a gurvy.G2) gurvy.G2 {
+	p.p.Set(&a.(*g2).p)
+	return p
+}
+
+func (p *g2) SetInfinity() gurvy.G2 {
+	p.p.Set(&g2Infinity)
+	return p
+}
+
+func (p *g2) Add(a, b gurvy.G2) gurvy.G2 {
+	var res G2Jac
+	res.Set(&a.(*g2).p)
+	res.AddAssign(&b.(*g2).p)
+	p.p.Set(&res)
+	return p
+}
+
+func (p *g2) Double(a gurvy.G2) gurvy.G2 {
+	p.p.Double(&a.(*g2).p)
+	return p
+}
+
+func (p *g2) Neg(a gurvy.G2) gurvy.G2 {
+	p.p.Neg(&a.(*g2).p)
+	return p
+}
+
+func (p *g2) ScalarMul(a gurvy.G2, s gurvy.Scalar) gurvy.G2 {
+	var e big.Int
+	s.(*scalar).e.ToBigIntRegular(&e)
+	p.p.mulWindowed(&a.(*g2).p, &e)
+	return p
+}
+
+func (p *g2) Equal(a gurvy.G2) bool {
+	return p.p.Equal(&a.(*g2).p)
+}
+
+func (p *g2) IsInfinity() bool {
+	return p.p.Z.IsZero()
+}
+
+func (p *g2) IsInSubGroup() bool {
+	return p.p.IsInSubGroup()
+}
+
+func (p *g2) Bytes() []byte {
+	var a G2Affine
+	a.FromJacobian(&p.p)
+	res := a.Bytes()
+	return res[:]
+}
+
+func (p *g2) SetBytes(buf []byte) (int, error) {
+	var a G2Affine
+	n, err := a.SetBytes(buf)
+	if err != nil {
+		return n, err
+	}
+	var _p G2Jac
+	_p.FromAffine(&a)
+	if !_p.IsInSubGroup() {
+		return n, ErrPointNotInSubGroup
+	}
+	p.p.Set(&_p)
+	return n, nil
+}
+
+func (p *g2) String() string {
+	var a G2Affine
+	a.FromJacobian(&p.p)
+	return a.String()
+}
+
+func (z *gt) Set(a gurvy.GT) gurvy.GT {
+	z.e.Set(&a.(*gt).e)
+	return z
+}
+
+func (z *gt) SetOne() gurvy.GT {
+	z.e.SetOne()
+	return z
+}
+
+func (z *gt) Mul(a, b gurvy.GT) gurvy.GT {
+	z.e.Mul(&a.(*gt).e, &b.(*gt).e)
+	return z
+}
+
+func (z *gt) Square(a gurvy.GT) gurvy.GT {
+	z.e.Square(&a.(*gt).e)
+	return z
+}
+
+func (z *gt) Inverse(a gurvy.GT) gurvy.GT {
+	z.e.Inverse(&a.(*gt).e)
+	return z
+}
+
+func (z *gt) Exp(a gurvy.GT, s gurvy.Scalar) gurvy.GT {
+	z.e.Exp(&a.(*gt).e, &s.(*scalar).e)
+	return z
+}
+
+func (z *gt) Equal(a gurvy.GT) bool {
+	return z.e.Equal(&a.(*gt).e)
+}
+
+func (z *gt) IsOne() bool {
+	return z.e.IsOne()
+}
+
+func (z *gt) Bytes() []byte {
+	res := z.e.Bytes()
+	return res[:]
+}
+
+func (z *gt) SetBytes(e []byte) error {
+	return z.e.SetBytes(e)
+}
+`
@@ -135,6 +135,17 @@ func TestE2ReceiverIsOperand(t *testing.T) {
 		genA,
 	))
 
+	properties.Property("Having the receiver as operand (Sqrt) should output the same result", prop.ForAll(
+		func(a *E2) bool {
+			var b, d E2
+			b.Square(a)
+			d.Sqrt(&b)
+			b.Sqrt(&b)
+			return b.Equal(&d)
+		},
+		genA,
+	))
+
 	properties.Property("Having the receiver as operand (mul by element) should output the same result", prop.ForAll(
 		func(a *E2, b fp.Element) bool {
 			var c E2
@@ -310,6 +321,25 @@ func TestE2State(t *testing.T) {
 		},
 	}
 
+	sqrtsquare := &commands.ProtoCommand{
+		Name: "SQRTSQUARE",
+		RunFunc: func(systemUnderTest commands.SystemUnderTest) commands.Result {
+			var a, b E2
+			a.Square(systemUnderTest.(*E2))
+			if b.Sqrt(&a) == nil {
+				return false
+			}
+			b.Square(&b)
+			return a.Equal(&b)
+		},
+		PostConditionFunc: func(state commands.State, result commands.Result) *gopter.PropResult {
+			if result.(bool) {
+				return &gopter.PropResult{Status: gopter.PropTrue}
+			}
+			return &gopter.PropResult{Status: gopter.PropFalse}
+		},
+	}
+
 	e2commands := &commands.ProtoCommands{
 		NewSystemUnderTestFunc: func(_ commands.State) commands.SystemUnderTest {
 			var a E2
@@ -318,7 +348,7 @@ func TestE2State(t *testing.T) {
 		},
 		InitialStateGen: gen.Const(false),
 		GenCommandFunc: func(state commands.State) gopter.Gen {
-			return gen.OneConstOf(subadd, mulinverse, inversetwice, negtwice, squaremul, mulbyelmtinverse, doublemul, mulbynonres, conjugate)
+			return gen.OneConstOf(subadd, mulinverse, inversetwice, negtwice, squaremul, mulbyelmtinverse, doublemul, mulbynonres, conjugate, sqrtsquare)
 		},
 	}
 
@@ -408,6 +438,16 @@ func BenchmarkE2MulNonResInv(b *testing.B) {
 	}
 }
 
+func BenchmarkE2Sqrt(b *testing.B) {
+	var a, c E2
+	a.SetRandom()
+	a.Square(&a)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Sqrt(&a)
+	}
+}
+
 func BenchmarkE2Conjugate(b *testing.B) {
 	var a E2
 	a.SetRandom()
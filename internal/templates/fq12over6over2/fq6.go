@@ -39,6 +39,19 @@ func (z *E6) SetOne() *E6 {
 	return z
 }
 
+// SetZero sets z to 0 and returns z
+func (z *E6) SetZero() *E6 {
+	z.B0.SetZero()
+	z.B1.SetZero()
+	z.B2.SetZero()
+	return z
+}
+
+// IsZero returns true if z is 0
+func (z *E6) IsZero() bool {
+	return z.B0.IsZero() && z.B1.IsZero() && z.B2.IsZero()
+}
+
 // SetRandom set z to a random elmt
 func (z *E6) SetRandom() *E6 {
 	z.B0.SetRandom()
@@ -186,4 +199,64 @@ func (z *E6) Inverse(x *E6) *E6 {
 	return z
 }
 
- `
+// MulBy01 sets z to x*(c0 + c1*v), returns z
+// (multiplication by a sparse element, 5 E2 multiplications)
+func (z *E6) MulBy01(x *E6, c0, c1 *E2) *E6 {
+	var a, b, t0, t1, t2, tmp E2
+	a.Mul(&x.B0, c0)
+	b.Mul(&x.B1, c1)
+
+	// t0 = x0*c0 + ξ*x2*c1
+	t0.Mul(&x.B2, c1).MulByNonResidue(&t0).Add(&t0, &a)
+
+	// t1 = (x0 + x1)*(c0 + c1) - x0*c0 - x1*c1
+	t1.Add(c0, c1)
+	tmp.Add(&x.B0, &x.B1)
+	t1.Mul(&t1, &tmp).Sub(&t1, &a).Sub(&t1, &b)
+
+	// t2 = x2*c0 + x1*c1
+	t2.Mul(&x.B2, c0).Add(&t2, &b)
+
+	z.B0.Set(&t0)
+	z.B1.Set(&t1)
+	z.B2.Set(&t2)
+	return z
+}
+
+// MulBy02 sets z to x*(c0 + c2*v**2), returns z
+// (multiplication by a sparse element, 5 E2 multiplications)
+func (z *E6) MulBy02(x *E6, c0, c2 *E2) *E6 {
+	var a, b, t0, t1, t2, tmp E2
+	a.Mul(&x.B0, c0)
+	b.Mul(&x.B2, c2)
+
+	// t0 = x0*c0 + ξ*x1*c2
+	t0.Mul(&x.B1, c2).MulByNonResidue(&t0).Add(&t0, &a)
+
+	// t1 = x1*c0 + ξ*x2*c2
+	t1.MulByNonResidue(&b)
+	tmp.Mul(&x.B1, c0)
+	t1.Add(&t1, &tmp)
+
+	// t2 = (x0 + x2)*(c0 + c2) - x0*c0 - x2*c2
+	t2.Add(c0, c2)
+	tmp.Add(&x.B0, &x.B2)
+	t2.Mul(&t2, &tmp).Sub(&t2, &a).Sub(&t2, &b)
+
+	z.B0.Set(&t0)
+	z.B1.Set(&t1)
+	z.B2.Set(&t2)
+	return z
+}
+
+// MulByE2 sets z to x*c, returns z
+func (z *E6) MulByE2(x *E6, c *E2) *E6 {
+	var yCopy E2
+	yCopy.Set(c)
+	z.B0.Mul(&x.B0, &yCopy)
+	z.B1.Mul(&x.B1, &yCopy)
+	z.B2.Mul(&x.B2, &yCopy)
+	return z
+}
+
+`
@@ -255,9 +255,64 @@ func TestE6State(t *testing.T) {
 
 }
 
+func TestE6MulBySparse(t *testing.T) {
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	genA := GenE6()
+	genB := GenE2()
+	genC := GenE2()
+
+	properties.Property("MulBy01 should match Mul", prop.ForAll(
+		func(a *E6, c0, c1 *E2) bool {
+			var b, c, d E6
+			b.B0.Set(c0)
+			b.B1.Set(c1)
+			c.Mul(a, &b)
+			d.MulBy01(a, c0, c1)
+			return c.Equal(&d)
+		},
+		genA,
+		genB,
+		genC,
+	))
+
+	properties.Property("MulBy02 should match Mul", prop.ForAll(
+		func(a *E6, c0, c2 *E2) bool {
+			var b, c, d E6
+			b.B0.Set(c0)
+			b.B2.Set(c2)
+			c.Mul(a, &b)
+			d.MulBy02(a, c0, c2)
+			return c.Equal(&d)
+		},
+		genA,
+		genB,
+		genC,
+	))
+
+	properties.Property("MulByE2 should match Mul", prop.ForAll(
+		func(a *E6, c0 *E2) bool {
+			var b, c, d E6
+			b.B0.Set(c0)
+			c.Mul(a, &b)
+			d.MulByE2(a, c0)
+			return c.Equal(&d)
+		},
+		genA,
+		genB,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
 // ------------------------------------------------------------
 // benches
 
+
 func BenchmarkE6Add(b *testing.B) {
 	var a, c E6
 	a.SetRandom()
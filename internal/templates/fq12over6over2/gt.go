@@ -0,0 +1,249 @@
+package fq12over6over2
+
+// Gt GT wrapper of the target group of the pairing
+const Gt = `
+import (
+	"errors"
+	"io"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gurvy/{{toLower .CurveName}}/fp"
+	"github.com/consensys/gurvy/{{toLower .CurveName}}/fr"
+)
+
+// GT target group of the pairing: the subgroup of order r of the cyclotomic subgroup of E12
+//
+// A GT element is obtained from Pair, from FinalExponentiation or from SetE12 / SetBytes, which check
+// the subgroup membership, so that an arbitrary E12 can not be mistaken for a pairing output.
+// The zero value of GT is the identity
+type GT struct {
+	e E12 // the zero E12 stands for the identity, cf value
+}
+
+// ErrNotInGT is returned when an E12 element is not in the target group of the pairing
+var ErrNotInGT = errors.New("invalid GT element: not in the subgroup of order r of the cyclotomic subgroup")
+
+// FinalExponentiation sets z to the final exponentiation of x (cf PairingResult.FinalExponentiation), returns z
+func (z *GT) FinalExponentiation(x *PairingResult) *GT {
+	z.e.FinalExponentiation(x)
+	return z
+}
+
+// SetE12 sets z to x and returns nil if x is in GT, ErrNotInGT otherwise (z is left unchanged)
+func (z *GT) SetE12(x *E12) error {
+	if !x.IsInSubGroup() {
+		return ErrNotInGT
+	}
+	z.e.Set(x)
+	return nil
+}
+
+// E12 returns the value of z in E12
+func (z *GT) E12() E12 {
+	return z.value()
+}
+
+// value returns the value of z in E12, the identity if z.e is zero (zero value of GT)
+func (z *GT) value() E12 {
+	var res E12
+	if z.e.Equal(&res) {
+		res.SetOne()
+		return res
+	}
+	return z.e
+}
+
+// Set sets z to x, returns z
+func (z *GT) Set(x *GT) *GT {
+	z.e.Set(&x.e)
+	return z
+}
+
+// SetOne sets z to the identity of GT, returns z
+func (z *GT) SetOne() *GT {
+	z.e.SetOne()
+	return z
+}
+
+// IsOne returns true if z is the identity of GT
+func (z *GT) IsOne() bool {
+	var one E12
+	one.SetOne()
+	e := z.value()
+	return e.Equal(&one)
+}
+
+// Equal returns true if z equals x
+func (z *GT) Equal(x *GT) bool {
+	a, b := z.value(), x.value()
+	return a.Equal(&b)
+}
+
+// Mul sets z to x*y, returns z
+func (z *GT) Mul(x, y *GT) *GT {
+	a, b := x.value(), y.value()
+	z.e.Mul(&a, &b)
+	return z
+}
+
+// Square sets z to x**2, returns z
+func (z *GT) Square(x *GT) *GT {
+	a := x.value()
+	z.e.CyclotomicSquare(&a)
+	return z
+}
+
+// Inverse sets z to x**-1, returns z
+// (GT elements are unitary, the inverse is the conjugate)
+func (z *GT) Inverse(x *GT) *GT {
+	a := x.value()
+	z.e.Conjugate(&a)
+	return z
+}
+
+// Exp sets z to x**k, returns z
+//
+// k is written in base |gtLambda|: k = k0 + k1*|gtLambda| + ... with 0 <= ki < |gtLambda|,
+// so that x**k = x**k0 * Frob(x)**(±k1) * ..., the Frobenius being almost free.
+// The {{if eq .CurveName "bn256"}}3{{else}}4{{end}} exponentiations share the same squarings (Straus-Shamir trick).
+func (z *GT) Exp(x *GT, k *fr.Element) *GT {
+
+	const nbDigits = {{if eq .CurveName "bn256"}}3{{else}}4{{end}}
+
+	var s, base big.Int
+	k.ToBigIntRegular(&s)
+	base.Abs(&gtLambda)
+
+	var digits [nbDigits]big.Int
+	for i := 0; i < nbDigits; i++ {
+		s.DivMod(&s, &base, &digits[i])
+	}
+
+	// bases[i] = Frob^i(x) = x**(gtLambda**i) = x**(±|gtLambda|**i)
+	var bases [nbDigits]E12
+	bases[0] = x.value()
+	bases[1].Frobenius(&bases[0])
+	bases[2].FrobeniusSquare(&bases[0])
+{{- if ne .CurveName "bn256"}}
+	bases[3].FrobeniusCube(&bases[0])
+{{- end}}
+	if gtLambda.Sign() < 0 {
+		for i := 1; i < nbDigits; i += 2 {
+			bases[i].Conjugate(&bases[i])
+		}
+	}
+
+	// table[i] = product of the bases[j] for the bits j set in i
+	var table [1 << nbDigits]E12
+	table[0].SetOne()
+	for i := 1; i < len(table); i++ {
+		table[i].Mul(&table[i&(i-1)], &bases[bits.TrailingZeros(uint(i))])
+	}
+
+	var res E12
+	res.SetOne()
+	for i := base.BitLen() - 1; i >= 0; i-- {
+		res.CyclotomicSquare(&res)
+		var idx uint
+		for j := 0; j < nbDigits; j++ {
+			idx |= digits[j].Bit(i) << uint(j)
+		}
+		if idx != 0 {
+			res.Mul(&res, &table[idx])
+		}
+	}
+
+	z.e.Set(&res)
+	return z
+}
+
+// Bytes returns the binary encoding of z (cf E12.Bytes)
+func (z *GT) Bytes() [SizeOfGT]byte {
+	e := z.value()
+	return e.Bytes()
+}
+
+// SetBytes interprets e as the bytes of a GT element (cf E12.SetBytes)
+// it returns ErrNotInGT if the decoded element is not in GT
+func (z *GT) SetBytes(e []byte) error {
+	var res E12
+	if err := res.SetBytes(e); err != nil {
+		return err
+	}
+	return z.SetE12(&res)
+}
+
+// SizeOfGTCompressed represents the size in bytes that a GT element needs in compressed binary form
+const SizeOfGTCompressed = fp.Limbs * 8 * 4
+
+// CompressedBytes returns the binary encoding of the T6 compressed form (y1, y2) of z (cf E12.CompressT6),
+// a third of the size of Bytes, the fp.Element coordinates are stored in order y1.A0, y1.A1, y2.A0, y2.A1
+// it returns ErrTorusExceptional if z has no T6 compressed form
+func (z *GT) CompressedBytes() (r [SizeOfGTCompressed]byte, err error) {
+	v := z.value()
+	y, err := v.CompressT6()
+	if err != nil {
+		return
+	}
+	const fpSize = fp.Limbs * 8
+	for i, e := range [4]*fp.Element{&y[0].A0, &y[0].A1, &y[1].A0, &y[1].A1} {
+		copy(r[i*fpSize:(i+1)*fpSize], e.Bytes())
+	}
+	return
+}
+
+// SetCompressedBytes interprets e as the bytes of a compressed GT element (cf CompressedBytes)
+// it returns ErrTorusExceptional if e is not a valid T6 compressed form, ErrNotInGT if the decompressed
+// element is not in GT
+func (z *GT) SetCompressedBytes(e []byte) error {
+	if len(e) < SizeOfGTCompressed {
+		return io.ErrShortBuffer
+	}
+	const fpSize = fp.Limbs * 8
+	var y [2]E2
+	for i, c := range [4]*fp.Element{&y[0].A0, &y[0].A1, &y[1].A0, &y[1].A1} {
+		if err := setElementBytes(c, e[i*fpSize:(i+1)*fpSize]); err != nil {
+			return err
+		}
+	}
+	var res E12
+	if err := res.DecompressT6(&y); err != nil {
+		return err
+	}
+	return z.SetE12(&res)
+}
+
+// IsInCyclotomicSubGroup returns true if z is in the cyclotomic subgroup of E12,
+// of order p**4 - p**2 + 1, i.e. if z != 0 and z * Frob**4(z) = Frob**2(z)
+func (z *E12) IsInCyclotomicSubGroup() bool {
+	if z.IsZero() {
+		return false
+	}
+	var a, b E12
+	b.FrobeniusSquare(z)
+	a.FrobeniusSquare(&b).Mul(&a, z)
+	return a.Equal(&b)
+}
+
+// IsInSubGroup returns true if z is in GT, the subgroup of order r of the cyclotomic subgroup
+// For z in the cyclotomic subgroup, it checks that Frob(z) = z**gtLambda: the gcd of p - gtLambda
+// and p**4 - p**2 + 1 is r, so this holds exactly for the elements of order r
+// (Expt requires an input in the cyclotomic subgroup, so that check, which rejects 0, comes first)
+func (z *E12) IsInSubGroup() bool {
+	if !z.IsInCyclotomicSubGroup() {
+		return false
+	}
+	var a, b E12
+	a.Frobenius(z)
+{{- if eq .CurveName "bn256"}}
+	var c E12
+	b.Expt(z).Expt(&b)                 // z**(x**2)
+	c.CyclotomicSquare(&b)             // z**(2x**2)
+	b.Mul(&b, &c).CyclotomicSquare(&b) // z**(6x**2)
+{{- else}}
+	b.Expt(z)
+{{- end}}
+	return a.Equal(&b)
+}
+`
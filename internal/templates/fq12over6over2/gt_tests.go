@@ -0,0 +1,226 @@
+package fq12over6over2
+
+// GtTests tests of the GT wrapper
+const GtTests = `
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gurvy/{{toLower .CurveName}}/fr"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+// ------------------------------------------------------------
+// tests
+
+func TestGT(t *testing.T) {
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 20
+
+	properties := gopter.NewProperties(parameters)
+
+	genA := GenE12()
+	genR := GenFr()
+
+	properties.Property("FinalExponentiation should output an element of GT", prop.ForAll(
+		func(a *E12) bool {
+			var res GT
+			res.FinalExponentiation(a)
+			e := res.E12()
+			return e.IsInCyclotomicSubGroup() && e.IsInSubGroup()
+		},
+		genA,
+	))
+
+	properties.Property("a random E12 should not be in GT", prop.ForAll(
+		func(a *E12) bool {
+			var res GT
+			return !a.IsInCyclotomicSubGroup() && res.SetE12(a) == ErrNotInGT
+		},
+		genA,
+	))
+
+	properties.Property("the easy part of the final exponentiation should not output an element of GT", prop.ForAll(
+		func(a *E12) bool {
+			// a**((p**6-1)*(p**2+1)) is in the cyclotomic subgroup, of order p**4 - p**2 + 1 = r*h
+			var b E12
+			b.Inverse(a)
+			a.Conjugate(a).Mul(a, &b)
+			b.FrobeniusSquare(a)
+			a.Mul(a, &b)
+			return a.IsInCyclotomicSubGroup() && !a.IsInSubGroup()
+		},
+		genA,
+	))
+
+	properties.Property("Exp should match E12.Exp", prop.ForAll(
+		func(a *E12, k fr.Element) bool {
+			var x, res GT
+			var expected E12
+			var e big.Int
+			x.FinalExponentiation(a)
+			res.Exp(&x, &k)
+			k.ToBigIntRegular(&e)
+			expected.Exp(&x.e, e)
+			return res.e.Equal(&expected)
+		},
+		genA,
+		genR,
+	))
+
+	properties.Property("Exp by r-1 should output the inverse", prop.ForAll(
+		func(a *E12) bool {
+			var x, res, inv GT
+			var k fr.Element
+			x.FinalExponentiation(a)
+			k.SetOne().Neg(&k)
+			res.Exp(&x, &k)
+			inv.Inverse(&x)
+			return res.Equal(&inv) && res.Mul(&res, &x).IsOne()
+		},
+		genA,
+	))
+
+	properties.Property("Square should match Mul", prop.ForAll(
+		func(a *E12) bool {
+			var x, s, m GT
+			x.FinalExponentiation(a)
+			s.Square(&x)
+			m.Mul(&x, &x)
+			return s.Equal(&m)
+		},
+		genA,
+	))
+
+	properties.Property("the zero value of GT should be the identity", prop.ForAll(
+		func(a *E12) bool {
+			var zero, one, x, res GT
+			one.SetOne()
+			x.FinalExponentiation(a)
+			res.Mul(&x, &zero)
+			return zero.IsOne() && zero.Equal(&one) && one.Equal(&zero) &&
+				res.Equal(&x) && zero.Bytes() == one.Bytes()
+		},
+		genA,
+	))
+
+	properties.Property("SetBytes(Bytes()) should stay the same", prop.ForAll(
+		func(a *E12) bool {
+			var x, res GT
+			x.FinalExponentiation(a)
+			buf := x.Bytes()
+			if err := res.SetBytes(buf[:]); err != nil {
+				return false
+			}
+			return res.Equal(&x)
+		},
+		genA,
+	))
+
+	properties.Property("SetCompressedBytes(CompressedBytes()) should stay the same", prop.ForAll(
+		func(a *E12) bool {
+			var x, res GT
+			x.FinalExponentiation(a)
+			buf, err := x.CompressedBytes()
+			if err != nil {
+				return false
+			}
+			if err := res.SetCompressedBytes(buf[:]); err != nil {
+				return false
+			}
+			return res.Equal(&x)
+		},
+		genA,
+	))
+
+	properties.Property("SetCompressedBytes should reject an element not in GT", prop.ForAll(
+		func(a *E12) bool {
+			// a is in the cyclotomic subgroup but (most likely) not of order r
+			var b E12
+			var res GT
+			b.Inverse(a)
+			a.Conjugate(a).Mul(a, &b)
+			b.FrobeniusSquare(a)
+			a.Mul(a, &b)
+			y, _ := a.CompressT6()
+			buf := append(y[0].A0.Bytes(), y[0].A1.Bytes()...)
+			buf = append(buf, y[1].A0.Bytes()...)
+			buf = append(buf, y[1].A1.Bytes()...)
+			return res.SetCompressedBytes(buf) == ErrNotInGT
+		},
+		genA,
+	))
+
+	properties.Property("SetBytes should reject an element not in GT", prop.ForAll(
+		func(a *E12) bool {
+			var res GT
+			buf := a.Bytes()
+			return res.SetBytes(buf[:]) == ErrNotInGT
+		},
+		genA,
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestGTIdentityEncoding(t *testing.T) {
+	var one, res GT
+	one.SetOne()
+	buf := one.Bytes()
+	if err := res.SetBytes(buf[:]); err != nil || !res.IsOne() {
+		t.Fatal("SetBytes(Bytes()) of the identity should stay the same")
+	}
+
+	// the zero E12 is not in GT, the identity has a single encoding
+	var zero [SizeOfGT]byte
+	if err := res.SetBytes(zero[:]); err != ErrNotInGT {
+		t.Fatal("SetBytes should reject the zero E12")
+	}
+	var e E12
+	if err := res.SetE12(&e); err != ErrNotInGT {
+		t.Fatal("SetE12 should reject the zero E12")
+	}
+}
+
+// ------------------------------------------------------------
+// benches
+
+func BenchmarkGTExp(b *testing.B) {
+	var a E12
+	var x, res GT
+	var k fr.Element
+	var e big.Int
+	a.SetRandom()
+	x.FinalExponentiation(&a)
+	k.SetRandom()
+	k.ToBigIntRegular(&e)
+
+	b.Run("GT.Exp", func(b *testing.B) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			res.Exp(&x, &k)
+		}
+	})
+
+	b.Run("E12.Exp", func(b *testing.B) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			a.Exp(&x.e, e)
+		}
+	})
+}
+
+func BenchmarkGTIsInSubGroup(b *testing.B) {
+	var a E12
+	var x GT
+	a.SetRandom()
+	x.FinalExponentiation(&a)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.e.IsInSubGroup()
+	}
+}
+`
@@ -0,0 +1,1307 @@
+package point
+
+// Marshal binary encoding of G1Affine and G2Affine
+// CoordType is the coordinate type of G2 (fp.Element or E2)
+const Marshal = `
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gurvy"
+	"github.com/consensys/gurvy/{{ toLower .CurveName}}/fp"
+	"github.com/consensys/gurvy/{{ toLower .CurveName}}/fr"
+	"github.com/consensys/gurvy/utils/parallel"
+)
+
+// SizeOfG1AffineCompressed represents the size in bytes that a G1Affine needs in binary form, compressed
+const SizeOfG1AffineCompressed = fp.Limbs * 8
+
+// SizeOfG1AffineUncompressed represents the size in bytes that a G1Affine needs in binary form, uncompressed
+const SizeOfG1AffineUncompressed = SizeOfG1AffineCompressed * 2
+
+// SizeOfG2AffineCompressed represents the size in bytes that a G2Affine needs in binary form, compressed
+{{- if eq .CoordType "E2" }}
+const SizeOfG2AffineCompressed = fp.Limbs * 8 * 2
+{{- else }}
+const SizeOfG2AffineCompressed = fp.Limbs * 8
+{{- end }}
+
+// SizeOfG2AffineUncompressed represents the size in bytes that a G2Affine needs in binary form, uncompressed
+const SizeOfG2AffineUncompressed = SizeOfG2AffineCompressed * 2
+
+{{- if eq .CurveName "bls381" }}
+// the 3 most significant bits of the first byte of an encoded point carry its encoding,
+// following the Zcash serialization format of BLS12-381 points
+// https://github.com/zkcrypto/pairing/tree/master/src/bls12_381#serialization
+//
+// 	bit 7: compression flag, set if only x is stored
+// 	bit 6: infinity flag, set if the point is the point at infinity (all other bits are zeroes)
+// 	bit 5: sort flag, set if y is the lexicographically largest square root (compressed points only)
+const (
+	mMask                 byte = 0b111 << 5
+	mUncompressed         byte = 0b000 << 5
+	mUncompressedInfinity byte = 0b010 << 5
+	mCompressedSmallest   byte = 0b100 << 5
+	mCompressedLargest    byte = 0b101 << 5
+	mCompressedInfinity   byte = 0b110 << 5
+)
+{{- else }}
+// the most significant bits of the first byte of an encoded point carry its encoding
+//
+// 	0b00: uncompressed, x and y follow (the point at infinity is all zeroes)
+// 	0b01: compressed point at infinity, all other bits are zeroes
+// 	0b10: compressed, x follows and y is the lexicographically smallest square root
+// 	0b11: compressed, x follows and y is the lexicographically largest square root
+const (
+	mMask               byte = 0b11 << 6
+	mUncompressed       byte = 0b00 << 6
+	mCompressedInfinity byte = 0b01 << 6
+	mCompressedSmallest byte = 0b10 << 6
+	mCompressedLargest  byte = 0b11 << 6
+)
+{{- end }}
+
+var (
+	// ErrNonCanonicalCoordinate is returned when an encoded coordinate is not reduced modulo q
+	ErrNonCanonicalCoordinate = errors.New("invalid encoding: coordinate is not reduced modulo q")
+	// ErrPointNotOnCurve is returned when the decoded point doesn't satisfy the curve equation
+	ErrPointNotOnCurve = errors.New("invalid encoding: point is not on the curve")
+	// ErrPointNotInSubGroup is returned when the decoded point is not in the subgroup of order r
+	ErrPointNotInSubGroup = errors.New("invalid encoding: point is not in the subgroup of order r")
+	// ErrNonCanonicalScalar is returned when an encoded fr.Element is not reduced modulo r
+	ErrNonCanonicalScalar = errors.New("invalid encoding: fr.Element is not reduced modulo r")
+	// ErrInvalidInfinityEncoding is returned when the infinity flag is set and other bits are not zeroes
+	ErrInvalidInfinityEncoding = errors.New("invalid encoding: infinity flag set with non zero bits")
+	{{- if eq .CurveName "bls381" }}
+	// ErrInvalidFlags is returned when the encoding flags are not a valid combination
+	ErrInvalidFlags = errors.New("invalid encoding: invalid combination of flags")
+	{{- end }}
+)
+
+// Bytes returns the compressed binary encoding of p
+//
+// x is stored in big endian, the most significant bits of the first byte
+// encode which square root y is (or that p is the point at infinity)
+func (p *G1Affine) Bytes() (res [SizeOfG1AffineCompressed]byte) {
+	if p.IsInfinity() {
+		res[0] = mCompressedInfinity
+		return
+	}
+
+	msbMask := mCompressedSmallest
+	if lexicographicallyLargest(&p.Y) {
+		msbMask = mCompressedLargest
+	}
+
+	copy(res[:], p.X.Bytes())
+	res[0] |= msbMask
+
+	return
+}
+
+// RawBytes returns the uncompressed binary encoding of p
+//
+// x and y are stored in big endian {{- if ne .CurveName "bls381" }}, the point at infinity is encoded as zeroes{{- end }}
+func (p *G1Affine) RawBytes() (res [SizeOfG1AffineUncompressed]byte) {
+	if p.IsInfinity() {
+		{{- if eq .CurveName "bls381" }}
+			res[0] = mUncompressedInfinity
+		{{- end }}
+		return
+	}
+
+	copy(res[:SizeOfG1AffineCompressed], p.X.Bytes())
+	copy(res[SizeOfG1AffineCompressed:], p.Y.Bytes())
+	res[0] |= mUncompressed
+
+	return
+}
+
+// SetBytes sets p from its binary encoding (compressed or uncompressed, see Bytes and RawBytes)
+// and returns the number of bytes read from buf
+//
+// it returns an error if buf is too short, if a coordinate is not reduced modulo q,
+// if the flags are invalid, or if the point is not on the curve
+func (p *G1Affine) SetBytes(buf []byte) (int, error) {
+	if len(buf) < SizeOfG1AffineCompressed {
+		return 0, io.ErrShortBuffer
+	}
+
+	mData := buf[0] & mMask
+
+	{{- if eq .CurveName "bls381" }}
+		if mData == 0b001<<5 || mData == 0b011<<5 || mData == 0b111<<5 {
+			return 0, ErrInvalidFlags
+		}
+	{{- end }}
+
+	if mData == mCompressedInfinity {
+		if !isZeroed(buf[0]&^mMask, buf[1:SizeOfG1AffineCompressed]) {
+			return 0, ErrInvalidInfinityEncoding
+		}
+		p.X.SetZero()
+		p.Y.SetZero()
+		return SizeOfG1AffineCompressed, nil
+	}
+
+	{{- if eq .CurveName "bls381" }}
+
+	if mData == mUncompressedInfinity {
+		if len(buf) < SizeOfG1AffineUncompressed {
+			return 0, io.ErrShortBuffer
+		}
+		if !isZeroed(buf[0]&^mMask, buf[1:SizeOfG1AffineUncompressed]) {
+			return 0, ErrInvalidInfinityEncoding
+		}
+		p.X.SetZero()
+		p.Y.SetZero()
+		return SizeOfG1AffineUncompressed, nil
+	}
+	{{- end }}
+
+	if mData == mUncompressed {
+		if len(buf) < SizeOfG1AffineUncompressed {
+			return 0, io.ErrShortBuffer
+		}
+		var X, Y fp.Element
+		if err := setElementBytes(&X, buf[:SizeOfG1AffineCompressed]); err != nil {
+			return 0, err
+		}
+		if err := setElementBytes(&Y, buf[SizeOfG1AffineCompressed:SizeOfG1AffineUncompressed]); err != nil {
+			return 0, err
+		}
+		q := G1Affine{X: X, Y: Y}
+		{{- if eq .CurveName "bls381" }}
+			// the point at infinity has its own flag, (0,0) is not a valid encoding
+			if q.IsInfinity() || !q.IsOnCurve() {
+				return 0, ErrPointNotOnCurve
+			}
+		{{- else }}
+			if !q.IsOnCurve() {
+				return 0, ErrPointNotOnCurve
+			}
+		{{- end }}
+		p.Set(&q)
+		return SizeOfG1AffineUncompressed, nil
+	}
+
+	// compressed: clear the flags and read x
+	var bufX [SizeOfG1AffineCompressed]byte
+	copy(bufX[:], buf[:SizeOfG1AffineCompressed])
+	bufX[0] &^= mMask
+
+	var X, Y, YSquared fp.Element
+	if err := setElementBytes(&X, bufX[:]); err != nil {
+		return 0, err
+	}
+
+	// y**2 = x**3 + b
+	YSquared.Square(&X).Mul(&YSquared, &X).Add(&YSquared, &B)
+	if Y.Sqrt(&YSquared) == nil {
+		return 0, ErrPointNotOnCurve
+	}
+
+	if lexicographicallyLargest(&Y) != (mData == mCompressedLargest) {
+		Y.Neg(&Y)
+	}
+
+	p.X.Set(&X)
+	p.Y.Set(&Y)
+
+	return SizeOfG1AffineCompressed, nil
+}
+
+// Bytes returns the compressed binary encoding of p
+//
+{{- if eq .CoordType "E2" }}
+// x = x.A0 + x.A1*u is stored in big endian as x.A1 | x.A0, the most significant bits of the first byte
+// encode which square root y is (or that p is the point at infinity)
+{{- else }}
+// x is stored in big endian, the most significant bits of the first byte
+// encode which square root y is (or that p is the point at infinity)
+{{- end }}
+func (p *G2Affine) Bytes() (res [SizeOfG2AffineCompressed]byte) {
+	if p.IsInfinity() {
+		res[0] = mCompressedInfinity
+		return
+	}
+
+	msbMask := mCompressedSmallest
+	{{- if eq .CoordType "E2" }}
+		if lexicographicallyLargestE2(&p.Y) {
+			msbMask = mCompressedLargest
+		}
+		copy(res[:fp.Limbs*8], p.X.A1.Bytes())
+		copy(res[fp.Limbs*8:], p.X.A0.Bytes())
+	{{- else }}
+		if lexicographicallyLargest(&p.Y) {
+			msbMask = mCompressedLargest
+		}
+		copy(res[:], p.X.Bytes())
+	{{- end }}
+	res[0] |= msbMask
+
+	return
+}
+
+// RawBytes returns the uncompressed binary encoding of p
+//
+// x and y are stored in big endian {{- if eq .CoordType "E2" }} (A1 first){{- end }} {{- if ne .CurveName "bls381" }}, the point at infinity is encoded as zeroes{{- end }}
+func (p *G2Affine) RawBytes() (res [SizeOfG2AffineUncompressed]byte) {
+	if p.IsInfinity() {
+		{{- if eq .CurveName "bls381" }}
+			res[0] = mUncompressedInfinity
+		{{- end }}
+		return
+	}
+
+	{{- if eq .CoordType "E2" }}
+		copy(res[:fp.Limbs*8], p.X.A1.Bytes())
+		copy(res[fp.Limbs*8:SizeOfG2AffineCompressed], p.X.A0.Bytes())
+		copy(res[SizeOfG2AffineCompressed:SizeOfG2AffineCompressed+fp.Limbs*8], p.Y.A1.Bytes())
+		copy(res[SizeOfG2AffineCompressed+fp.Limbs*8:], p.Y.A0.Bytes())
+	{{- else }}
+		copy(res[:SizeOfG2AffineCompressed], p.X.Bytes())
+		copy(res[SizeOfG2AffineCompressed:], p.Y.Bytes())
+	{{- end }}
+	res[0] |= mUncompressed
+
+	return
+}
+
+// SetBytes sets p from its binary encoding (compressed or uncompressed, see Bytes and RawBytes)
+// and returns the number of bytes read from buf
+//
+// it returns an error if buf is too short, if a coordinate is not reduced modulo q,
+// if the flags are invalid, or if the point is not on the curve
+func (p *G2Affine) SetBytes(buf []byte) (int, error) {
+	if len(buf) < SizeOfG2AffineCompressed {
+		return 0, io.ErrShortBuffer
+	}
+
+	mData := buf[0] & mMask
+
+	{{- if eq .CurveName "bls381" }}
+		if mData == 0b001<<5 || mData == 0b011<<5 || mData == 0b111<<5 {
+			return 0, ErrInvalidFlags
+		}
+	{{- end }}
+
+	if mData == mCompressedInfinity {
+		if !isZeroed(buf[0]&^mMask, buf[1:SizeOfG2AffineCompressed]) {
+			return 0, ErrInvalidInfinityEncoding
+		}
+		p.X.SetZero()
+		p.Y.SetZero()
+		return SizeOfG2AffineCompressed, nil
+	}
+
+	{{- if eq .CurveName "bls381" }}
+
+	if mData == mUncompressedInfinity {
+		if len(buf) < SizeOfG2AffineUncompressed {
+			return 0, io.ErrShortBuffer
+		}
+		if !isZeroed(buf[0]&^mMask, buf[1:SizeOfG2AffineUncompressed]) {
+			return 0, ErrInvalidInfinityEncoding
+		}
+		p.X.SetZero()
+		p.Y.SetZero()
+		return SizeOfG2AffineUncompressed, nil
+	}
+	{{- end }}
+
+	if mData == mUncompressed {
+		if len(buf) < SizeOfG2AffineUncompressed {
+			return 0, io.ErrShortBuffer
+		}
+		var q G2Affine
+		{{- if eq .CoordType "E2" }}
+			if err := setE2Bytes(&q.X, buf[:SizeOfG2AffineCompressed]); err != nil {
+				return 0, err
+			}
+			if err := setE2Bytes(&q.Y, buf[SizeOfG2AffineCompressed:SizeOfG2AffineUncompressed]); err != nil {
+				return 0, err
+			}
+		{{- else }}
+			if err := setElementBytes(&q.X, buf[:SizeOfG2AffineCompressed]); err != nil {
+				return 0, err
+			}
+			if err := setElementBytes(&q.Y, buf[SizeOfG2AffineCompressed:SizeOfG2AffineUncompressed]); err != nil {
+				return 0, err
+			}
+		{{- end }}
+		{{- if eq .CurveName "bls381" }}
+			// the point at infinity has its own flag, (0,0) is not a valid encoding
+			if q.IsInfinity() || !q.IsOnCurve() {
+				return 0, ErrPointNotOnCurve
+			}
+		{{- else }}
+			if !q.IsOnCurve() {
+				return 0, ErrPointNotOnCurve
+			}
+		{{- end }}
+		p.Set(&q)
+		return SizeOfG2AffineUncompressed, nil
+	}
+
+	// compressed: clear the flags and read x
+	var bufX [SizeOfG2AffineCompressed]byte
+	copy(bufX[:], buf[:SizeOfG2AffineCompressed])
+	bufX[0] &^= mMask
+
+	var X, Y, YSquared {{.CoordType}}
+	{{- if eq .CoordType "E2" }}
+		if err := setE2Bytes(&X, bufX[:]); err != nil {
+			return 0, err
+		}
+	{{- else }}
+		if err := setElementBytes(&X, bufX[:]); err != nil {
+			return 0, err
+		}
+	{{- end }}
+
+	// y**2 = x**3 + b'
+	YSquared.Square(&X).Mul(&YSquared, &X).Add(&YSquared, &bTwistCurveCoeff)
+	if Y.Sqrt(&YSquared) == nil {
+		return 0, ErrPointNotOnCurve
+	}
+
+	{{- if eq .CoordType "E2" }}
+		if lexicographicallyLargestE2(&Y) != (mData == mCompressedLargest) {
+			Y.Neg(&Y)
+		}
+	{{- else }}
+		if lexicographicallyLargest(&Y) != (mData == mCompressedLargest) {
+			Y.Neg(&Y)
+		}
+	{{- end }}
+
+	p.X.Set(&X)
+	p.Y.Set(&Y)
+
+	return SizeOfG2AffineCompressed, nil
+}
+
+// Encoder writes points, field elements and GT elements to an output stream
+//
+// the first call to Encode writes a header carrying the curve ID, slices are
+// prefixed with their length (uint32, big endian)
+type Encoder struct {
+	w             io.Writer
+	n             int64 // written bytes
+	raw           bool  // raw vs compressed encoding of points
+	headerWritten bool
+}
+
+// EncoderOption sets an option of an Encoder
+type EncoderOption func(*Encoder)
+
+// RawEncoding returns an option to use in NewEncoder(...) to write points uncompressed (see RawBytes)
+// points are compressed by default (see Bytes)
+func RawEncoding() EncoderOption {
+	return func(enc *Encoder) {
+		enc.raw = true
+	}
+}
+
+// NewEncoder returns a binary encoder supporting curve {{ toLower .CurveName }} objects
+func NewEncoder(w io.Writer, options ...EncoderOption) *Encoder {
+	enc := &Encoder{w: w}
+	for _, option := range options {
+		option(enc)
+	}
+	return enc
+}
+
+// Encode writes the binary encoding of v to the stream
+// type of v must be one of *fr.Element, *fp.Element, *G1Affine, *G2Affine, *PairingResult, *GT,
+// []fr.Element, []fp.Element, []G1Affine or []G2Affine
+func (enc *Encoder) Encode(v interface{}) error {
+	if !enc.headerWritten {
+		var header [2]byte
+		binary.BigEndian.PutUint16(header[:], uint16(ID))
+		if err := enc.write(header[:]); err != nil {
+			return err
+		}
+		enc.headerWritten = true
+	}
+
+	switch t := v.(type) {
+	case *fr.Element:
+		return enc.write(t.Bytes())
+	case *fp.Element:
+		return enc.write(t.Bytes())
+	case *G1Affine:
+		if enc.raw {
+			buf := t.RawBytes()
+			return enc.write(buf[:])
+		}
+		buf := t.Bytes()
+		return enc.write(buf[:])
+	case *G2Affine:
+		if enc.raw {
+			buf := t.RawBytes()
+			return enc.write(buf[:])
+		}
+		buf := t.Bytes()
+		return enc.write(buf[:])
+	case *PairingResult:
+		buf := t.Bytes()
+		return enc.write(buf[:])
+	case *GT:
+		buf := t.Bytes()
+		return enc.write(buf[:])
+	case []fr.Element:
+		if err := enc.writeLen(len(t)); err != nil {
+			return err
+		}
+		buf := make([]byte, 0, len(t)*fr.Limbs*8)
+		for i := 0; i < len(t); i++ {
+			buf = append(buf, t[i].Bytes()...)
+		}
+		return enc.write(buf)
+	case []fp.Element:
+		if err := enc.writeLen(len(t)); err != nil {
+			return err
+		}
+		buf := make([]byte, 0, len(t)*fp.Limbs*8)
+		for i := 0; i < len(t); i++ {
+			buf = append(buf, t[i].Bytes()...)
+		}
+		return enc.write(buf)
+	case []G1Affine:
+		if err := enc.writeLen(len(t)); err != nil {
+			return err
+		}
+		var buf []byte
+		if enc.raw {
+			buf = make([]byte, len(t)*SizeOfG1AffineUncompressed)
+			parallel.Execute(0, len(t), func(start, end int) {
+				for i := start; i < end; i++ {
+					b := t[i].RawBytes()
+					copy(buf[i*SizeOfG1AffineUncompressed:], b[:])
+				}
+			}, false)
+		} else {
+			buf = make([]byte, len(t)*SizeOfG1AffineCompressed)
+			parallel.Execute(0, len(t), func(start, end int) {
+				for i := start; i < end; i++ {
+					b := t[i].Bytes()
+					copy(buf[i*SizeOfG1AffineCompressed:], b[:])
+				}
+			}, false)
+		}
+		return enc.write(buf)
+	case []G2Affine:
+		if err := enc.writeLen(len(t)); err != nil {
+			return err
+		}
+		var buf []byte
+		if enc.raw {
+			buf = make([]byte, len(t)*SizeOfG2AffineUncompressed)
+			parallel.Execute(0, len(t), func(start, end int) {
+				for i := start; i < end; i++ {
+					b := t[i].RawBytes()
+					copy(buf[i*SizeOfG2AffineUncompressed:], b[:])
+				}
+			}, false)
+		} else {
+			buf = make([]byte, len(t)*SizeOfG2AffineCompressed)
+			parallel.Execute(0, len(t), func(start, end int) {
+				for i := start; i < end; i++ {
+					b := t[i].Bytes()
+					copy(buf[i*SizeOfG2AffineCompressed:], b[:])
+				}
+			}, false)
+		}
+		return enc.write(buf)
+	default:
+		return fmt.Errorf("{{ toLower .CurveName }} encoder: unsupported type %T", v)
+	}
+}
+
+// BytesWritten returns the total number of bytes written by the encoder
+func (enc *Encoder) BytesWritten() int64 {
+	return enc.n
+}
+
+func (enc *Encoder) write(buf []byte) error {
+	n, err := enc.w.Write(buf)
+	enc.n += int64(n)
+	return err
+}
+
+func (enc *Encoder) writeLen(l int) error {
+	if uint64(l) > uint64(^uint32(0)) {
+		return errors.New("slice too long to be encoded")
+	}
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(l))
+	return enc.write(buf[:])
+}
+
+// Decoder reads points, field elements and GT elements from an input stream
+// written by an Encoder
+type Decoder struct {
+	r          io.Reader
+	n          int64 // read bytes
+	raw        bool  // raw vs compressed encoding of points
+	headerRead bool
+}
+
+// DecoderOption sets an option of a Decoder
+type DecoderOption func(*Decoder)
+
+// RawDecoding returns an option to use in NewDecoder(...) to read points written uncompressed
+// (see RawEncoding); the decoder expects compressed points by default
+func RawDecoding() DecoderOption {
+	return func(dec *Decoder) {
+		dec.raw = true
+	}
+}
+
+// NewDecoder returns a binary decoder supporting curve {{ toLower .CurveName }} objects
+func NewDecoder(r io.Reader, options ...DecoderOption) *Decoder {
+	dec := &Decoder{r: r}
+	for _, option := range options {
+		option(dec)
+	}
+	return dec
+}
+
+// decodeChunkSize is the max number of points read from the stream at once when decoding a slice
+const decodeChunkSize = 1 << 14
+
+// Decode reads the binary encoding of v from the stream
+// type of v must be one of *fr.Element, *fp.Element, *G1Affine, *G2Affine, *PairingResult, *GT,
+// *[]fr.Element, *[]fp.Element, *[]G1Affine or *[]G2Affine
+//
+// points are checked to be on the curve, and slices of points are decoded in parallel
+func (dec *Decoder) Decode(v interface{}) error {
+	if !dec.headerRead {
+		var header [2]byte
+		if err := dec.read(header[:]); err != nil {
+			return err
+		}
+		if id := gurvy.ID(binary.BigEndian.Uint16(header[:])); id != ID {
+			return fmt.Errorf("{{ toLower .CurveName }} decoder: invalid header, stream was encoded for curve ID %d", uint16(id))
+		}
+		dec.headerRead = true
+	}
+
+	switch t := v.(type) {
+	case *fr.Element:
+		var buf [fr.Limbs * 8]byte
+		if err := dec.read(buf[:]); err != nil {
+			return err
+		}
+		return setScalarBytes(t, buf[:])
+	case *fp.Element:
+		var buf [fp.Limbs * 8]byte
+		if err := dec.read(buf[:]); err != nil {
+			return err
+		}
+		return setElementBytes(t, buf[:])
+	case *G1Affine:
+		buf := make([]byte, dec.sizeOfG1Affine())
+		if err := dec.read(buf); err != nil {
+			return err
+		}
+		return dec.setG1Bytes(t, buf)
+	case *G2Affine:
+		buf := make([]byte, dec.sizeOfG2Affine())
+		if err := dec.read(buf); err != nil {
+			return err
+		}
+		return dec.setG2Bytes(t, buf)
+	case *PairingResult:
+		var buf [SizeOfGT]byte
+		if err := dec.read(buf[:]); err != nil {
+			return err
+		}
+		return t.SetBytes(buf[:])
+	case *GT:
+		var buf [SizeOfGT]byte
+		if err := dec.read(buf[:]); err != nil {
+			return err
+		}
+		return t.SetBytes(buf[:])
+	case *[]fr.Element:
+		const size = fr.Limbs * 8
+		*t = (*t)[:0]
+		return dec.readSlice(size, func(nb int) {
+			*t = append(*t, make([]fr.Element, nb)...)
+		}, func(i int, buf []byte) error {
+			return setScalarBytes(&(*t)[i], buf)
+		}, false)
+	case *[]fp.Element:
+		const size = fp.Limbs * 8
+		*t = (*t)[:0]
+		return dec.readSlice(size, func(nb int) {
+			*t = append(*t, make([]fp.Element, nb)...)
+		}, func(i int, buf []byte) error {
+			return setElementBytes(&(*t)[i], buf)
+		}, false)
+	case *[]G1Affine:
+		*t = (*t)[:0]
+		return dec.readSlice(dec.sizeOfG1Affine(), func(nb int) {
+			*t = append(*t, make([]G1Affine, nb)...)
+		}, func(i int, buf []byte) error {
+			return dec.setG1Bytes(&(*t)[i], buf)
+		}, true)
+	case *[]G2Affine:
+		*t = (*t)[:0]
+		return dec.readSlice(dec.sizeOfG2Affine(), func(nb int) {
+			*t = append(*t, make([]G2Affine, nb)...)
+		}, func(i int, buf []byte) error {
+			return dec.setG2Bytes(&(*t)[i], buf)
+		}, true)
+	default:
+		return fmt.Errorf("{{ toLower .CurveName }} decoder: unsupported type %T", v)
+	}
+}
+
+// BytesRead returns the total number of bytes read by the decoder
+func (dec *Decoder) BytesRead() int64 {
+	return dec.n
+}
+
+func (dec *Decoder) read(buf []byte) error {
+	n, err := io.ReadFull(dec.r, buf)
+	dec.n += int64(n)
+	return err
+}
+
+func (dec *Decoder) sizeOfG1Affine() int {
+	if dec.raw {
+		return SizeOfG1AffineUncompressed
+	}
+	return SizeOfG1AffineCompressed
+}
+
+func (dec *Decoder) sizeOfG2Affine() int {
+	if dec.raw {
+		return SizeOfG2AffineUncompressed
+	}
+	return SizeOfG2AffineCompressed
+}
+
+// setG1Bytes sets p from buf, checking that the encoding matches the decoder settings
+func (dec *Decoder) setG1Bytes(p *G1Affine, buf []byte) error {
+	n, err := p.SetBytes(buf)
+	if err == io.ErrShortBuffer || (err == nil && n != len(buf)) {
+		return errUnexpectedPointEncoding
+	}
+	return err
+}
+
+// setG2Bytes sets p from buf, checking that the encoding matches the decoder settings
+func (dec *Decoder) setG2Bytes(p *G2Affine, buf []byte) error {
+	n, err := p.SetBytes(buf)
+	if err == io.ErrShortBuffer || (err == nil && n != len(buf)) {
+		return errUnexpectedPointEncoding
+	}
+	return err
+}
+
+var errUnexpectedPointEncoding = errors.New("invalid encoding: point encoding doesn't match the decoder settings (see RawDecoding)")
+
+// readSlice reads a length prefixed slice of elements of size bytes each from the stream
+//
+// the slice is read by chunks: grow appends nb elements to it and setBytes decodes its i-th element;
+// chunks are decoded in parallel if concurrent is set
+func (dec *Decoder) readSlice(size int, grow func(nb int), setBytes func(i int, buf []byte) error, concurrent bool) error {
+	var bufLen [4]byte
+	if err := dec.read(bufLen[:]); err != nil {
+		return err
+	}
+	total := int(binary.BigEndian.Uint32(bufLen[:]))
+
+	chunkSize := decodeChunkSize
+	if total < chunkSize {
+		chunkSize = total
+	}
+	buf := make([]byte, size*chunkSize)
+
+	for offset := 0; offset < total; offset += chunkSize {
+		nb := chunkSize
+		if total-offset < nb {
+			nb = total - offset
+		}
+		if err := dec.read(buf[:nb*size]); err != nil {
+			return err
+		}
+		grow(nb)
+
+		if !concurrent {
+			for i := 0; i < nb; i++ {
+				if err := setBytes(offset+i, buf[i*size:(i+1)*size]); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		var errLock sync.Mutex
+		var err error
+		parallel.Execute(0, nb, func(start, end int) {
+			for i := start; i < end; i++ {
+				if _err := setBytes(offset+i, buf[i*size:(i+1)*size]); _err != nil {
+					errLock.Lock()
+					err = _err
+					errLock.Unlock()
+					return
+				}
+			}
+		}, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setScalarBytes sets z from the big endian bytes in buf
+// it returns an error if buf is not the canonical encoding of a fr.Element (i.e >= r)
+func setScalarBytes(z *fr.Element, buf []byte) error {
+	var v big.Int
+	v.SetBytes(buf)
+	if v.Cmp(fr.Modulus()) >= 0 {
+		return ErrNonCanonicalScalar
+	}
+	z.SetBigInt(&v)
+	return nil
+}
+
+// setElementBytes sets z from the big endian bytes in buf
+// it returns an error if buf is not the canonical encoding of a fp.Element (i.e >= q)
+func setElementBytes(z *fp.Element, buf []byte) error {
+	var v big.Int
+	v.SetBytes(buf)
+	if v.Cmp(fp.Modulus()) >= 0 {
+		return ErrNonCanonicalCoordinate
+	}
+	z.SetBigInt(&v)
+	return nil
+}
+
+{{- if eq .CoordType "E2" }}
+
+// setE2Bytes sets z from buf = z.A1 | z.A0 (big endian)
+func setE2Bytes(z *E2, buf []byte) error {
+	if err := setElementBytes(&z.A1, buf[:fp.Limbs*8]); err != nil {
+		return err
+	}
+	return setElementBytes(&z.A0, buf[fp.Limbs*8:fp.Limbs*8*2])
+}
+
+// lexicographicallyLargestE2 returns true if z = z.A0 + z.A1*u is larger than -z,
+// comparing z.A1 first
+func lexicographicallyLargestE2(z *E2) bool {
+	if z.A1.IsZero() {
+		return lexicographicallyLargest(&z.A0)
+	}
+	return lexicographicallyLargest(&z.A1)
+}
+{{- end }}
+
+// lexicographicallyLargest returns true if z (in regular form) is larger than -z
+func lexicographicallyLargest(z *fp.Element) bool {
+	var neg fp.Element
+	neg.Neg(z)
+	_z, _neg := z.ToRegular(), neg.ToRegular()
+	for i := fp.Limbs - 1; i >= 0; i-- {
+		if _z[i] != _neg[i] {
+			return _z[i] > _neg[i]
+		}
+	}
+	return false
+}
+
+// isZeroed returns true if firstByte and all bytes in buf are 0
+func isZeroed(firstByte byte, buf []byte) bool {
+	if firstByte != 0 {
+		return false
+	}
+	for _, b := range buf {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+`
+
+// MarshalTests ...
+const MarshalTests = `
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gurvy/{{ toLower .CurveName}}/fp"
+	"github.com/consensys/gurvy/{{ toLower .CurveName}}/fr"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestEncoder(t *testing.T) {
+
+	// encoder and decoder round trip, for both compressed and raw encodings
+	for _, raw := range []bool{false, true} {
+		var inA fr.Element
+		var inB fp.Element
+		var inC, inD G1Affine
+		var inE G2Affine
+		var inF PairingResult
+		var inG []G1Affine
+		var inH []G2Affine
+		var inI []fr.Element
+		var inJ []fp.Element
+		var inK GT
+
+		inA.SetRandom()
+		inB.SetRandom()
+		inC.FromJacobian(&g1Gen)
+		// inD is the point at infinity
+		inE.FromJacobian(&g2Gen)
+		inF.SetRandom()
+		inG = make([]G1Affine, 2*decodeChunkSize+3)
+		for i := 0; i < len(inG); i++ {
+			if i%2 == 0 {
+				inG[i].Set(&inC)
+			} else {
+				inG[i].Neg(&inC)
+			}
+		}
+		inG[1].X.SetZero()
+		inG[1].Y.SetZero()
+		inH = make([]G2Affine, 10)
+		for i := 0; i < len(inH); i++ {
+			inH[i].Set(&inE)
+		}
+		inI = make([]fr.Element, 3)
+		inI[1].SetRandom()
+		inJ = make([]fp.Element, 0)
+		inK, _ = Pair([]G1Affine{inC}, []G2Affine{inE})
+
+		var buf bytes.Buffer
+		var enc *Encoder
+		if raw {
+			enc = NewEncoder(&buf, RawEncoding())
+		} else {
+			enc = NewEncoder(&buf)
+		}
+		toEncode := []interface{}{&inA, &inB, &inC, &inD, &inE, &inF, inG, inH, inI, inJ, &inK}
+		for _, v := range toEncode {
+			if err := enc.Encode(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if enc.BytesWritten() != int64(buf.Len()) {
+			t.Fatal("invalid number of bytes written")
+		}
+
+		var dec *Decoder
+		if raw {
+			dec = NewDecoder(bytes.NewReader(buf.Bytes()), RawDecoding())
+		} else {
+			dec = NewDecoder(bytes.NewReader(buf.Bytes()))
+		}
+
+		var outA fr.Element
+		var outB fp.Element
+		var outC, outD G1Affine
+		var outE G2Affine
+		var outF PairingResult
+		var outG []G1Affine
+		var outH []G2Affine
+		var outI []fr.Element
+		var outJ []fp.Element
+		var outK GT
+
+		toDecode := []interface{}{&outA, &outB, &outC, &outD, &outE, &outF, &outG, &outH, &outI, &outJ, &outK}
+		for _, v := range toDecode {
+			if err := dec.Decode(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if dec.BytesRead() != int64(buf.Len()) {
+			t.Fatal("invalid number of bytes read")
+		}
+
+		if !inA.Equal(&outA) || !inB.Equal(&outB) || !inC.Equal(&outC) || !inD.Equal(&outD) || !inE.Equal(&outE) || !inF.Equal(&outF) || !inK.Equal(&outK) {
+			t.Fatal("decoded values don't match encoded values")
+		}
+		if len(inG) != len(outG) || len(inH) != len(outH) || len(inI) != len(outI) || len(inJ) != len(outJ) {
+			t.Fatal("decoded slices don't match encoded slices")
+		}
+		for i := 0; i < len(inG); i++ {
+			if !inG[i].Equal(&outG[i]) {
+				t.Fatal("decoded slices don't match encoded slices")
+			}
+		}
+		for i := 0; i < len(inH); i++ {
+			if !inH[i].Equal(&outH[i]) {
+				t.Fatal("decoded slices don't match encoded slices")
+			}
+		}
+		for i := 0; i < len(inI); i++ {
+			if !inI[i].Equal(&outI[i]) {
+				t.Fatal("decoded slices don't match encoded slices")
+			}
+		}
+	}
+
+	// compressed points can't be read by a raw decoder, and conversely
+	{
+		var p G1Affine
+		p.FromJacobian(&g1Gen)
+
+		var buf bytes.Buffer
+		if err := NewEncoder(&buf).Encode(&p); err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(make([]byte, SizeOfG1AffineCompressed))
+		if err := NewDecoder(&buf, RawDecoding()).Decode(&p); err != errUnexpectedPointEncoding {
+			t.Fatal("expected errUnexpectedPointEncoding, got", err)
+		}
+
+		buf.Reset()
+		if err := NewEncoder(&buf, RawEncoding()).Encode(&p); err != nil {
+			t.Fatal(err)
+		}
+		if err := NewDecoder(&buf).Decode(&p); err != errUnexpectedPointEncoding {
+			t.Fatal("expected errUnexpectedPointEncoding, got", err)
+		}
+	}
+
+	// invalid point in a slice
+	{
+		var p G1Affine
+		p.FromJacobian(&g1Gen)
+		p.Y.Double(&p.Y)
+
+		var buf bytes.Buffer
+		if err := NewEncoder(&buf, RawEncoding()).Encode([]G1Affine{p}); err != nil {
+			t.Fatal(err)
+		}
+		var points []G1Affine
+		if err := NewDecoder(&buf, RawDecoding()).Decode(&points); err != ErrPointNotOnCurve {
+			t.Fatal("expected ErrPointNotOnCurve, got", err)
+		}
+	}
+
+	// header mismatch
+	{
+		var e fr.Element
+		buf := bytes.NewBuffer([]byte{0xff, 0xff})
+		buf.Write(e.Bytes())
+		if err := NewDecoder(buf).Decode(&e); err == nil {
+			t.Fatal("decoding a stream with an invalid header should fail")
+		}
+	}
+
+	// unsupported type
+	{
+		var buf bytes.Buffer
+		if err := NewEncoder(&buf).Encode(42); err == nil {
+			t.Fatal("encoding an unsupported type should fail")
+		}
+	}
+}
+
+func TestG1AffineSerialization(t *testing.T) {
+
+	// infinity
+	{
+		var p1, p2 G1Affine
+		p2.X.SetRandom()
+		p2.Y.SetRandom()
+		buf := p1.Bytes()
+		n, err := p2.SetBytes(buf[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != SizeOfG1AffineCompressed {
+			t.Fatal("invalid number of bytes consumed")
+		}
+		if !p2.IsInfinity() {
+			t.Fatal("p2 should be infinity")
+		}
+
+		p2.X.SetRandom()
+		p2.Y.SetRandom()
+		rawBuf := p1.RawBytes()
+		n, err = p2.SetBytes(rawBuf[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != SizeOfG1AffineUncompressed {
+			t.Fatal("invalid number of bytes consumed")
+		}
+		if !p2.IsInfinity() {
+			t.Fatal("p2 should be infinity")
+		}
+
+		buf[SizeOfG1AffineCompressed-1] = 1
+		if _, err := p2.SetBytes(buf[:]); err != ErrInvalidInfinityEncoding {
+			t.Fatal("expected ErrInvalidInfinityEncoding, got", err)
+		}
+	}
+
+	// malformed inputs
+	{
+		var p G1Affine
+		p.FromJacobian(&g1Gen)
+
+		buf := p.Bytes()
+		if _, err := p.SetBytes(buf[:SizeOfG1AffineCompressed-1]); err != io.ErrShortBuffer {
+			t.Fatal("expected io.ErrShortBuffer, got", err)
+		}
+		rawBuf := p.RawBytes()
+		if _, err := p.SetBytes(rawBuf[:SizeOfG1AffineUncompressed-1]); err != io.ErrShortBuffer {
+			t.Fatal("expected io.ErrShortBuffer, got", err)
+		}
+
+		// q is not canonical
+		badBuf := rawBuf
+		q := fp.Modulus().Bytes()
+		copy(badBuf[SizeOfG1AffineCompressed-len(q):], q)
+		if _, err := p.SetBytes(badBuf[:]); err != ErrNonCanonicalCoordinate {
+			t.Fatal("expected ErrNonCanonicalCoordinate, got", err)
+		}
+
+		// (x, 2y) is not on the curve
+		var r G1Affine
+		r.Set(&p)
+		r.Y.Double(&r.Y)
+		rawBuf = r.RawBytes()
+		if _, err := p.SetBytes(rawBuf[:]); err != ErrPointNotOnCurve {
+			t.Fatal("expected ErrPointNotOnCurve, got", err)
+		}
+	}
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 10
+
+	properties := gopter.NewProperties(parameters)
+
+	var genAff G1Affine
+	genAff.FromJacobian(&g1Gen)
+
+	properties.Property("[G1] Affine SetBytes(RawBytes) should stay the same", prop.ForAll(
+		func(a fr.Element) bool {
+			var start, end G1Affine
+			var ab big.Int
+			var g G1Jac
+			a.ToBigIntRegular(&ab)
+			g.ScalarMultiplication(&genAff, &ab)
+			start.FromJacobian(&g)
+
+			buf := start.RawBytes()
+			n, err := end.SetBytes(buf[:])
+			if err != nil {
+				return false
+			}
+			if n != SizeOfG1AffineUncompressed {
+				return false
+			}
+			return start.X.Equal(&end.X) && start.Y.Equal(&end.Y)
+		},
+		GenFr(),
+	))
+
+	properties.Property("[G1] Affine SetBytes(Bytes()) should stay the same", prop.ForAll(
+		func(a fr.Element) bool {
+			var start, end G1Affine
+			var ab big.Int
+			var g G1Jac
+			a.ToBigIntRegular(&ab)
+			g.ScalarMultiplication(&genAff, &ab)
+			start.FromJacobian(&g)
+
+			buf := start.Bytes()
+			n, err := end.SetBytes(buf[:])
+			if err != nil {
+				return false
+			}
+			if n != SizeOfG1AffineCompressed {
+				return false
+			}
+			return start.X.Equal(&end.X) && start.Y.Equal(&end.Y)
+		},
+		GenFr(),
+	))
+
+	properties.Property("[G1] Affine SetBytes should reject off curve x", prop.ForAll(
+		func(x fp.Element) bool {
+			var p G1Affine
+			var ySquare fp.Element
+			ySquare.Square(&x).Mul(&ySquare, &x).Add(&ySquare, &B)
+			if ySquare.Legendre() != -1 {
+				return true
+			}
+			var buf [SizeOfG1AffineCompressed]byte
+			copy(buf[:], x.Bytes())
+			buf[0] |= mCompressedSmallest
+			_, err := p.SetBytes(buf[:])
+			return err == ErrPointNotOnCurve
+		},
+		GenFp(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestG2AffineSerialization(t *testing.T) {
+
+	// infinity
+	{
+		var p1, p2 G2Affine
+		p2.X.SetRandom()
+		p2.Y.SetRandom()
+		buf := p1.Bytes()
+		n, err := p2.SetBytes(buf[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != SizeOfG2AffineCompressed {
+			t.Fatal("invalid number of bytes consumed")
+		}
+		if !p2.IsInfinity() {
+			t.Fatal("p2 should be infinity")
+		}
+
+		p2.X.SetRandom()
+		p2.Y.SetRandom()
+		rawBuf := p1.RawBytes()
+		n, err = p2.SetBytes(rawBuf[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != SizeOfG2AffineUncompressed {
+			t.Fatal("invalid number of bytes consumed")
+		}
+		if !p2.IsInfinity() {
+			t.Fatal("p2 should be infinity")
+		}
+
+		buf[SizeOfG2AffineCompressed-1] = 1
+		if _, err := p2.SetBytes(buf[:]); err != ErrInvalidInfinityEncoding {
+			t.Fatal("expected ErrInvalidInfinityEncoding, got", err)
+		}
+	}
+
+	// malformed inputs
+	{
+		var p G2Affine
+		p.FromJacobian(&g2Gen)
+
+		buf := p.Bytes()
+		if _, err := p.SetBytes(buf[:SizeOfG2AffineCompressed-1]); err != io.ErrShortBuffer {
+			t.Fatal("expected io.ErrShortBuffer, got", err)
+		}
+		rawBuf := p.RawBytes()
+		if _, err := p.SetBytes(rawBuf[:SizeOfG2AffineUncompressed-1]); err != io.ErrShortBuffer {
+			t.Fatal("expected io.ErrShortBuffer, got", err)
+		}
+
+		// q is not canonical
+		badBuf := rawBuf
+		q := fp.Modulus().Bytes()
+		copy(badBuf[SizeOfG2AffineCompressed+fp.Limbs*8-len(q):], q)
+		if _, err := p.SetBytes(badBuf[:]); err != ErrNonCanonicalCoordinate {
+			t.Fatal("expected ErrNonCanonicalCoordinate, got", err)
+		}
+
+		// (x, 2y) is not on the curve
+		var r G2Affine
+		r.Set(&p)
+		r.Y.Double(&r.Y)
+		rawBuf = r.RawBytes()
+		if _, err := p.SetBytes(rawBuf[:]); err != ErrPointNotOnCurve {
+			t.Fatal("expected ErrPointNotOnCurve, got", err)
+		}
+	}
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 10
+
+	properties := gopter.NewProperties(parameters)
+
+	var genAff G2Affine
+	genAff.FromJacobian(&g2Gen)
+
+	properties.Property("[G2] Affine SetBytes(RawBytes) should stay the same", prop.ForAll(
+		func(a fr.Element) bool {
+			var start, end G2Affine
+			var ab big.Int
+			var g G2Jac
+			a.ToBigIntRegular(&ab)
+			g.ScalarMultiplication(&genAff, &ab)
+			start.FromJacobian(&g)
+
+			buf := start.RawBytes()
+			n, err := end.SetBytes(buf[:])
+			if err != nil {
+				return false
+			}
+			if n != SizeOfG2AffineUncompressed {
+				return false
+			}
+			return start.X.Equal(&end.X) && start.Y.Equal(&end.Y)
+		},
+		GenFr(),
+	))
+
+	properties.Property("[G2] Affine SetBytes(Bytes()) should stay the same", prop.ForAll(
+		func(a fr.Element) bool {
+			var start, end G2Affine
+			var ab big.Int
+			var g G2Jac
+			a.ToBigIntRegular(&ab)
+			g.ScalarMultiplication(&genAff, &ab)
+			start.FromJacobian(&g)
+
+			buf := start.Bytes()
+			n, err := end.SetBytes(buf[:])
+			if err != nil {
+				return false
+			}
+			if n != SizeOfG2AffineCompressed {
+				return false
+			}
+			return start.X.Equal(&end.X) && start.Y.Equal(&end.Y)
+		},
+		GenFr(),
+	))
+
+	properties.Property("[G2] Affine Bytes() should differ for p and -p", prop.ForAll(
+		func(a fr.Element) bool {
+			var p, q G2Affine
+			var ab big.Int
+			var g G2Jac
+			a.ToBigIntRegular(&ab)
+			g.ScalarMultiplication(&genAff, &ab)
+			p.FromJacobian(&g)
+			q.Neg(&p)
+			b1, b2 := p.Bytes(), q.Bytes()
+			return !bytes.Equal(b1[:], b2[:])
+		},
+		GenFr(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+`
@@ -0,0 +1,94 @@
+package point
+
+// MultiExp options and errors of G1Jac.MultiExp and G2Jac.MultiExp
+const MultiExp = `
+
+import (
+	"errors"
+	"runtime"
+
+	"github.com/consensys/gurvy/{{ toLower .CurveName}}/fr"
+)
+
+var (
+	// ErrMultiExpInputSize is returned by MultiExp when the points and the scalars have different lengths
+	ErrMultiExpInputSize = errors.New("invalid inputs: points and scalars must have the same length")
+
+	// ErrMultiExpWindowSize is returned by MultiExp when MultiExpOptions.C is 1 or larger than MaxMultiExpWindowSize
+	ErrMultiExpWindowSize = errors.New("invalid options: the window size must be between 2 and 16 bits")
+
+	// ErrMultiExpMemory is returned by MultiExp when MultiExpOptions.MaxMemory is too small for the buckets of
+	// the bucket method, whatever the number of points processed at once
+	ErrMultiExpMemory = errors.New("invalid options: the memory budget is too small")
+)
+
+// MaxMultiExpWindowSize is the largest window size (in bits) of the bucket method
+const MaxMultiExpWindowSize = 16
+
+// from batchAffineMinWindowSize bits, the buckets of the bucket method are in affine coordinates, the additions
+// being done by batches of at most maxBatchAffineSize additions sharing an inversion
+const (
+	batchAffineMinWindowSize = 10
+	maxBatchAffineSize       = 512
+)
+
+// MultiExpOptions sets the resources used by MultiExp. The zero value (or a nil *MultiExpOptions) selects the defaults
+type MultiExpOptions struct {
+	// NbTasks is the number of goroutines running the computation, runtime.NumCPU() if <= 0
+	NbTasks int
+
+	// C is the window size (in bits) of the bucket method, selected from the number of points if 0.
+	// Setting it also disables the small inputs path (Straus' method)
+	C uint
+
+	// MaxMemory bounds (in bytes) the memory allocated by the bucket method for the buckets of the tasks and the
+	// signed digits of the scalars, no limit if <= 0. Above it, the points are processed by batches, one after the
+	// other. The other allocations (a point per window, Straus' method for small inputs) are not counted
+	MaxMemory int
+}
+
+// nbTasks returns the number of goroutines set by opts
+func (opts *MultiExpOptions) nbTasks() int {
+	if opts.NbTasks <= 0 {
+		return runtime.NumCPU()
+	}
+	return opts.NbTasks
+}
+
+// windowSize returns the window size set by opts, or an empirical best window size for nbPoints points
+func (opts *MultiExpOptions) windowSize(nbPoints int) uint {
+	if opts.C != 0 {
+		return opts.C
+	}
+	if nbPoints <= 1<<10 {
+		return 8
+	} else if nbPoints <= 1<<12 {
+		return 9
+	} else if nbPoints <= 1<<14 {
+		return 11
+	} else if nbPoints <= 1<<16 {
+		return 12
+	} else if nbPoints <= 1<<18 {
+		return 13
+	} else if nbPoints <= 1<<20 {
+		return 15
+	}
+	return 16
+}
+
+// batchAffineSize returns the number of additions in a batch, for nbBuckets buckets in affine coordinates.
+// The larger the batch, the cheaper the inversion per addition, but the more additions go to the overflow
+func batchAffineSize(nbBuckets int) int {
+	if batchSize := nbBuckets / 4; batchSize < maxBatchAffineSize {
+		return batchSize
+	}
+	return maxBatchAffineSize
+}
+
+// multiExpNbChunks returns the number of c-bit signed digits of the scalars in the bucket method.
+// The scalars being reduced mod r, the top digit (at most c-2 bits plus the carry) is always positive
+func multiExpNbChunks(c uint) int {
+	return (fr.Bits + int(c) + 1) / int(c)
+}
+
+`
@@ -0,0 +1,60 @@
+// Package timing provides a statistical test of constant time implementations, in the spirit of dudect
+// (cf https://eprint.iacr.org/2016/1123.pdf): the running times of a function on two classes of inputs
+// are compared with Welch's t-test
+package timing
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Threshold above which |t| denotes a timing leak (dudect uses 4.5 on millions of measurements;
+// it is higher here to absorb the noise of a shared machine)
+const Threshold = 10
+
+// WelchT runs f(class) nbMeasures times, with the class (0 or 1) drawn at random for each run, and
+// returns Welch's t statistic of the running times of the two classes. The slowest 10% of the
+// measurements are discarded (interruptions, GC...)
+func WelchT(nbMeasures int, f func(class int)) float64 {
+
+	classes := make([]int, nbMeasures)
+	durations := make([]float64, nbMeasures)
+
+	// warm up
+	f(0)
+	f(1)
+
+	for i := 0; i < nbMeasures; i++ {
+		classes[i] = rand.Intn(2)
+		start := time.Now()
+		f(classes[i])
+		durations[i] = float64(time.Since(start))
+	}
+
+	// crop the outliers
+	sorted := make([]float64, nbMeasures)
+	copy(sorted, durations)
+	sort.Float64s(sorted)
+	cutoff := sorted[nbMeasures*9/10]
+
+	var n, mean, m2 [2]float64
+	for i := 0; i < nbMeasures; i++ {
+		if durations[i] > cutoff {
+			continue
+		}
+		// Welford's online mean and variance
+		c := classes[i]
+		n[c]++
+		delta := durations[i] - mean[c]
+		mean[c] += delta / n[c]
+		m2[c] += delta * (durations[i] - mean[c])
+	}
+	if n[0] < 2 || n[1] < 2 {
+		return 0
+	}
+
+	v0, v1 := m2[0]/(n[0]-1), m2[1]/(n[1]-1)
+	return (mean[0] - mean[1]) / math.Sqrt(v0/n[0]+v1/n[1])
+}
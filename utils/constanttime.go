@@ -0,0 +1,35 @@
+package utils
+
+// Helpers for the constant time scalar multiplications: they operate on little endian 64-bit words
+// (field elements) and their running time only depends on the length of the inputs and on public
+// parameters, never on the values of the words
+
+// CondCopy sets dst to src if cond is 1 and leaves it unchanged if cond is 0
+func CondCopy(dst, src []uint64, cond uint64) {
+	mask := -cond
+	for i := range dst {
+		dst[i] ^= mask & (dst[i] ^ src[i])
+	}
+}
+
+// IsZero returns 1 if all the words of k are 0, and 0 otherwise
+func IsZero(k []uint64) uint64 {
+	var acc uint64
+	for i := range k {
+		acc |= k[i]
+	}
+	return ((acc | -acc) >> 63) ^ 1
+}
+
+// Window returns the w bits of k starting at bit i (bits past the end of k are 0), w must be < 64
+func Window(k []uint64, i, w uint) uint64 {
+	j, off := i/64, i%64
+	var res uint64
+	if j < uint(len(k)) {
+		res = k[j] >> off
+		if off+w > 64 && j+1 < uint(len(k)) {
+			res |= k[j+1] << (64 - off)
+		}
+	}
+	return res & (1<<w - 1)
+}
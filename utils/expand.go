@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+var (
+	errExpandMsgLength = errors.New("expand_message_xmd: requested length is too large")
+	errExpandMsgDST    = errors.New("expand_message_xmd: the domain separation tag must not be empty")
+)
+
+// ExpandMsgXmd expands msg to a slice of lenInBytes pseudo random bytes,
+// using SHA-256 and the domain separation tag dst
+// cf RFC 9380, section 5.3.1
+// dst must not be empty (section 3.1), tags longer than 255 bytes are hashed first
+func ExpandMsgXmd(msg, dst []byte, lenInBytes int) ([]byte, error) {
+
+	if len(dst) == 0 {
+		return nil, errExpandMsgDST
+	}
+
+	h := sha256.New()
+
+	// oversized tags are hashed first (section 5.3.3)
+	if len(dst) > 255 {
+		h.Write([]byte("H2C-OVERSIZE-DST-"))
+		h.Write(dst)
+		dst = h.Sum(nil)
+		h.Reset()
+	}
+
+	ell := (lenInBytes + h.Size() - 1) / h.Size()
+	if ell > 255 || lenInBytes > 65535 || lenInBytes < 0 {
+		return nil, errExpandMsgLength
+	}
+
+	// DST_prime = DST || I2OSP(len(DST), 1)
+	dstPrime := make([]byte, len(dst)+1)
+	copy(dstPrime, dst)
+	dstPrime[len(dst)] = byte(len(dst))
+
+	// b_0 = H(Z_pad || msg || I2OSP(len_in_bytes, 2) || I2OSP(0, 1) || DST_prime)
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(msg)
+	h.Write([]byte{byte(lenInBytes >> 8), byte(lenInBytes), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	// b_1 = H(b_0 || I2OSP(1, 1) || DST_prime)
+	h.Reset()
+	h.Write(b0)
+	h.Write([]byte{1})
+	h.Write(dstPrime)
+	bi := h.Sum(nil)
+
+	res := make([]byte, 0, ell*h.Size())
+	res = append(res, bi...)
+
+	// b_i = H(strxor(b_0, b_(i - 1)) || I2OSP(i, 1) || DST_prime)
+	for i := 2; i <= ell; i++ {
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		res = append(res, bi...)
+	}
+
+	return res[:lenInBytes], nil
+}
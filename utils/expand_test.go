@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"testing"
+)
+
+// test vectors from RFC 9380, appendix K.1
+type expandMsgVectors struct {
+	DST   string `json:"DST"`
+	Tests []struct {
+		LenInBytes   string `json:"len_in_bytes"`
+		Msg          string `json:"msg"`
+		UniformBytes string `json:"uniform_bytes"`
+	} `json:"tests"`
+}
+
+func TestExpandMsgXmd(t *testing.T) {
+	for _, file := range []string{"expand_message_xmd_SHA256_38.json", "expand_message_xmd_SHA256_256.json"} {
+		raw, err := ioutil.ReadFile("testdata/" + file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var vectors expandMsgVectors
+		if err := json.Unmarshal(raw, &vectors); err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range vectors.Tests {
+			lenInBytes, err := strconv.ParseInt(v.LenInBytes, 0, 32)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := hex.DecodeString(v.UniformBytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := ExpandMsgXmd([]byte(v.Msg), []byte(vectors.DST), int(lenInBytes))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(res, expected) {
+				t.Fatal(file, "vector", i, "mismatch")
+			}
+		}
+	}
+}
+
+func TestExpandMsgXmdLength(t *testing.T) {
+	if _, err := ExpandMsgXmd([]byte("abc"), []byte("DST"), 255*32+1); err == nil {
+		t.Fatal("expected an error when requesting more than 255 blocks")
+	}
+}
+
+func TestExpandMsgXmdDST(t *testing.T) {
+	if _, err := ExpandMsgXmd([]byte("abc"), nil, 32); err != errExpandMsgDST {
+		t.Fatal("expected an error with an empty domain separation tag")
+	}
+	if _, err := ExpandMsgXmd([]byte("abc"), []byte{}, 32); err != errExpandMsgDST {
+		t.Fatal("expected an error with an empty domain separation tag")
+	}
+}
@@ -41,3 +41,105 @@ func NafDecomposition(a *big.Int, result []int8) int {
 	}
 	return length
 }
+
+// WnafDecomposition gets the width-w NAF decomposition of a non-negative big number, little endian
+// The non-zero digits are odd, in (-2**(w-1), 2**(w-1)), and any w consecutive digits hold at most one
+// of them. result must have room for a.BitLen()+1 digits, w must be in [2, 8]
+func WnafDecomposition(a *big.Int, w uint, result []int8) int {
+
+	mask := big.Word(1)<<w - 1
+	half := int(1) << (w - 1)
+
+	length := 0
+
+	// some buffers
+	var buf, aCopy big.Int
+	aCopy.Set(a)
+
+	for aCopy.Sign() != 0 {
+
+		if aCopy.Bit(0) == 0 {
+			result[length] = 0
+		} else {
+			// d = aCopy mod 2**w, in (-2**(w-1), 2**(w-1))
+			d := int(aCopy.Bits()[0] & mask)
+			if d >= half {
+				d -= 2 * half
+			}
+			result[length] = int8(d)
+			buf.SetInt64(int64(d))
+			aCopy.Sub(&aCopy, &buf)
+		}
+		aCopy.Rsh(&aCopy, 1)
+		length++
+	}
+	return length
+}
+
+// SplitScalar sets k such that s = k[0] + k[1]*lambda mod r, basis being a reduced basis of the lattice
+// {(a, b) | a + b*lambda = 0 mod r}, of determinant ±r
+// (Babai rounding: |k[0]| and |k[1]| are bounded by the norm of the basis vectors, ~sqrt(r))
+func SplitScalar(s *big.Int, basis *[2][2]big.Int, k *[2]big.Int) {
+
+	var det, c0, c1, tmp big.Int
+	det.Mul(&basis[0][0], &basis[1][1])
+	det.Sub(&det, tmp.Mul(&basis[0][1], &basis[1][0]))
+
+	// (s, 0) = c0*basis[0] + c1*basis[1] over Q, rounded
+	c0.Mul(&basis[1][1], s)
+	roundDiv(&c0, &det)
+	c1.Mul(&basis[0][1], s).Neg(&c1)
+	roundDiv(&c1, &det)
+
+	// k = (s, 0) - c0*basis[0] - c1*basis[1]
+	var k0, k1 big.Int
+	k0.Set(s)
+	k0.Sub(&k0, tmp.Mul(&c0, &basis[0][0]))
+	k0.Sub(&k0, tmp.Mul(&c1, &basis[1][0]))
+	k1.Mul(&c0, &basis[0][1]).Neg(&k1)
+	k1.Sub(&k1, tmp.Mul(&c1, &basis[1][1]))
+
+	k[0].Set(&k0)
+	k[1].Set(&k1)
+}
+
+// roundDiv sets n to the closest integer to n/d
+func roundDiv(n, d *big.Int) {
+	var _d big.Int
+	_d.Set(d)
+	if _d.Sign() < 0 {
+		_d.Neg(&_d)
+		n.Neg(n)
+	}
+	// floor((2n + d) / 2d), big.Int.Div being euclidean
+	n.Lsh(n, 1).Add(n, &_d)
+	_d.Lsh(&_d, 1)
+	n.Div(n, &_d)
+}
+
+// SplitScalar4 sets k such that s = k[0] + k[1]*l1 + k[2]*l2 + k[3]*l3 mod r, basis being a reduced basis of the
+// lattice {a | a[0] + a[1]*l1 + a[2]*l2 + a[3]*l3 = 0 mod r} and babai r times the first row of basis**-1
+// (Babai rounding: the |k[i]| are bounded by the sum of the norms of the basis vectors, ~r**(1/4))
+func SplitScalar4(s, r *big.Int, basis *[4][4]big.Int, babai *[4]big.Int, k *[4]big.Int) {
+
+	// (s, 0, 0, 0) = sum c[j]*basis[j] over Q, rounded
+	var c [4]big.Int
+	for j := 0; j < 4; j++ {
+		c[j].Mul(&babai[j], s)
+		roundDiv(&c[j], r)
+	}
+
+	// k = (s, 0, 0, 0) - sum c[j]*basis[j]
+	var res [4]big.Int
+	var tmp big.Int
+	res[0].Set(s)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			res[i].Sub(&res[i], tmp.Mul(&c[j], &basis[j][i]))
+		}
+	}
+
+	for i := 0; i < 4; i++ {
+		k[i].Set(&res[i])
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"math/big"
+	"math/rand"
 	"testing"
 )
 
@@ -19,3 +20,148 @@ func TestNafDecomposition(t *testing.T) {
 		}
 	}
 }
+
+func TestWnafDecomposition(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	var bound big.Int
+	bound.Lsh(big.NewInt(1), 256)
+
+	for w := uint(2); w <= 8; w++ {
+		for i := 0; i < 100; i++ {
+			var a, res, digit big.Int
+			a.Rand(rnd, &bound)
+			var result [257]int8
+			lA := WnafDecomposition(&a, w, result[:])
+			dec := result[:lA]
+
+			lastNonZero := -int(w)
+			for j := len(dec) - 1; j >= 0; j-- {
+				res.Lsh(&res, 1).Add(&res, digit.SetInt64(int64(dec[j])))
+				d := int(dec[j])
+				if d == 0 {
+					continue
+				}
+				if d%2 == 0 || d >= 1<<(w-1) || d <= -(1<<(w-1)) {
+					t.Fatalf("w=%d: digit %d out of range", w, d)
+				}
+				if lastNonZero-j < int(w) && lastNonZero >= 0 {
+					t.Fatalf("w=%d: non-zero digits too close", w)
+				}
+				lastNonZero = j
+			}
+			if res.Cmp(&a) != 0 {
+				t.Fatalf("w=%d: the decomposition of %s is wrong", w, a.String())
+			}
+		}
+	}
+}
+
+func TestSplitScalar(t *testing.T) {
+	// bls381: r = x**4-x**2+1, lambda = x**2-1
+	var r, lambda big.Int
+	r.SetString("52435875175126190479447740508185965837690552500527637822603658699938581184513", 10)
+	lambda.SetString("228988810152649578064853576960394133503", 10)
+	var basis [2][2]big.Int
+	basis[0][0].SetInt64(1)
+	basis[0][1].SetString("228988810152649578064853576960394133504", 10)
+	basis[1][0].Set(&lambda)
+	basis[1][1].SetInt64(-1)
+
+	rnd := rand.New(rand.NewSource(42))
+	for i := 0; i < 100; i++ {
+		var s, res big.Int
+		var k [2]big.Int
+		s.Rand(rnd, &r)
+		SplitScalar(&s, &basis, &k)
+		res.Mul(&k[1], &lambda).Add(&res, &k[0]).Sub(&res, &s).Mod(&res, &r)
+		if res.Sign() != 0 {
+			t.Fatal("s should be k0 + k1*lambda mod r")
+		}
+		if k[0].BitLen() > 129 || k[1].BitLen() > 129 {
+			t.Fatal("k0 and k1 should be half the size of r")
+		}
+	}
+}
+
+func TestSplitScalar4(t *testing.T) {
+	// bls381: r = x**4-x**2+1, (l1, l2, l3) = (x, x**2-1, x**3-x)
+	var x, r, tmp big.Int
+	x.SetString("-15132376222941642752", 10)
+	r.SetString("52435875175126190479447740508185965837690552500527637822603658699938581184513", 10)
+	var l [4]big.Int
+	l[0].SetInt64(1)
+	l[1].Set(&x)
+	l[2].Mul(&x, &x).Sub(&l[2], &l[0])
+	l[3].Mul(&l[2], &x)
+
+	var basis [4][4]big.Int
+	var babai [4]big.Int
+	basis[0][0].Neg(&x)
+	basis[0][1].SetInt64(1)
+	basis[1][0].SetInt64(1)
+	basis[1][1].Neg(&x)
+	basis[1][2].SetInt64(1)
+	basis[2][2].Neg(&x)
+	basis[2][3].SetInt64(1)
+	basis[3][0].SetInt64(1)
+	basis[3][3].Set(&x)
+	babai[0].Mul(&x, &x).Mul(&babai[0], &x).Neg(&babai[0])
+	babai[1].Mul(&x, &x).Neg(&babai[1])
+	babai[2].Neg(&x)
+	babai[3].SetInt64(1)
+
+	rnd := rand.New(rand.NewSource(42))
+	for i := 0; i < 100; i++ {
+		var s, res big.Int
+		var k [4]big.Int
+		s.Rand(rnd, &r)
+		SplitScalar4(&s, &r, &basis, &babai, &k)
+		for j := 0; j < 4; j++ {
+			res.Add(&res, tmp.Mul(&k[j], &l[j]))
+			if k[j].BitLen() > 66 {
+				t.Fatal("the k[i] should be a quarter of the size of r")
+			}
+		}
+		if res.Sub(&res, &s).Mod(&res, &r).Sign() != 0 {
+			t.Fatal("s should be k0 + k1*l1 + k2*l2 + k3*l3 mod r")
+		}
+	}
+}
+
+func TestConstantTimeHelpers(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 100; i++ {
+		var a, b, c [4]uint64
+		var bigA, win big.Int
+		for j := range a {
+			a[j], b[j] = rnd.Uint64(), rnd.Uint64()
+		}
+		for j := len(a) - 1; j >= 0; j-- {
+			bigA.Lsh(&bigA, 64).Or(&bigA, new(big.Int).SetUint64(a[j]))
+		}
+
+		c = a
+		CondCopy(c[:], b[:], 0)
+		if c != a {
+			t.Fatal("CondCopy should not copy if cond is 0")
+		}
+		CondCopy(c[:], b[:], 1)
+		if c != b {
+			t.Fatal("CondCopy should copy if cond is 1")
+		}
+
+		for _, w := range []uint{1, 4, 5, 16} {
+			for bit := uint(0); bit < 260; bit++ {
+				win.Rsh(&bigA, bit).And(&win, big.NewInt(1<<w-1))
+				if Window(a[:], bit, w) != win.Uint64() {
+					t.Fatalf("Window(%d, %d) is wrong", bit, w)
+				}
+			}
+		}
+	}
+
+	if IsZero([]uint64{0, 0, 0}) != 1 || IsZero([]uint64{0, 1 << 63, 0}) != 0 || IsZero([]uint64{0, 0, 1}) != 0 {
+		t.Fatal("IsZero is wrong")
+	}
+}